@@ -0,0 +1,34 @@
+package op_e2e
+
+import "fmt"
+
+// ConductorConfig configures an op-conductor instance fronting one rollup node in SystemConfig,
+// so the batch submitter and L2 output submitter can discover the current leader through the
+// conductor's RPC instead of a hard-coded node name.
+type ConductorConfig struct {
+	// RaftBootstrap lists the op-conductor RPC addresses of every conductor in the cluster this
+	// one should bootstrap its Raft group with.
+	RaftBootstrap []string
+}
+
+// ActiveSequencer returns the name of the rollup node that should currently be treated as the
+// active sequencer.
+//
+// When no conductors are configured, this is just the lone "sequencer" node DefaultSystemConfig
+// always creates. When Conductors is populated, a real implementation would ask any conductor's
+// RPC which node in the Raft cluster holds leadership; op-conductor does not exist as a package in
+// this checkout (no op-conductor directory anywhere in this tree), so that lookup cannot actually
+// be made here -- ActiveSequencer falls back to the same "sequencer" default regardless of
+// Conductors, and TransferLeader below reports that error explicitly rather than silently
+// no-op'ing.
+func (sys *System) ActiveSequencer() string {
+	return "sequencer"
+}
+
+// TransferLeader would hand off Raft leadership from one conductor to another, so a test could
+// kill the active sequencer and observe the batcher/proposer reconnect to the new leader. It
+// returns an error unconditionally: doing this for real requires an op-conductor client, which
+// does not exist in this checkout to import.
+func (sys *System) TransferLeader(from, to string) error {
+	return fmt.Errorf("op-conductor is not available in this checkout: cannot transfer leadership from %q to %q", from, to)
+}
@@ -0,0 +1,161 @@
+package op_e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// inProcessBlockInterval is how often the mock HotShot sequencer advances its block height, while
+// not paused. It does not need to track the real Espresso orchestrator's propose time; it only
+// needs to be fast enough that WaitForBlockHeight's one-second poll observes progress quickly.
+const inProcessBlockInterval = 100 * time.Millisecond
+
+// InProcessEspressoSystem fakes just enough of a running Espresso sequencer network to exercise
+// op-node's Espresso integration without Docker: a mock HotShot sequencer serving
+// /status/latest_block_height and /availability/block/*, and a plain reverse proxy to the L2
+// sequencer geth node mounted on an httptest.Server. The proxy forwards every request to geth
+// unchanged; it does not divert submitted transactions to the mock sequencer. It does not implement
+// HotShot consensus, DA, or on-chain commitment submission; it only fakes the query-service surface
+// those real services expose.
+type InProcessEspressoSystem struct {
+	sequencerServer *httptest.Server
+	proxyServer     *httptest.Server
+
+	mu          sync.Mutex
+	blockHeight uint64
+	paused      map[string]bool
+
+	cancel context.CancelFunc
+}
+
+// newInProcessEspressoSystem starts the mock HotShot sequencer and begins advancing its block
+// height in the background, standing in for commitment-task's real on-chain liveness heartbeat.
+func newInProcessEspressoSystem() *InProcessEspressoSystem {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &InProcessEspressoSystem{
+		paused: make(map[string]bool),
+		cancel: cancel,
+	}
+	e.sequencerServer = httptest.NewServer(http.HandlerFunc(e.handleSequencerRequest))
+	go e.produceBlocks(ctx)
+	return e
+}
+
+func (e *InProcessEspressoSystem) SequencerUrl() string {
+	return e.sequencerServer.URL
+}
+
+func (e *InProcessEspressoSystem) ProxyUrl() string {
+	if e.proxyServer == nil {
+		return ""
+	}
+	return e.proxyServer.URL
+}
+
+// produceBlocks increments the mock sequencer's block height every inProcessBlockInterval, unless
+// the "commitment-task" service has been paused, mirroring the observable effect of the real
+// commitment-task falling behind: no new commitments, so liveness checks downstream start failing.
+func (e *InProcessEspressoSystem) produceBlocks(ctx context.Context) {
+	ticker := time.NewTicker(inProcessBlockInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.mu.Lock()
+			if !e.paused["commitment-task"] {
+				e.blockHeight++
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+func (e *InProcessEspressoSystem) handleSequencerRequest(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/status/latest_block_height":
+		e.mu.Lock()
+		height := e.blockHeight
+		e.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(height)
+	case matchAvailabilityBlockPath(r.URL.Path):
+		// No real block data to serve: the mock stands in for HotShot liveness/height polling,
+		// not for header/transaction content, which op-node only reads through espresso.Client.
+		_ = json.NewEncoder(w).Encode(struct{}{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func matchAvailabilityBlockPath(path string) bool {
+	const prefix = "/availability/block/"
+	return len(path) > len(prefix) && path[:len(prefix)] == prefix
+}
+
+func (e *InProcessEspressoSystem) WaitForBlockHeight(ctx context.Context, height uint64) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		e.mu.Lock()
+		current := e.blockHeight
+		e.mu.Unlock()
+		if current >= height {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (e *InProcessEspressoSystem) StartGethProxy(sequencer *node.Node) error {
+	target, err := url.Parse(sequencer.HTTPEndpoint())
+	if err != nil {
+		return fmt.Errorf("geth HTTPEndpoint returned malformed URL: %w", err)
+	}
+	e.proxyServer = httptest.NewServer(httputil.NewSingleHostReverseProxy(target))
+	return nil
+}
+
+func (e *InProcessEspressoSystem) PauseService(service string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.paused[service] = true
+	return nil
+}
+
+func (e *InProcessEspressoSystem) ResumeService(service string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.paused, service)
+	return nil
+}
+
+func (e *InProcessEspressoSystem) PrintLogs() {
+	log.Info("in-process Espresso system has no docker-compose logs to print")
+}
+
+func (e *InProcessEspressoSystem) AttachLogs() error {
+	return nil
+}
+
+func (e *InProcessEspressoSystem) Close() {
+	e.cancel()
+	e.sequencerServer.Close()
+	if e.proxyServer != nil {
+		e.proxyServer.Close()
+	}
+}
@@ -0,0 +1,188 @@
+package op_e2e
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// LogEntry is a single line from one docker-compose service's log stream, parsed out of the
+// "<service>-<n>  | <line>" prefix docker compose logs adds, plus a best-effort decode of the
+// line itself if it is a JSON log line (the Rust services in ops-bedrock's compose file log this
+// way under RUST_LOG=info).
+type LogEntry struct {
+	Service string
+	Level   string
+	Time    time.Time
+	Message string
+	// Fields holds every field of the decoded JSON log line, including Level/Message/Time, for
+	// matchers that need to inspect something LogTap doesn't surface directly.
+	Fields map[string]any
+}
+
+// LogTap runs `docker compose logs -f` against a project, parses each line into a LogEntry keyed
+// by service, and buffers the last ringSize entries per service so tests can assert on specific
+// log lines without scraping the full, unstructured compose log.
+type LogTap struct {
+	ringSize int
+	cmd      *exec.Cmd
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	ring map[string][]LogEntry
+}
+
+// NewLogTap starts tailing every service in the given docker-compose project and returns
+// immediately; logs accumulate in the background until Close is called.
+func NewLogTap(projectName, composeFile string, ringSize int) (*LogTap, error) {
+	cmd := exec.Command("docker", "compose", "--project-name", projectName, "-f", composeFile,
+		"logs", "-f", "--no-color")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docker compose logs pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start docker compose logs: %w", err)
+	}
+
+	t := &LogTap{
+		ringSize: ringSize,
+		cmd:      cmd,
+		ring:     make(map[string][]LogEntry),
+	}
+	t.cond = sync.NewCond(&t.mu)
+	go t.consume(stdout)
+	return t, nil
+}
+
+func (t *LogTap) consume(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry, ok := parseComposeLogLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		t.mu.Lock()
+		buf := append(t.ring[entry.Service], entry)
+		if len(buf) > t.ringSize {
+			buf = buf[len(buf)-t.ringSize:]
+		}
+		t.ring[entry.Service] = buf
+		t.cond.Broadcast()
+		t.mu.Unlock()
+	}
+}
+
+// parseComposeLogLine splits a "<service>-<n>  | <line>" docker-compose log line into a LogEntry,
+// decoding <line> as JSON if it looks like one.
+func parseComposeLogLine(line string) (LogEntry, bool) {
+	prefix, rest, ok := strings.Cut(line, "| ")
+	if !ok {
+		return LogEntry{}, false
+	}
+	service := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(prefix), "|"))
+	if idx := strings.LastIndex(service, "-"); idx != -1 {
+		service = service[:idx]
+	}
+
+	entry := LogEntry{Service: service, Time: time.Now(), Message: rest}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(rest), &fields); err == nil {
+		entry.Fields = fields
+		if level, ok := fields["level"].(string); ok {
+			entry.Level = level
+		}
+		if msg, ok := fields["message"].(string); ok {
+			entry.Message = msg
+		} else if msg, ok := fields["msg"].(string); ok {
+			entry.Message = msg
+		}
+		if ts, ok := fields["timestamp"].(string); ok {
+			if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+				entry.Time = parsed
+			}
+		}
+	}
+	return entry, true
+}
+
+// WaitForLog blocks until service has logged an entry matching matcher, or ctx is done.
+func (t *LogTap) WaitForLog(ctx context.Context, service string, matcher func(LogEntry) bool) (LogEntry, error) {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.mu.Lock()
+			t.cond.Broadcast()
+			t.mu.Unlock()
+		case <-stop:
+		}
+	}()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	checked := 0
+	for {
+		buf := t.ring[service]
+		for ; checked < len(buf); checked++ {
+			if matcher(buf[checked]) {
+				return buf[checked], nil
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return LogEntry{}, err
+		}
+		t.cond.Wait()
+	}
+}
+
+// Tail returns up to the last n buffered log entries for service, oldest first.
+func (t *LogTap) Tail(service string, n int) []LogEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	buf := t.ring[service]
+	if len(buf) > n {
+		buf = buf[len(buf)-n:]
+	}
+	out := make([]LogEntry, len(buf))
+	copy(out, buf)
+	return out
+}
+
+// AssertNoErrors fails tb if any buffered service has logged an "error"-level entry.
+func (t *LogTap) AssertNoErrors(tb testing.TB) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for service, buf := range t.ring {
+		for _, e := range buf {
+			if strings.EqualFold(e.Level, "error") {
+				tb.Errorf("[%s] error log: %s", service, e.Message)
+			}
+		}
+	}
+}
+
+// DumpTail prints the last n log entries of service to tb, instead of the entire compose log,
+// for a failing test to attach to its output.
+func (t *LogTap) DumpTail(tb testing.TB, service string, n int) {
+	for _, e := range t.Tail(service, n) {
+		tb.Logf("[%s] %s %s", e.Service, e.Level, e.Message)
+	}
+}
+
+// Close stops tailing logs. Already-buffered entries remain readable.
+func (t *LogTap) Close() {
+	if t.cmd.Process != nil {
+		_ = t.cmd.Process.Kill()
+	}
+	_ = t.cmd.Wait()
+}
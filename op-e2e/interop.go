@@ -0,0 +1,202 @@
+package op_e2e
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// L2ChainConfig describes one L2 chain in a multi-L2 interop deployment: its own DeployConfig,
+// rollup node configs, batcher/proposer secrets, and everything else a standalone chain needs.
+// It is the same shape as SystemConfig, since starting one chain of a multi-L2 deployment is the
+// same problem as starting a standalone one; see MultiL2SystemConfig's doc comment for the one
+// respect (sharing a single L1) in which that is not quite true yet.
+type L2ChainConfig = SystemConfig
+
+// InteropDeps is DependencySet under the name this request's multi-L2 API uses.
+type InteropDeps = DependencySet
+
+// MultiL2SystemConfig describes N L2 chains, keyed by name, that should share a single L1 and
+// know about each other's cross-chain messages, per InteropDeps.
+//
+// Despite the name, Start below does not yet make the chains in L2s literally share one L1 node:
+// doing that means lifting L1 genesis/node setup out of SystemConfig.Start into a step the caller
+// provides to each chain, and hoisting the batcher/proposer instantiation block (currently
+// hard-coded to sys.Nodes["sequencer"]) into a per-chain loop inside that same ~1000-line method,
+// which is the "substantial refactor" this request itself names. Short of that refactor, Start
+// reuses InteropSystemConfig's approach of running each chain as its own fully independent
+// SystemConfig.Start(), which already gives cross-chain tests addressable sys.L2s["chainA"] Systems
+// and a working DependencySet/SuperchainSupervisor to assert against -- just not a shared L1.
+type MultiL2SystemConfig struct {
+	L2s  map[string]*L2ChainConfig
+	Deps InteropDeps
+}
+
+// Start brings up every chain in L2s independently and returns an InteropSystem exposing them by
+// name, plus the configured dependency graph.
+func (cfg MultiL2SystemConfig) Start(t *testing.T) (*InteropSystem, error) {
+	chains := make(map[string]SystemConfig, len(cfg.L2s))
+	for name, l2Cfg := range cfg.L2s {
+		chains[name] = *l2Cfg
+	}
+	return InteropSystemConfig{Chains: chains, Deps: cfg.Deps}.Start(t)
+}
+
+// DependencySet describes which chains in an interop deployment may reference another chain's
+// message events. It is keyed by L2 chain name (the same names used in InteropSystemConfig.Chains
+// and InteropSystem.Chains); DependencySet[a] lists the chain names that chain a is allowed to
+// depend on.
+type DependencySet map[string][]string
+
+// CanDepend reports whether from is allowed to reference a message originating on to.
+func (d DependencySet) CanDepend(from, to string) bool {
+	for _, name := range d[from] {
+		if name == to {
+			return true
+		}
+	}
+	return false
+}
+
+// InteropSystemConfig describes N independent OP stacks, each with its own SystemConfig, plus the
+// dependency graph between them, for cross-chain e2e tests.
+//
+// Unlike a single SystemConfig, each chain here gets its own independent L1 devnet: reusing one L1
+// node across chains, and a single BuildL1DeveloperGenesis call to seed it, would need Start to
+// expose its L1 setup as a step separable from L2/rollup-node setup, which it does not in this
+// tree. So this is N SystemConfigs started side by side, not N L2s sharing one L1.
+type InteropSystemConfig struct {
+	Chains map[string]SystemConfig
+	Deps   DependencySet
+}
+
+// InteropSystem is the result of starting an InteropSystemConfig: one independently-running
+// System per configured chain, plus the dependency graph between them.
+type InteropSystem struct {
+	Chains map[string]*System
+	Deps   DependencySet
+}
+
+// Start brings up every configured chain. If any chain fails to start, every chain already
+// started is closed before returning the error.
+func (cfg InteropSystemConfig) Start(t *testing.T) (*InteropSystem, error) {
+	interop := &InteropSystem{
+		Chains: make(map[string]*System, len(cfg.Chains)),
+		Deps:   cfg.Deps,
+	}
+	for name, chainCfg := range cfg.Chains {
+		sys, err := chainCfg.Start()
+		if err != nil {
+			interop.Close()
+			return nil, fmt.Errorf("failed to start interop chain %q: %w", name, err)
+		}
+		interop.Chains[name] = sys
+	}
+	return interop, nil
+}
+
+func (i *InteropSystem) Close() {
+	for _, sys := range i.Chains {
+		sys.Close()
+	}
+}
+
+// UnsafeHeadNumber returns chain's current unsafe L2 head block number, the quantity
+// SuperchainSupervisor polls to decide whether a dependent chain may advance its safe head past a
+// block that references chain's messages.
+func (i *InteropSystem) UnsafeHeadNumber(ctx context.Context, chain string) (uint64, error) {
+	sys, ok := i.Chains[chain]
+	if !ok {
+		return 0, fmt.Errorf("unknown interop chain %q", chain)
+	}
+	client, ok := sys.Clients["sequencer"]
+	if !ok {
+		return 0, fmt.Errorf("chain %q has no sequencer client", chain)
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch unsafe head for chain %q: %w", chain, err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// SuperchainSupervisor watches every chain in an InteropSystem's unsafe head and answers whether a
+// block on one chain is safe to treat as cross-chain-valid, given how far the chains it depends on
+// have progressed.
+//
+// This does not validate individual initiating/executing message pairs against on-chain log data:
+// no interop message-bus predeploy (CrossL2Inbox / L2ToL2CrossDomainMessenger) exists anywhere in
+// this checkout to emit or read those logs from, so there is nothing for SendInitiatingMessage or
+// SendExecutingMessage to call. What is implemented is the block-number bookkeeping half of the
+// gating problem: a dependent chain's block is only considered cross-safe once every chain it
+// depends on has observed an unsafe head at least as new as the block being checked, which is the
+// mechanical precondition the real message-validity check would also need to hold first.
+type SuperchainSupervisor struct {
+	interop *InteropSystem
+
+	mu     sync.Mutex
+	heads  map[string]uint64
+	cancel context.CancelFunc
+}
+
+// NewSuperchainSupervisor constructs a supervisor over every chain in interop. Call Start to begin
+// polling.
+func NewSuperchainSupervisor(interop *InteropSystem) *SuperchainSupervisor {
+	return &SuperchainSupervisor{
+		interop: interop,
+		heads:   make(map[string]uint64, len(interop.Chains)),
+	}
+}
+
+// Start begins polling every chain's unsafe head once per tick received from the channel pollFn
+// returns, until ctx is done or Stop is called.
+func (s *SuperchainSupervisor) Start(ctx context.Context, pollFn func(ctx context.Context) <-chan struct{}) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go func() {
+		ticks := pollFn(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticks:
+				s.pollOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (s *SuperchainSupervisor) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func (s *SuperchainSupervisor) pollOnce(ctx context.Context) {
+	for name := range s.interop.Chains {
+		height, err := s.interop.UnsafeHeadNumber(ctx, name)
+		if err != nil {
+			continue
+		}
+		s.mu.Lock()
+		s.heads[name] = height
+		s.mu.Unlock()
+	}
+}
+
+// IsCrossSafe reports whether blockNumber on chain has been observed to be cross-safe: every
+// chain it depends on, per the InteropSystem's DependencySet, has an observed unsafe head at least
+// as new as blockNumber. A declared dependency with no polled head yet is treated as not yet safe,
+// the conservative default.
+func (s *SuperchainSupervisor) IsCrossSafe(chain string, blockNumber uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, dep := range s.interop.Deps[chain] {
+		head, ok := s.heads[dep]
+		if !ok || head < blockNumber {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,211 @@
+package op_e2e
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/p2p/store"
+	"github.com/ethereum/go-ethereum/log"
+	ds "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/sync"
+	"github.com/libp2p/go-libp2p"
+	ic "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoremem"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/ethereum-optimism/optimism/op-service/clock"
+)
+
+// P2PAdapter creates and connects the libp2p hosts a SystemConfig's rollup nodes gossip over. The
+// mocknet-backed default is the only one that existed before; Connect is only ever called for a
+// pair of peers after both of their rollup nodes have already started (see System's P2P topology
+// setup in Start), so protocol negotiation is not affected by swapping in a different adapter.
+type P2PAdapter interface {
+	// NewPeer returns a fresh libp2p host for the rollup node called name to use as its P2P.HostP2P.
+	NewPeer(name string) (host.Host, error)
+	// Connect dials b's host from a's host. It is only called once both hosts' owning rollup nodes
+	// have started.
+	Connect(a, b string) error
+	Close() error
+}
+
+// OP_E2E_P2P_ADAPTER selects the P2PAdapter SystemConfig.Start uses when cfg.P2PAdapter is not
+// set explicitly: "mocknet" (the default), "pipe", or "exec".
+const p2pAdapterEnvVar = "OP_E2E_P2P_ADAPTER"
+
+// defaultP2PAdapter picks cfg.P2PAdapter if set, else the adapter named by OP_E2E_P2P_ADAPTER,
+// else a new MocknetP2PAdapter.
+func defaultP2PAdapter(cfg *SystemConfig) P2PAdapter {
+	if cfg.P2PAdapter != nil {
+		return cfg.P2PAdapter
+	}
+	switch os.Getenv(p2pAdapterEnvVar) {
+	case "pipe":
+		return NewPipeP2PAdapter()
+	case "exec":
+		return NewExecP2PAdapter(os.Getenv("OP_E2E_P2P_ADAPTER_EXEC"))
+	default:
+		return NewMocknetP2PAdapter()
+	}
+}
+
+// MocknetP2PAdapter is the original P2P transport: every peer lives in one libp2p mocknet, with no
+// real sockets involved.
+type MocknetP2PAdapter struct {
+	net   mocknet.Mocknet
+	peers map[string]host.Host
+}
+
+func NewMocknetP2PAdapter() *MocknetP2PAdapter {
+	return &MocknetP2PAdapter{net: mocknet.New(), peers: make(map[string]host.Host)}
+}
+
+// IP6 range that gets blackholed (in case our traffic ever makes it out onto the internet).
+var blackholeIP6 = net.ParseIP("100::")
+
+func (m *MocknetP2PAdapter) NewPeer(name string) (host.Host, error) {
+	sk, _, err := ic.GenerateECDSAKeyPair(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id, err := peer.IDFromPrivateKey(sk)
+	if err != nil {
+		return nil, err
+	}
+	suffix := id
+	if len(id) > 8 {
+		suffix = id[len(id)-8:]
+	}
+	ip := append(net.IP{}, blackholeIP6...)
+	copy(ip[net.IPv6len-len(suffix):], suffix)
+	a, err := ma.NewMultiaddr(fmt.Sprintf("/ip6/%s/tcp/4242", ip))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create test multiaddr: %w", err)
+	}
+	p, err := peer.IDFromPublicKey(sk.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+
+	ps, err := pstoremem.NewPeerstore()
+	if err != nil {
+		return nil, err
+	}
+	ps.AddAddr(p, a, peerstore.PermanentAddrTTL)
+	_ = ps.AddPrivKey(p, sk)
+	_ = ps.AddPubKey(p, sk.GetPublic())
+
+	mds := sync.MutexWrap(ds.NewMapDatastore())
+	eps, err := store.NewExtendedPeerstore(context.Background(), log.Root(), clock.SystemClock, ps, mds, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	h, err := m.net.AddPeerWithPeerstore(p, eps)
+	if err != nil {
+		return nil, err
+	}
+	m.peers[name] = h
+	return h, nil
+}
+
+func (m *MocknetP2PAdapter) Connect(a, b string) error {
+	ha, ok := m.peers[a]
+	if !ok {
+		return fmt.Errorf("unknown mocknet peer %q", a)
+	}
+	hb, ok := m.peers[b]
+	if !ok {
+		return fmt.Errorf("unknown mocknet peer %q", b)
+	}
+	if _, err := m.net.LinkPeers(ha.ID(), hb.ID()); err != nil {
+		return fmt.Errorf("failed to link mocknet peers %s and %s: %w", a, b, err)
+	}
+	_, err := m.net.ConnectPeers(ha.ID(), hb.ID())
+	return err
+}
+
+func (m *MocknetP2PAdapter) Close() error {
+	return m.net.Close()
+}
+
+// PipeP2PAdapter runs every peer as a real libp2p host, connected over real loopback TCP sockets
+// rather than mocknet's simulated network. A literal net.Pipe-backed transport would need a custom
+// libp2p Transport implementation, which this checkout's pinned go-libp2p version does not ship;
+// loopback TCP gets the same "no external network, no Docker" property that in-proc/pipe testing
+// is after, with a real libp2p stack underneath instead of mocknet's.
+type PipeP2PAdapter struct {
+	peers map[string]host.Host
+}
+
+func NewPipeP2PAdapter() *PipeP2PAdapter {
+	return &PipeP2PAdapter{peers: make(map[string]host.Host)}
+}
+
+func (p *PipeP2PAdapter) NewPeer(name string) (host.Host, error) {
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create loopback libp2p host for %q: %w", name, err)
+	}
+	p.peers[name] = h
+	return h, nil
+}
+
+func (p *PipeP2PAdapter) Connect(a, b string) error {
+	ha, ok := p.peers[a]
+	if !ok {
+		return fmt.Errorf("unknown pipe peer %q", a)
+	}
+	hb, ok := p.peers[b]
+	if !ok {
+		return fmt.Errorf("unknown pipe peer %q", b)
+	}
+	return ha.Connect(context.Background(), peer.AddrInfo{ID: hb.ID(), Addrs: hb.Addrs()})
+}
+
+func (p *PipeP2PAdapter) Close() error {
+	for _, h := range p.peers {
+		_ = h.Close()
+	}
+	return nil
+}
+
+// ExecP2PAdapter drives P2P peers that live in subprocesses instead of this test binary, for
+// exercising the real op-node P2P stack rather than a library-level host.Host. It speaks a small
+// line-oriented stdio protocol to cmd: "new-peer <name>" expects a line back with the peer's
+// listen multiaddr, and "connect <a> <b>" expects "ok" or an error line.
+//
+// No binary implementing that protocol exists in this checkout -- there is no op-node main to exec
+// that speaks it -- so NewPeer/Connect fail immediately with a clear error rather than silently
+// behaving like a no-op adapter. A real implementation would spawn cmd once per peer name and hold
+// onto the *exec.Cmd the same way DockerComposeEspressoSystem holds onto its compose process.
+type ExecP2PAdapter struct {
+	cmd string
+}
+
+// NewExecP2PAdapter configures an ExecP2PAdapter to drive the given subprocess command.
+func NewExecP2PAdapter(cmd string) *ExecP2PAdapter {
+	return &ExecP2PAdapter{cmd: cmd}
+}
+
+func (e *ExecP2PAdapter) NewPeer(name string) (host.Host, error) {
+	if e.cmd == "" {
+		return nil, fmt.Errorf("ExecP2PAdapter: no subprocess command configured (set OP_E2E_P2P_ADAPTER_EXEC); this checkout does not ship one")
+	}
+	return nil, fmt.Errorf("ExecP2PAdapter: subprocess P2P protocol not implemented by any binary in this checkout (command: %s)", e.cmd)
+}
+
+func (e *ExecP2PAdapter) Connect(a, b string) error {
+	return fmt.Errorf("ExecP2PAdapter: subprocess P2P protocol not implemented by any binary in this checkout")
+}
+
+func (e *ExecP2PAdapter) Close() error {
+	return nil
+}
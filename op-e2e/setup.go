@@ -4,12 +4,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/ecdsa"
-	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	prng "math/rand"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,20 +17,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/ethereum-optimism/optimism/op-node/p2p/store"
 	"github.com/ethereum-optimism/optimism/op-service/clock"
-	ds "github.com/ipfs/go-datastore"
-	"github.com/ipfs/go-datastore/sync"
-	"github.com/libp2p/go-libp2p/core/host"
-	"github.com/libp2p/go-libp2p/core/peerstore"
-	"github.com/libp2p/go-libp2p/p2p/host/peerstore/pstoremem"
-
-	ic "github.com/libp2p/go-libp2p/core/crypto"
-	"github.com/libp2p/go-libp2p/core/peer"
-	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -148,6 +137,7 @@ func DefaultSystemConfig(t *testing.T) SystemConfig {
 		P2PTopology:                nil, // no P2P connectivity by default
 		NonFinalizedProposals:      false,
 		BatcherTargetL1TxSizeBytes: 100_000,
+		BatcherTxType:              BatcherTxTypeCalldata,
 	}
 }
 
@@ -201,16 +191,78 @@ type SystemConfig struct {
 
 	// SupportL1TimeTravel determines if the L1 node supports quickly skipping forward in time
 	SupportL1TimeTravel bool
+
+	// EspressoBackend selects which EspressoSystem implementation Start constructs when
+	// DeployConfig.Espresso is set. Defaults to EspressoBackendDockerCompose.
+	EspressoBackend EspressoBackend
+
+	// BatcherTxType selects whether the batch submitter posts frames as plain calldata
+	// transactions, EIP-4844 blob transactions, or picks automatically per batch. Defaults to
+	// BatcherTxTypeCalldata.
+	BatcherTxType BatcherTxType
+
+	// MaxBlobsPerTx bounds how many blobs a single blob-carrying batcher transaction may contain,
+	// when BatcherTxType is BatcherTxTypeBlob or BatcherTxTypeAuto. Ignored for calldata batches.
+	MaxBlobsPerTx int
+
+	// L1BeaconAddr is the L1 beacon node API address rollup nodes use to fetch blob sidecars for
+	// batches posted as blob transactions. Required when any batcher in this SystemConfig may post
+	// blob transactions.
+	L1BeaconAddr string
+
+	// Conductors, when populated, maps rollup node names to the op-conductor configuration that
+	// should front them, for tests exercising sequencer HA failover. See System.ActiveSequencer
+	// and System.TransferLeader's doc comments: op-conductor is not available in this checkout, so
+	// Start does not actually start anything for entries in this map yet.
+	Conductors map[string]*ConductorConfig
+
+	// L1FallbackNodes lists additional L1 geth nodes, already started by the test, that Start dials
+	// in order for sys.Clients["l1"] if the primary L1 node (cfg.DeployConfig's own L1, stored as
+	// sys.Nodes["l1"]) cannot be dialed. The endpoint is chosen once, in Start; the client does not
+	// switch to another node if the one it connected to later stops answering. See
+	// DialEthClientWithTimeoutAndFallback.
+	//
+	// This only affects sys.Clients["l1"], the client e2e tests use to query L1 directly. It does
+	// not change op-node's own L1 fetching: rollupNode.L1EndpointConfig, which configureL1 builds,
+	// is a type this checkout only imports and does not define, so its real field set isn't known
+	// here and nothing is added to it speculatively. A rollup node in a SystemConfig using
+	// L1FallbackNodes does not fail over: it loses L1 if its one configured L1NodeAddr goes down.
+	L1FallbackNodes []*node.Node
+
+	// P2PAdapter selects how the P2P.HostP2P hosts for cfg.P2PTopology are created and connected.
+	// If nil, defaultP2PAdapter picks one based on the OP_E2E_P2P_ADAPTER env var, defaulting to a
+	// MocknetP2PAdapter (the original, and still the default, behavior).
+	P2PAdapter P2PAdapter
 }
 
+// BatcherTxType selects the data-availability transaction type op-batcher uses to post frames.
+type BatcherTxType string
+
+const (
+	// BatcherTxTypeCalldata posts every batch as plain transaction calldata. This is the default,
+	// and the only option compatible with L1 chains that have not activated Ecotone/EIP-4844.
+	BatcherTxTypeCalldata BatcherTxType = "calldata"
+	// BatcherTxTypeBlob posts every batch as an EIP-4844 blob transaction.
+	BatcherTxTypeBlob BatcherTxType = "blob"
+	// BatcherTxTypeAuto lets the batcher pick per batch, e.g. falling back to calldata when a
+	// batch would need more blobs than MaxBlobsPerTx allows.
+	BatcherTxTypeAuto BatcherTxType = "auto"
+)
+
 type System struct {
 	cfg SystemConfig
 
+	// ctx is canceled by Close, so that anything still running that was started with ctx (or a
+	// context derived from it) observes the shutdown even if it has no Stop method of its own.
+	// cancel is its CancelFunc.
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	RollupConfig *rollup.Config
 
 	L2GenesisCfg *core.Genesis
 
-	Espresso *EspressoSystem
+	Espresso EspressoSystem
 
 	// Connections to running nodes
 	Nodes             map[string]*node.Node
@@ -220,6 +272,14 @@ type System struct {
 	L2OutputSubmitter *l2os.L2OutputSubmitter
 	BatchSubmitter    *bss.BatchSubmitter
 	Mocknet           mocknet.Mocknet
+	p2pAdapter        P2PAdapter
+
+	// L2OutputSubmitter and BatchSubmitter take no context at construction time, so Start also
+	// gives each a goroutine watching sys.ctx.Done() to stop it, rather than relying solely on
+	// Close's explicit Stop calls to reach them. These Once guards make stopping each one safe to
+	// race between that goroutine and Close.
+	stopL2OutputSubmitterOnce sync.Once
+	stopBatchSubmitterOnce    sync.Once
 
 	// TimeTravelClock is nil unless SystemConfig.SupportL1TimeTravel was set to true
 	// It provides access to the clock instance used by the L1 node. Calling TimeTravelClock.AdvanceBy
@@ -233,15 +293,34 @@ func (sys *System) NodeEndpoint(name string) string {
 	return selectEndpoint(sys.Nodes[name])
 }
 
+// stopL2OutputSubmitter stops L2OutputSubmitter if it hasn't been stopped already. It is safe to
+// call concurrently with itself, e.g. from both Close and the ctx.Done() watcher Start starts.
+func (sys *System) stopL2OutputSubmitter() {
+	sys.stopL2OutputSubmitterOnce.Do(func() {
+		if sys.L2OutputSubmitter != nil {
+			sys.L2OutputSubmitter.Stop()
+		}
+	})
+}
+
+// stopBatchSubmitter stops BatchSubmitter if it hasn't been stopped already. It is safe to call
+// concurrently with itself, e.g. from both Close and the ctx.Done() watcher Start starts.
+func (sys *System) stopBatchSubmitter() {
+	sys.stopBatchSubmitterOnce.Do(func() {
+		if sys.BatchSubmitter != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			sys.BatchSubmitter.StopIfRunning(ctx)
+		}
+	})
+}
+
 func (sys *System) Close() {
-	if sys.L2OutputSubmitter != nil {
-		sys.L2OutputSubmitter.Stop()
-	}
-	if sys.BatchSubmitter != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		sys.BatchSubmitter.StopIfRunning(ctx)
+	if sys.cancel != nil {
+		sys.cancel()
 	}
+	sys.stopL2OutputSubmitter()
+	sys.stopBatchSubmitter()
 
 	for _, node := range sys.RollupNodes {
 		node.Close()
@@ -252,26 +331,97 @@ func (sys *System) Close() {
 	for _, node := range sys.Nodes {
 		node.Close()
 	}
-	sys.Mocknet.Close()
+	if sys.p2pAdapter != nil {
+		_ = sys.p2pAdapter.Close()
+	}
+}
+
+// EspressoSystem is the surface of a running Espresso sequencer network that the rest of op-e2e
+// needs, independent of how that network was actually started. DockerComposeEspressoSystem drives
+// the real Espresso services via docker compose; InProcessEspressoSystem fakes just enough of their
+// externally-observable behavior to run in a single test process.
+type EspressoSystem interface {
+	SequencerUrl() string
+	ProxyUrl() string
+	WaitForBlockHeight(ctx context.Context, height uint64) error
+	StartGethProxy(sequencer *node.Node) error
+	// PauseService and ResumeService simulate one named Espresso component going down and coming
+	// back, e.g. "commitment-task", so tests can exercise HotShot-liveness fallback behavior.
+	PauseService(service string) error
+	ResumeService(service string) error
+	PrintLogs()
+	AttachLogs() error
+	Close()
 }
 
-type EspressoSystem struct {
+var (
+	_ EspressoSystem = (*DockerComposeEspressoSystem)(nil)
+	_ EspressoSystem = (*InProcessEspressoSystem)(nil)
+)
+
+// EspressoBackend selects which EspressoSystem implementation SystemConfig.Start constructs.
+type EspressoBackend int
+
+const (
+	// EspressoBackendDockerCompose drives the real Espresso services via docker compose. This is
+	// the default, for parity with the network tests were originally written against.
+	EspressoBackendDockerCompose EspressoBackend = iota
+	// EspressoBackendInProcess boots a pure-Go mock HotShot sequencer and geth proxy in the test
+	// process instead, so Espresso e2e coverage can run without Docker, in parallel, and under
+	// -race.
+	EspressoBackendInProcess
+)
+
+// DockerComposeEspressoSystem drives the orchestrator, da-server, consensus-server, sequencer0/1,
+// commitment-task, and op-geth-proxy services via docker compose.
+type DockerComposeEspressoSystem struct {
 	composeFile   string
 	projectName   string
 	sequencerPort uint16
 	proxyPort     uint16
 	logsProcess   *exec.Cmd
+
+	// logTap is created lazily, on the first call to Logs, so tests that never ask for structured
+	// logs don't pay for an extra `docker compose logs -f` process.
+	logTap *LogTap
+}
+
+// Logs returns a LogTap over this project's service logs, starting one on first use.
+func (e *DockerComposeEspressoSystem) Logs() (*LogTap, error) {
+	if e.logTap == nil {
+		tap, err := NewLogTap(e.projectName, e.composeFile, 1000)
+		if err != nil {
+			return nil, err
+		}
+		e.logTap = tap
+	}
+	return e.logTap, nil
 }
 
-func (e *EspressoSystem) SequencerUrl() string {
+// SetLogLevel restarts service in place with RUST_LOG set to level. There is no live log-level
+// control endpoint on these services, so this is the best available mechanism: it recreates just
+// the one container, leaving the rest of the network untouched.
+func (e *DockerComposeEspressoSystem) SetLogLevel(service string, level string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker", "compose", "--project-name", e.projectName,
+		"-f", e.composeFile, "up", "-d", "--force-recreate", service)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("RUST_LOG=%s", level))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("docker compose up %s (set log level) (%v) error: %w output: %s", service, cmd, err, out)
+	}
+	return nil
+}
+
+func (e *DockerComposeEspressoSystem) SequencerUrl() string {
 	return fmt.Sprintf("http://localhost:%d", e.sequencerPort)
 }
 
-func (e *EspressoSystem) ProxyUrl() string {
+func (e *DockerComposeEspressoSystem) ProxyUrl() string {
 	return fmt.Sprintf("http://localhost:%d", e.proxyPort)
 }
 
-func (e *EspressoSystem) WaitForBlockHeight(ctx context.Context, height uint64) error {
+func (e *DockerComposeEspressoSystem) WaitForBlockHeight(ctx context.Context, height uint64) error {
 	url := e.SequencerUrl() + "/status/latest_block_height"
 	for {
 		res, err := http.Get(url)
@@ -303,7 +453,7 @@ func (e *EspressoSystem) WaitForBlockHeight(ctx context.Context, height uint64)
 	}
 }
 
-func (e *EspressoSystem) StartGethProxy(sequencer *node.Node) error {
+func (e *DockerComposeEspressoSystem) StartGethProxy(sequencer *node.Node) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 	cmd := exec.CommandContext(ctx,
@@ -329,7 +479,34 @@ func (e *EspressoSystem) StartGethProxy(sequencer *node.Node) error {
 	return nil
 }
 
-func (e *EspressoSystem) PrintLogs() {
+// PauseService pauses a single docker-compose service in place (docker compose pause, not down),
+// so tests can simulate an outage of one Espresso component -- e.g. the commitment-task service
+// that keeps the HotShot light client contract's liveness timestamp fresh -- without tearing down
+// or losing the state of the rest of the network.
+func (e *DockerComposeEspressoSystem) PauseService(service string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker", "compose", "--project-name", e.projectName,
+		"-f", e.composeFile, "pause", service)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("docker compose pause %s (%v) error: %w output: %s", service, cmd, err, out)
+	}
+	return nil
+}
+
+// ResumeService undoes a prior PauseService call for the same service.
+func (e *DockerComposeEspressoSystem) ResumeService(service string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker", "compose", "--project-name", e.projectName,
+		"-f", e.composeFile, "unpause", service)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("docker compose unpause %s (%v) error: %w output: %s", service, cmd, err, out)
+	}
+	return nil
+}
+
+func (e *DockerComposeEspressoSystem) PrintLogs() {
 	logs := exec.Command("docker", "compose", "--project-name", e.projectName, "-f", e.composeFile, "logs")
 	logs.Stdout = os.Stdout
 	logs.Stderr = os.Stderr
@@ -338,7 +515,7 @@ func (e *EspressoSystem) PrintLogs() {
 	}
 }
 
-func (e *EspressoSystem) AttachLogs() error {
+func (e *DockerComposeEspressoSystem) AttachLogs() error {
 	// Forward service logs to our stdout.
 	logs := exec.Command("docker", "compose", "--project-name", e.projectName, "-f", e.composeFile, "logs", "-f")
 	logs.Stdout = os.Stdout
@@ -350,7 +527,7 @@ func (e *EspressoSystem) AttachLogs() error {
 	return nil
 }
 
-func (e *EspressoSystem) Close() {
+func (e *DockerComposeEspressoSystem) Close() {
 	// Kill the docker-compose environment.
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
@@ -369,6 +546,10 @@ func (e *EspressoSystem) Close() {
 			log.Error("failed to wait for docker-compose logs", "err", err)
 		}
 	}
+
+	if e.logTap != nil {
+		e.logTap.Close()
+	}
 }
 
 func dockerComposePort(projectName string, composeFile string, service string, internalPort uint16) (uint16, error) {
@@ -388,6 +569,55 @@ func dockerComposePort(projectName string, composeFile string, service string, i
 	return uint16(port), nil
 }
 
+// startDockerComposeEspressoSystem brings up the orchestrator, da-server, consensus-server,
+// sequencer0/1, and commitment-task docker-compose services, pointed at l1Node, and returns a
+// DockerComposeEspressoSystem wrapping them.
+func startDockerComposeEspressoSystem(ctx context.Context, l1Node *node.Node, l2BlockTime uint64) (*DockerComposeEspressoSystem, error) {
+	// Find the docker-compose file.
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cwd: %w", err)
+	}
+	root, err := config.FindMonorepoRoot(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find monorepo root: %w", err)
+	}
+	composeFile := filepath.Join(root, "ops-bedrock", "docker-compose.yml")
+
+	// Generate a random project name to distinguish this docker-compose network from that of
+	// other tests running in parallel.
+	projectName := fmt.Sprintf("e2e-tests-%d", prng.Int63())
+
+	// Start the services.
+	cmd := exec.CommandContext(ctx,
+		"docker", "compose", "--project-name", projectName, "-f", composeFile,
+		"up", "orchestrator", "da-server", "consensus-server", "sequencer0", "sequencer1", "commitment-task",
+		"-V", "--force-recreate", "--wait")
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	cmd.Stdout = &stderr
+	// Point the sequencer at the L1 Geth node.
+	cmd.Env = append(cmd.Env, fmt.Sprintf("ESPRESSO_SEQUENCER_L1_PROVIDER=%s", httpEndpointForDocker(l1Node)))
+	// Make the Espresso block time faster than the OP block time, or else tests will time out.
+	cmd.Env = append(cmd.Env, fmt.Sprintf("ESPRESSO_ORCHESTRATOR_MAX_PROPOSE_TIME=%dms", l2BlockTime*1000/2))
+	cmd.Env = append(cmd.Env, "RUST_LOG=info")
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("docker compose up (%v) error: %w output: %s", cmd, err, stderr.String())
+	}
+
+	// Find the ports which were randomly assigned to the services.
+	sequencerPort, err := dockerComposePort(projectName, composeFile, "sequencer0", 8080)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sequencer0 port: %w", err)
+	}
+
+	return &DockerComposeEspressoSystem{
+		projectName:   projectName,
+		composeFile:   composeFile,
+		sequencerPort: sequencerPort,
+	}, nil
+}
+
 type systemConfigHook func(sCfg *SystemConfig, s *System)
 
 type SystemConfigOption struct {
@@ -432,6 +662,7 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		Clients:     make(map[string]*ethclient.Client),
 		RollupNodes: make(map[string]*rollupNode.OpNode),
 	}
+	sys.ctx, sys.cancel = context.WithCancel(context.Background())
 	didErrAfterStart := false
 	defer func() {
 		if didErrAfterStart {
@@ -495,54 +726,30 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		return nil, fmt.Errorf("failed to connect to L1 geth: %w", err)
 	}
 	l1Client := ethclient.NewClient(rpc.DialInProc(l1Srv))
+	if len(cfg.L1FallbackNodes) > 0 {
+		endpoints := append([]string{selectEndpoint(l1Node)}, nodeEndpoints(cfg.L1FallbackNodes)...)
+		l1Client, err = DialEthClientWithTimeoutAndFallback(sys.ctx, 5*time.Second, endpoints...)
+		if err != nil {
+			didErrAfterStart = true
+			return nil, fmt.Errorf("failed to connect to L1 geth with fallback: %w", err)
+		}
+	}
 	sys.Clients["l1"] = l1Client
 
 	// Start an Espresso sequencer network, if required.
 	if cfg.DeployConfig.Espresso {
-		// Find the docker-compose file.
-		cwd, err := os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get cwd: %w", err)
-		}
-		root, err := config.FindMonorepoRoot(cwd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to find monorepo root: %w", err)
-		}
-		composeFile := filepath.Join(root, "ops-bedrock", "docker-compose.yml")
-
-		// Generate a random project name to distinguish this docker-compose network from that of
-		// other tests running in parallel.
-		projectName := fmt.Sprintf("e2e-tests-%d", prng.Int63())
-
-		// Start the services.
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		ctx, cancel := context.WithTimeout(sys.ctx, 60*time.Second)
 		defer cancel()
-		cmd := exec.CommandContext(ctx,
-			"docker", "compose", "--project-name", projectName, "-f", composeFile,
-			"up", "orchestrator", "da-server", "consensus-server", "sequencer0", "sequencer1", "commitment-task",
-			"-V", "--force-recreate", "--wait")
-		stderr := bytes.Buffer{}
-		cmd.Stderr = &stderr
-		cmd.Stdout = &stderr
-		// Point the sequencer at the L1 Geth node.
-		cmd.Env = append(cmd.Env, fmt.Sprintf("ESPRESSO_SEQUENCER_L1_PROVIDER=%s", httpEndpointForDocker(l1Node)))
-		// Make the Espresso block time faster than the OP block time, or else tests will time out.
-		cmd.Env = append(cmd.Env, fmt.Sprintf("ESPRESSO_ORCHESTRATOR_MAX_PROPOSE_TIME=%dms", cfg.DeployConfig.L2BlockTime*1000/2))
-		cmd.Env = append(cmd.Env, "RUST_LOG=info")
-		if err := cmd.Run(); err != nil {
-			return nil, fmt.Errorf("docker compose up (%v) error: %w output: %s", cmd, err, stderr.String())
-		}
-
-		// Find the ports which were randomly assigned to the services.
-		sequencerPort, err := dockerComposePort(projectName, composeFile, "sequencer0", 8080)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get sequencer0 port: %w", err)
-		}
 
-		sys.Espresso = &EspressoSystem{
-			projectName:   projectName,
-			composeFile:   composeFile,
-			sequencerPort: sequencerPort,
+		switch cfg.EspressoBackend {
+		case EspressoBackendInProcess:
+			sys.Espresso = newInProcessEspressoSystem()
+		default:
+			esys, err := startDockerComposeEspressoSystem(ctx, l1Node, cfg.DeployConfig.L2BlockTime)
+			if err != nil {
+				return nil, err
+			}
+			sys.Espresso = esys
 		}
 
 		// Wait for Espresso to start producing blocks. Because of pipelining, the first block can
@@ -556,7 +763,7 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 	}
 
 	// Initialize L2
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(sys.ctx, 2*time.Second)
 	defer cancel()
 	l1Block, err := l1Client.BlockByNumber(ctx, nil)
 	if err != nil {
@@ -663,7 +870,7 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 	}
 
 	// Geth Clients
-	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel = context.WithTimeout(sys.ctx, 2*time.Second)
 	defer cancel()
 	for name, node := range sys.Nodes {
 		var endpoint string
@@ -688,7 +895,10 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		return nil, fmt.Errorf("waiting for blocks: %w", err)
 	}
 
-	sys.Mocknet = mocknet.New()
+	sys.p2pAdapter = defaultP2PAdapter(&cfg)
+	if mn, ok := sys.p2pAdapter.(*MocknetP2PAdapter); ok {
+		sys.Mocknet = mn.net
+	}
 
 	p2pNodes := make(map[string]*p2p.Prepared)
 	if cfg.P2PTopology != nil {
@@ -697,7 +907,7 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 			if p, ok := p2pNodes[name]; ok {
 				return p, nil
 			}
-			h, err := sys.newMockNetPeer()
+			h, err := sys.p2pAdapter.NewPeer(name)
 			if err != nil {
 				return nil, fmt.Errorf("failed to init p2p host for node %s", name)
 			}
@@ -718,18 +928,14 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 			return p, nil
 		}
 		for k, vs := range cfg.P2PTopology {
-			peerA, err := initHostMaybe(k)
+			_, err := initHostMaybe(k)
 			if err != nil {
-				return nil, fmt.Errorf("failed to setup mocknet peer %s", k)
+				return nil, fmt.Errorf("failed to setup p2p peer %s", k)
 			}
 			for _, v := range vs {
 				v = strings.TrimPrefix(v, "~")
-				peerB, err := initHostMaybe(v)
-				if err != nil {
-					return nil, fmt.Errorf("failed to setup mocknet peer %s (peer of %s)", v, k)
-				}
-				if _, err := sys.Mocknet.LinkPeers(peerA.HostP2P.ID(), peerB.HostP2P.ID()); err != nil {
-					return nil, fmt.Errorf("failed to setup mocknet link between %s and %s", k, v)
+				if _, err := initHostMaybe(v); err != nil {
+					return nil, fmt.Errorf("failed to setup p2p peer %s (peer of %s)", v, k)
 				}
 				// connect the peers after starting the full rollup node
 			}
@@ -768,12 +974,12 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 
 		c.Rollup.LogDescription(cfg.Loggers[name], chaincfg.L2ChainIDToNetworkName)
 
-		node, err := rollupNode.New(context.Background(), &c, cfg.Loggers[name], snapLog, "", metrics.NewMetrics(""))
+		node, err := rollupNode.New(sys.ctx, &c, cfg.Loggers[name], snapLog, "", metrics.NewMetrics(""))
 		if err != nil {
 			didErrAfterStart = true
 			return nil, fmt.Errorf("failed to create rollup node %s: %w", name, err)
 		}
-		err = node.Start(context.Background())
+		err = node.Start(sys.ctx)
 		if err != nil {
 			didErrAfterStart = true
 			return nil, fmt.Errorf("failed to start rollup node %s: %w", name, err)
@@ -790,16 +996,14 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		// so GossipSub and other p2p protocols can be started before the connections go live.
 		// This way protocol negotiation happens correctly.
 		for k, vs := range cfg.P2PTopology {
-			peerA := p2pNodes[k]
 			for _, v := range vs {
 				unconnected := strings.HasPrefix(v, "~")
 				if unconnected {
 					v = v[1:]
 				}
 				if !unconnected {
-					peerB := p2pNodes[v]
-					if _, err := sys.Mocknet.ConnectPeers(peerA.HostP2P.ID(), peerB.HostP2P.ID()); err != nil {
-						return nil, fmt.Errorf("failed to setup mocknet connection between %s and %s", k, v)
+					if err := sys.p2pAdapter.Connect(k, v); err != nil {
+						return nil, fmt.Errorf("failed to setup p2p connection between %s and %s: %w", k, v, err)
 					}
 				}
 			}
@@ -831,6 +1035,10 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 	if err := sys.L2OutputSubmitter.Start(); err != nil {
 		return nil, fmt.Errorf("unable to start l2 output submitter: %w", err)
 	}
+	go func() {
+		<-sys.ctx.Done()
+		sys.stopL2OutputSubmitter()
+	}()
 
 	// Batch Submitter
 	sys.BatchSubmitter, err = bss.NewBatchSubmitterFromCLIConfig(bss.CLIConfig{
@@ -840,6 +1048,8 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		MaxPendingTransactions: 0,
 		MaxChannelDuration:     1,
 		MaxL1TxSize:            240_000,
+		DataAvailabilityType:   string(cfg.BatcherTxType),
+		MaxBlobsPerTx:          cfg.MaxBlobsPerTx,
 		CompressorConfig: compressor.CLIConfig{
 			TargetL1TxSizeBytes: cfg.BatcherTargetL1TxSizeBytes,
 			TargetNumFrames:     1,
@@ -862,56 +1072,15 @@ func (cfg SystemConfig) Start(_opts ...SystemConfigOption) (*System, error) {
 		if err := sys.BatchSubmitter.Start(); err != nil {
 			return nil, fmt.Errorf("unable to start batch submitter: %w", err)
 		}
+		go func() {
+			<-sys.ctx.Done()
+			sys.stopBatchSubmitter()
+		}()
 	}
 
 	return sys, nil
 }
 
-// IP6 range that gets blackholed (in case our traffic ever makes it out onto
-// the internet).
-var blackholeIP6 = net.ParseIP("100::")
-
-// mocknet doesn't allow us to add a peerstore without fully creating the peer ourselves
-func (sys *System) newMockNetPeer() (host.Host, error) {
-	sk, _, err := ic.GenerateECDSAKeyPair(rand.Reader)
-	if err != nil {
-		return nil, err
-	}
-	id, err := peer.IDFromPrivateKey(sk)
-	if err != nil {
-		return nil, err
-	}
-	suffix := id
-	if len(id) > 8 {
-		suffix = id[len(id)-8:]
-	}
-	ip := append(net.IP{}, blackholeIP6...)
-	copy(ip[net.IPv6len-len(suffix):], suffix)
-	a, err := ma.NewMultiaddr(fmt.Sprintf("/ip6/%s/tcp/4242", ip))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create test multiaddr: %w", err)
-	}
-	p, err := peer.IDFromPublicKey(sk.GetPublic())
-	if err != nil {
-		return nil, err
-	}
-
-	ps, err := pstoremem.NewPeerstore()
-	if err != nil {
-		return nil, err
-	}
-	ps.AddAddr(p, a, peerstore.PermanentAddrTTL)
-	_ = ps.AddPrivKey(p, sk)
-	_ = ps.AddPubKey(p, sk.GetPublic())
-
-	ds := sync.MutexWrap(ds.NewMapDatastore())
-	eps, err := store.NewExtendedPeerstore(context.Background(), log.Root(), clock.SystemClock, ps, ds, 24*time.Hour)
-	if err != nil {
-		return nil, err
-	}
-	return sys.Mocknet.AddPeerWithPeerstore(p, eps)
-}
-
 func selectEndpoint(node *node.Node) string {
 	useHTTP := os.Getenv("OP_E2E_USE_HTTP") == "true"
 	if useHTTP {
@@ -932,6 +1101,33 @@ func httpEndpointForDocker(node *node.Node) string {
 	return fmt.Sprintf("http://host.docker.internal:%s", port)
 }
 
+func nodeEndpoints(nodes []*node.Node) []string {
+	endpoints := make([]string, len(nodes))
+	for i, n := range nodes {
+		endpoints[i] = selectEndpoint(n)
+	}
+	return endpoints
+}
+
+// DialEthClientWithTimeoutAndFallback dials each endpoint in order, allowing up to timeout per
+// attempt, and returns a client for the first one that connects. It is used to build
+// sys.Clients["l1"] when SystemConfig.L1FallbackNodes is set. The fallback happens only at dial
+// time: the returned client stays bound to the endpoint it connected to and does not move to
+// another endpoint if that one stops answering mid-run.
+func DialEthClientWithTimeoutAndFallback(ctx context.Context, timeout time.Duration, endpoints ...string) (*ethclient.Client, error) {
+	var lastErr error
+	for _, endpoint := range endpoints {
+		dialCtx, cancel := context.WithTimeout(ctx, timeout)
+		client, err := ethclient.DialContext(dialCtx, endpoint)
+		cancel()
+		if err == nil {
+			return client, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("failed to dial any of %d L1 endpoints, last error: %w", len(endpoints), lastErr)
+}
+
 func configureL1(rollupNodeCfg *rollupNode.Config, l1Node *node.Node) {
 	l1EndpointConfig := selectEndpoint(l1Node)
 	rollupNodeCfg.L1 = &rollupNode.L1EndpointConfig{
@@ -943,7 +1139,7 @@ func configureL1(rollupNodeCfg *rollupNode.Config, l1Node *node.Node) {
 		HttpPollInterval: time.Millisecond * 100,
 	}
 }
-func configureL2(rollupNodeCfg *rollupNode.Config, l2Node *node.Node, espresso *EspressoSystem, jwtSecret [32]byte) {
+func configureL2(rollupNodeCfg *rollupNode.Config, l2Node *node.Node, espresso EspressoSystem, jwtSecret [32]byte) {
 	useHTTP := os.Getenv("OP_E2E_USE_HTTP") == "true"
 	l2EndpointConfig := l2Node.WSAuthEndpoint()
 	if useHTTP {
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// transactionWireHex is the wire format Transaction is sent to the sequencer in when
+// --submit-encoding is "hex" (the default): the raw payload as a single 0x-prefixed hex string,
+// instead of one JSON number per byte. For uniformly distributed bytes this cuts the request body to
+// a little over half its size (2 characters per byte instead of about 3.6) and removes the O(n) int conversion the old []int encoding needed per submission.
+type transactionWireHex struct {
+	Vm      int    `json:"vm"`
+	Payload string `json:"payload"`
+}
+
+// transactionWireInts is the original wire format: one JSON integer per payload byte. It is kept
+// available behind --submit-encoding=ints for sequencer deployments that have not picked up the hex
+// format yet.
+type transactionWireInts struct {
+	Vm      int   `json:"vm"`
+	Payload []int `json:"payload"`
+}
+
+// encodeTransactionForWire marshals txn in the wire format selected by --submit-encoding.
+func encodeTransactionForWire(txn Transaction, encoding string) ([]byte, error) {
+	switch encoding {
+	case "hex", "":
+		return json.Marshal(transactionWireHex{Vm: txn.Vm, Payload: "0x" + hex.EncodeToString(txn.Payload)})
+	case "ints":
+		payload := make([]int, len(txn.Payload))
+		for i, b := range txn.Payload {
+			payload[i] = int(b)
+		}
+		return json.Marshal(transactionWireInts{Vm: txn.Vm, Payload: payload})
+	default:
+		return nil, fmt.Errorf("unknown --submit-encoding %q (expected \"hex\" or \"ints\")", encoding)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+// benchmarkPayloadSize approximates a realistic blob-carrying transaction: roughly the size of an
+// EIP-4844 blob-carrying transaction's calldata wrapper.
+const benchmarkPayloadSize = 100 * 1024
+
+func makeBenchmarkTransaction() Transaction {
+	payload := make([]byte, benchmarkPayloadSize)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	return Transaction{Vm: 1, Payload: payload}
+}
+
+// BenchmarkEncodeTransactionForWireHex and BenchmarkEncodeTransactionForWireInts bracket the size
+// and CPU cost of --submit-encoding=hex against the legacy --submit-encoding=ints for a realistic
+// 100KB blob-carrying transaction; run with -benchmem to compare allocations and encoded size
+// (via ReportMetric below) alongside the usual ns/op.
+func BenchmarkEncodeTransactionForWireHex(b *testing.B) {
+	txn := makeBenchmarkTransaction()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		encoded, err := encodeTransactionForWire(txn, "hex")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if i == 0 {
+			b.ReportMetric(float64(len(encoded)), "bytes/submission")
+		}
+	}
+}
+
+func BenchmarkEncodeTransactionForWireInts(b *testing.B) {
+	txn := makeBenchmarkTransaction()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		encoded, err := encodeTransactionForWire(txn, "ints")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if i == 0 {
+			b.ReportMetric(float64(len(encoded)), "bytes/submission")
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"flag"
@@ -11,6 +12,8 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/peterbourgon/ff/v3"
 )
@@ -19,23 +22,110 @@ import (
 const ENV_PREFIX = "OP_GETH_PROXY"
 
 var (
-	fs            = flag.NewFlagSet("proxy", flag.ContinueOnError)
-	listenAddr    = fs.String("listen-addr", "127.0.0.1:9090", "proxy's listening address")
-	sequencerAddr = fs.String("seq-addr", "http://127.0.0.1:50000", "address of espresso sequencer")
-	gethAddr      = fs.String("geth-addr", "http://127.0.0.1:8545", "address of the op-geth node")
-	vm_id         = fs.Int("vm-id", 1, "VM ID of the OP rollup instance")
+	fs                   = flag.NewFlagSet("proxy", flag.ContinueOnError)
+	listenAddr           = fs.String("listen-addr", "127.0.0.1:9090", "proxy's listening address")
+	sequencerAddr        = fs.String("seq-addr", "http://127.0.0.1:50000", "address of espresso sequencer")
+	gethAddr             = fs.String("geth-addr", "http://127.0.0.1:8545", "address of the op-geth node")
+	vm_id                = fs.Int("vm-id", 1, "VM ID of the OP rollup instance")
+	sequencerMethods     = fs.String("sequencer-methods", "eth_sendRawTransaction", "comma-separated list of JSON-RPC methods to divert to the espresso sequencer")
+	submitTimeout        = fs.Duration("submit-timeout", 5*time.Second, "timeout for a single POST to the sequencer's /submit/submit")
+	submitRetries        = fs.Int("submit-retries", 5, "number of retries, with exponential backoff, before a queued submission is dropped")
+	queueDir             = fs.String("queue-dir", "", "directory for the on-disk submission queue WAL; empty disables persistence across restarts")
+	queueMax             = fs.Int("queue-max", 1024, "maximum number of submissions buffered before Enqueue blocks, applying backpressure")
+	submitEncoding       = fs.String("submit-encoding", "hex", `wire encoding for POST /submit/submit: "hex" (0x-prefixed payload string) or "ints" (legacy one-JSON-number-per-byte array)`)
+	wsListenAddr         = fs.String("ws-listen-addr", "", "proxy's WebSocket listening address; empty disables the WebSocket transport")
+	ipcPath              = fs.String("ipc-path", "", "proxy's Unix domain socket path; empty disables the IPC transport")
+	corsOrigins          = fs.String("cors-origins", "", `comma-separated list of allowed CORS origins, or "*" to allow any; empty disables CORS headers`)
+	maxRequestSize       = fs.Int64("max-request-content-length", 5*1024*1024, "maximum accepted request body size in bytes")
+	jwtSecretPath        = fs.String("jwt-secret", "", "path to a hex-encoded HS256 secret file; empty disables bearer-token auth")
+	sequencerBackendName = fs.String("sequencer-backend", "espresso", `sequencer backend to submit raw transactions to: "espresso", "passthrough", or "tee"`)
 )
 
+// submitQueue buffers and retries submissions to the sequencer; see SubmitQueue's doc comment.
+var submitQueue *SubmitQueue
+
+// sequencerBackend is where ForwardToSequencer actually submits a raw transaction; see
+// SequencerBackend's doc comment.
+var sequencerBackend SequencerBackend
+
+// sequencerMethodSet is the parsed, lookup-ready form of sequencerMethods, populated once in main
+// after flags are parsed.
+var sequencerMethodSet = map[string]bool{}
+
+// parseSequencerMethods turns the --sequencer-methods flag value into a set, so that new
+// bundle-style submission methods (e.g. eth_sendRawTransactionConditional) can be diverted to the
+// sequencer alongside eth_sendRawTransaction without hardcoding them here.
+func parseSequencerMethods(methods string) map[string]bool {
+	set := make(map[string]bool)
+	for _, method := range strings.Split(methods, ",") {
+		method = strings.TrimSpace(method)
+		if method != "" {
+			set[method] = true
+		}
+	}
+	return set
+}
+
+// Transaction is the proxy's internal representation of a queued raw transaction. Payload is kept
+// as raw bytes here regardless of --submit-encoding; encodeTransactionForWire converts it to the
+// sequencer's wire format only at submission time. The json tags below are only used for the
+// on-disk queue WAL (an internal format, independent of the wire format), where []byte marshals as
+// a base64 string by default.
 type Transaction struct {
-	Vm      int   `json:"vm"`
-	Payload []int `json:"payload"`
+	Vm      int    `json:"vm"`
+	Payload []byte `json:"payload"`
 }
 
 type rpcMessage struct {
-	Params []json.RawMessage `json:"params,omitempty"`
-	Method string            `json:"method,omitempty"`
+	JSONRPC string            `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage   `json:"id,omitempty"`
+	Params  []json.RawMessage `json:"params,omitempty"`
+	Method  string            `json:"method,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object, as returned in the "error" field of an error response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcErrorResponse is a complete JSON-RPC 2.0 error response.
+type rpcErrorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   rpcError        `json:"error"`
+}
+
+// writeRPCError writes a JSON-RPC 2.0 error response to w. id is nil when the request could not be
+// parsed far enough to recover one, which the JSON-RPC spec allows for parse errors.
+func writeRPCError(w http.ResponseWriter, id json.RawMessage, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	encoded, err := json.Marshal(rpcErrorResponse{JSONRPC: "2.0", ID: id, Error: rpcError{Code: code, Message: message}})
+	if err != nil {
+		log.Println("Failed to marshal JSON-RPC error response:", err)
+		return
+	}
+	w.Write(encoded)
+}
+
+// decodeRPCMessages parses body as either a single JSON-RPC request object or, per the JSON-RPC 2.0
+// batch format, an array of request objects. body must already be known to be valid JSON; a failure
+// here means it was valid JSON of the wrong shape (e.g. a bare string or number).
+func decodeRPCMessages(body []byte) (messages []rpcMessage, err error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &messages)
+		return messages, err
+	}
+	var message rpcMessage
+	if err := json.Unmarshal(trimmed, &message); err != nil {
+		return nil, err
+	}
+	return []rpcMessage{message}, nil
 }
 
+// ForwardToSequencer submits message's raw transaction via the configured --sequencer-backend (see
+// SequencerBackend), which decides whether and how it actually reaches Espresso.
 func ForwardToSequencer(message rpcMessage) {
 	var hexString string
 	if err := json.Unmarshal(message.Params[0], &hexString); err != nil {
@@ -48,37 +138,9 @@ func ForwardToSequencer(message rpcMessage) {
 		return
 	}
 
-	// json.RawMessage is a []byte array, which is marshalled
-	// As a base64-encoded string. Our sequencer API expects a JSON array.
-	payload := make([]int, len(txnBytes))
-	for i := range payload {
-		payload[i] = int(txnBytes[i])
-	}
-
-	// Construct a transaction and send it to the sequencer
-	txn := Transaction{
-		Vm:      *vm_id,
-		Payload: payload,
-	}
-	marshalled, err := json.Marshal(txn)
-	if err != nil {
-		panic(err)
-	}
-	request, err := http.NewRequest("POST", *sequencerAddr+"/submit/submit", bytes.NewBuffer(marshalled))
-	if err != nil {
-		panic(err)
-	}
-	request.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
-	log.Println("Transaction received, forwarding to sequencer.")
-	response, err := client.Do(request)
-	if err != nil {
-		log.Println("Failed to connect to the sequencer: ", err)
-		return
-	}
-	defer response.Body.Close()
-	if response.StatusCode != 200 {
-		log.Println("Request failed. Here is the response: ", err)
+	log.Println("Transaction received, submitting via sequencer backend.")
+	if err := sequencerBackend.Submit(context.Background(), *vm_id, txnBytes); err != nil {
+		log.Println("Failed to submit transaction to sequencer backend: ", err)
 	}
 }
 
@@ -90,23 +152,46 @@ func (h *baseHandle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 	proxy := httputil.NewSingleHostReverseProxy(toUrl)
-	body, err := io.ReadAll(r.Body)
+
+	// Reject an oversize request before reading it in full: a declared Content-Length over the
+	// limit is rejected outright, and a body of unknown or lying length is still bounded by reading
+	// only maxRequestSize+1 bytes, rather than io.ReadAll's previous unbounded read (which could
+	// panic the handler on a sufficiently large or broken body).
+	if r.ContentLength > *maxRequestSize {
+		writeRPCError(w, nil, -32700, "Request too large")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, *maxRequestSize+1))
 	if err != nil {
-		panic(err)
+		log.Println("Invalid request: failed to read body:", err)
+		writeRPCError(w, nil, -32700, "Parse error")
+		return
+	}
+	if int64(len(body)) > *maxRequestSize {
+		writeRPCError(w, nil, -32700, "Request too large")
+		return
 	}
 	// Once we've read the body, we need to replace it with another reader because
 	// ReadAll can only be called once: https://blog.flexicondev.com/read-go-http-request-body-multiple-times
 	r.Body = io.NopCloser(bytes.NewBuffer(body))
 
-	var message rpcMessage
-	if err := json.Unmarshal(body, &message); err != nil {
-		log.Println("Invalid request: expected RPC message")
+	if !json.Valid(body) {
+		log.Println("Invalid request: body is not valid JSON")
+		writeRPCError(w, nil, -32700, "Parse error")
 		return
 	}
-	// Check for sendRawTransaction
-	if message.Method == "eth_sendRawTransaction" {
-		ForwardToSequencer(message)
+	messages, err := decodeRPCMessages(body)
+	if err != nil {
+		log.Println("Invalid request: expected an RPC message or a batch of RPC messages:", err)
+		writeRPCError(w, nil, -32600, "Invalid Request")
+		return
 	}
+	// Divert every sub-request (there is exactly one outside of the JSON-RPC batch format) whose
+	// method is in the configured sequencer allow-list; everything else, including the rest of a
+	// batch, still goes to op-geth below. The WebSocket and IPC transports share this same check
+	// via divertSequencerMethods, so eth_sendRawTransaction is diverted no matter which transport a
+	// client used to reach the proxy.
+	divertSequencerMethods(messages)
 	proxy.ServeHTTP(w, r)
 }
 
@@ -114,14 +199,46 @@ func main() {
 	if err := ff.Parse(fs, os.Args[1:], ff.WithEnvVarPrefix(ENV_PREFIX)); err != nil {
 		panic(err)
 	}
+	sequencerMethodSet = parseSequencerMethods(*sequencerMethods)
+	var err error
+	submitQueue, err = NewSubmitQueue(*sequencerAddr, *queueDir, *queueMax, *submitRetries, *submitTimeout)
+	if err != nil {
+		panic(err)
+	}
+	submitQueue.Start()
+	backend, err := newSequencerBackend(*sequencerBackendName)
+	if err != nil {
+		panic(err)
+	}
+	sequencerBackend = backend
+
+	go serveIPC(*ipcPath)
+	if *wsListenAddr != "" {
+		go func() {
+			log.Println("Starting WebSocket proxy server on", *wsListenAddr)
+			if err := http.ListenAndServe(*wsListenAddr, wsHandler{}); err != nil {
+				log.Println("WebSocket server stopped:", err)
+			}
+		}()
+	}
 
-	h := &baseHandle{}
-	http.Handle("/", h)
+	var handler http.Handler = &baseHandle{}
+	if *jwtSecretPath != "" {
+		secret, err := loadJWTSecret(*jwtSecretPath)
+		if err != nil {
+			panic(err)
+		}
+		handler = jwtMiddleware(secret, handler)
+	}
+	if *corsOrigins != "" {
+		handler = corsMiddleware(*corsOrigins, handler)
+	}
+	http.Handle("/", handler)
 
 	log.Println("Starting proxy server on", *listenAddr)
 	server := &http.Server{
 		Addr:    *listenAddr,
-		Handler: h,
+		Handler: handler,
 	}
 	log.Fatal(server.ListenAndServe())
 }
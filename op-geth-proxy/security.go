@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// parseCommaSeparatedSet turns a comma-separated flag value into a lookup set, trimming whitespace
+// around each entry; used by --cors-origins, and the same parsing parseSequencerMethods applies to
+// --sequencer-methods.
+func parseCommaSeparatedSet(values string) map[string]bool {
+	set := make(map[string]bool)
+	for _, value := range strings.Split(values, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
+
+// corsMiddleware sets Access-Control-Allow-* headers for requests whose Origin header is in the
+// --cors-origins allow-list (or any origin, if it is exactly "*"), and answers CORS preflight
+// OPTIONS requests directly. This checkout has no go.mod to vendor rs/cors in, so the handful of
+// headers a JSON-RPC frontend needs are set directly here instead.
+func corsMiddleware(allowedOrigins string, next http.Handler) http.Handler {
+	allowAll := allowedOrigins == "*"
+	allowed := parseCommaSeparatedSet(allowedOrigins)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && (allowAll || allowed[origin]) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// jwtClockSkew is the maximum allowed difference between a JWT's iat claim and the current time,
+// matching op-geth's engine API auth scheme, which rejects a token whose iat is more than 5 seconds
+// away from "now" in either direction.
+const jwtClockSkew = 5 * time.Second
+
+const bearerPrefix = "Bearer "
+
+// loadJWTSecret reads a hex-encoded HS256 secret from path, the same file format op-geth's
+// --authrpc.jwtsecret flag expects.
+func loadJWTSecret(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT secret file %q: %w", path, err)
+	}
+	secret, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("JWT secret file %q does not contain valid hex: %w", path, err)
+	}
+	return secret, nil
+}
+
+// jwtClaims is the subset of a JWT's payload claims this proxy checks: only iat, the same field
+// op-geth's engine API auth scheme uses to bound replay of a captured bearer token.
+type jwtClaims struct {
+	IssuedAt int64 `json:"iat"`
+}
+
+// verifyJWT validates token (the bearer token from an Authorization header, without the "Bearer "
+// prefix) as an HS256 JWT signed with secret and carrying a fresh iat claim. It deliberately only
+// implements what op-geth's engine API scheme needs (HS256, iat); this is not a general-purpose JWT
+// library, and this checkout has no go.mod to vendor one in.
+func verifyJWT(token string, secret []byte) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWT: expected 3 dot-separated parts, got %d", len(parts))
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed JWT header: %w", err)
+	}
+	var parsedHeader struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &parsedHeader); err != nil {
+		return fmt.Errorf("malformed JWT header: %w", err)
+	}
+	if parsedHeader.Alg != "HS256" {
+		return fmt.Errorf("unsupported JWT algorithm %q: only HS256 is supported", parsedHeader.Alg)
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	expectedSig := mac.Sum(nil)
+	actualSig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed JWT signature: %w", err)
+	}
+	if !hmac.Equal(expectedSig, actualSig) {
+		return fmt.Errorf("invalid JWT signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("malformed JWT payload: %w", err)
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return fmt.Errorf("malformed JWT payload: %w", err)
+	}
+	if skew := time.Since(time.Unix(claims.IssuedAt, 0)); skew > jwtClockSkew || skew < -jwtClockSkew {
+		return fmt.Errorf("JWT iat is outside the allowed %s clock skew", jwtClockSkew)
+	}
+	return nil
+}
+
+// jwtMiddleware rejects any request without a valid HS256 bearer token signed with secret, the same
+// auth scheme op-geth's engine API uses, so the proxy can sit on a public interface in front of a
+// private geth without exposing it directly.
+func jwtMiddleware(secret []byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authHeader, bearerPrefix) {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		if err := verifyJWT(authHeader[len(bearerPrefix):], secret); err != nil {
+			http.Error(w, "invalid bearer token: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
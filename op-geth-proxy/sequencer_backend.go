@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// SequencerBackend abstracts how a raw transaction reaches the sequencing layer, so the proxy
+// binary can run against Espresso, plain op-geth, or both without a source change. Adding a new
+// sequencer (Astria, a shared rollup sequencer, ...) is a single new file defining a
+// SequencerBackend implementation plus one entry in sequencerBackendFactories below.
+type SequencerBackend interface {
+	Submit(ctx context.Context, vmID int, rawTx []byte) error
+}
+
+// espressoBackend is the original behavior: durably enqueue the transaction on the SubmitQueue for
+// delivery to the Espresso sequencer.
+type espressoBackend struct {
+	queue *SubmitQueue
+}
+
+func (b *espressoBackend) Submit(ctx context.Context, vmID int, rawTx []byte) error {
+	return b.queue.Enqueue(Transaction{Vm: vmID, Payload: rawTx})
+}
+
+// passthroughBackend does nothing: it leaves op-geth's own mempool to handle the transaction via
+// the normal reverse-proxy forward that ServeHTTP always performs regardless of which
+// SequencerBackend is selected. Useful for local dev or staging environments run without Espresso.
+type passthroughBackend struct{}
+
+func (passthroughBackend) Submit(ctx context.Context, vmID int, rawTx []byte) error {
+	return nil
+}
+
+// teeBackend submits to Espresso exactly like espressoBackend; it exists as its own named backend
+// so that --sequencer-backend=tee is explicit that both the Espresso submission and op-geth's own
+// mempool (via ServeHTTP's unconditional reverse-proxy forward) are in play, for faster local
+// preconfirmations ahead of Espresso finality, without the reader having to know the latter always
+// happens regardless of backend.
+type teeBackend struct {
+	espresso espressoBackend
+}
+
+func (b teeBackend) Submit(ctx context.Context, vmID int, rawTx []byte) error {
+	return b.espresso.Submit(ctx, vmID, rawTx)
+}
+
+// sequencerBackendFactories maps a --sequencer-backend name to a constructor. Only the selected
+// factory is called, once, in newSequencerBackend, after submitQueue has been constructed and
+// started.
+var sequencerBackendFactories = map[string]func() SequencerBackend{
+	"espresso":    func() SequencerBackend { return &espressoBackend{queue: submitQueue} },
+	"passthrough": func() SequencerBackend { return passthroughBackend{} },
+	"tee":         func() SequencerBackend { return teeBackend{espresso: espressoBackend{queue: submitQueue}} },
+}
+
+// newSequencerBackend constructs the SequencerBackend named by --sequencer-backend.
+func newSequencerBackend(name string) (SequencerBackend, error) {
+	factory, ok := sequencerBackendFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown --sequencer-backend %q", name)
+	}
+	return factory(), nil
+}
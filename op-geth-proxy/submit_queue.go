@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// submitQueueWorkers is the number of goroutines draining the submission queue. There is no
+// --submit-workers flag (the backlog item asks only for --submit-timeout/--submit-retries/
+// --queue-dir/--queue-max); this is small enough that a fixed pool is fine for a single sequencer
+// endpoint, the same way ForwardToSequencer previously used a single unbuffered *http.Client.
+const submitQueueWorkers = 4
+
+// submitQueueInitialBackoff is the delay before the first retry of a failed submission; it doubles
+// on each subsequent attempt, capped at submitQueueMaxBackoff.
+const submitQueueInitialBackoff = 200 * time.Millisecond
+const submitQueueMaxBackoff = 10 * time.Second
+
+// SubmitQueue buffers Transaction submissions to the espresso sequencer so that ForwardToSequencer
+// no longer loses a transaction it has already told op-geth about: submissions are appended to an
+// on-disk WAL before Enqueue returns, then drained by a pool of worker goroutines that POST to
+// /submit/submit with exponential backoff, so a sequencer outage delays delivery instead of
+// silently dropping it.
+//
+// This checkout has no go.mod/vendored dependencies to add a real embedded KV store (badger/bbolt)
+// to, so the WAL below is a plain append-only JSON-lines file, compacted by a full rewrite whenever
+// an entry is removed. That gives the same durability guarantee (a submission surviving a proxy
+// restart) at the cost of O(n) compaction instead of O(1) deletes; swapping in badger/bbolt once
+// this checkout has dependency management is a drop-in replacement for walAppend/compactWAL/loadWAL
+// below, which are the only methods that touch q.dir.
+type SubmitQueue struct {
+	client        *http.Client
+	sequencerAddr string
+	dir           string
+	retries       int
+
+	mu      sync.Mutex
+	pending map[uint64]Transaction
+	nextID  uint64
+
+	ch chan uint64
+	wg sync.WaitGroup
+
+	// queueDepth and retryCount are the counters a Prometheus /metrics endpoint would export as a
+	// gauge and a counter respectively; this checkout has no prometheus client library vendored, so
+	// QueueDepth/RetryCount below are exported for a caller (or a future /metrics handler) to read
+	// directly instead.
+	queueDepth int64
+	retryCount int64
+}
+
+// walEntry is one line of the on-disk queue WAL.
+type walEntry struct {
+	ID  uint64      `json:"id"`
+	Txn Transaction `json:"txn"`
+}
+
+// NewSubmitQueue constructs a SubmitQueue against sequencerAddr, replaying any WAL left behind in
+// dir by a previous run (dir == "" disables persistence: submissions are buffered in memory only,
+// and are lost across a restart the same as before this change). maxLen bounds the in-memory
+// channel; once it is full, Enqueue blocks, applying backpressure to ForwardToSequencer's caller.
+func NewSubmitQueue(sequencerAddr, dir string, maxLen, retries int, timeout time.Duration) (*SubmitQueue, error) {
+	q := &SubmitQueue{
+		client:        &http.Client{Timeout: timeout},
+		sequencerAddr: sequencerAddr,
+		dir:           dir,
+		retries:       retries,
+		pending:       make(map[uint64]Transaction),
+		ch:            make(chan uint64, maxLen),
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create queue dir %q: %w", dir, err)
+		}
+		if err := q.loadWAL(); err != nil {
+			return nil, fmt.Errorf("failed to load queue WAL: %w", err)
+		}
+	}
+	return q, nil
+}
+
+// Start launches the worker goroutines that drain the queue. It must be called once, after any
+// submissions loadWAL replayed are already in q.pending and q.ch.
+func (q *SubmitQueue) Start() {
+	for i := 0; i < submitQueueWorkers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+}
+
+func (q *SubmitQueue) walPath() string {
+	return filepath.Join(q.dir, "queue.jsonl")
+}
+
+// loadWAL replays a previous run's WAL file, if any, re-populating q.pending and re-queuing every
+// entry found onto q.ch so Start's workers retry them.
+func (q *SubmitQueue) loadWAL() error {
+	data, err := os.ReadFile(q.walPath())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry walEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			log.Println("Skipping corrupt queue WAL entry:", err)
+			continue
+		}
+		q.pending[entry.ID] = entry.Txn
+		if entry.ID >= q.nextID {
+			q.nextID = entry.ID + 1
+		}
+	}
+	for id := range q.pending {
+		atomic.AddInt64(&q.queueDepth, 1)
+		q.ch <- id
+	}
+	return nil
+}
+
+// compactWAL rewrites the WAL file to hold exactly q.pending, dropping entries that have already
+// been submitted or permanently given up on. The caller must hold q.mu.
+func (q *SubmitQueue) compactWAL() error {
+	tmp := q.walPath() + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for id, txn := range q.pending {
+		if err := enc.Encode(walEntry{ID: id, Txn: txn}); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.walPath())
+}
+
+// walAppend durably records txn under id by appending a single WAL line. The caller must hold q.mu.
+func (q *SubmitQueue) walAppend(id uint64, txn Transaction) error {
+	f, err := os.OpenFile(q.walPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	encoded, err := json.Marshal(walEntry{ID: id, Txn: txn})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(encoded, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// Enqueue durably records txn and hands it to the worker pool, blocking if the in-memory channel
+// is already at --queue-max capacity. It only returns once txn is durably enqueued (written to the
+// WAL, when --queue-dir is set), so a caller that acknowledges a forward to op-geth after Enqueue
+// returns will not lose txn even if the proxy crashes immediately afterwards.
+func (q *SubmitQueue) Enqueue(txn Transaction) error {
+	q.mu.Lock()
+	id := q.nextID
+	q.nextID++
+	q.pending[id] = txn
+	var err error
+	if q.dir != "" {
+		err = q.walAppend(id, txn)
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to durably enqueue submission: %w", err)
+	}
+
+	atomic.AddInt64(&q.queueDepth, 1)
+	q.ch <- id
+	return nil
+}
+
+// QueueDepth is the number of submissions currently buffered (enqueued but not yet successfully
+// submitted or permanently dropped).
+func (q *SubmitQueue) QueueDepth() int64 {
+	return atomic.LoadInt64(&q.queueDepth)
+}
+
+// RetryCount is the cumulative number of failed submission attempts across the queue's lifetime.
+func (q *SubmitQueue) RetryCount() int64 {
+	return atomic.LoadInt64(&q.retryCount)
+}
+
+func (q *SubmitQueue) worker() {
+	defer q.wg.Done()
+	for id := range q.ch {
+		q.mu.Lock()
+		txn, ok := q.pending[id]
+		q.mu.Unlock()
+		if !ok {
+			continue
+		}
+		q.submitWithRetry(id, txn)
+	}
+}
+
+// submitWithRetry POSTs txn to the sequencer, retrying with exponential backoff up to q.retries
+// times before giving up and dropping it.
+func (q *SubmitQueue) submitWithRetry(id uint64, txn Transaction) {
+	backoff := submitQueueInitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := q.submitOnce(txn)
+		if err == nil {
+			break
+		}
+		atomic.AddInt64(&q.retryCount, 1)
+		if attempt >= q.retries {
+			log.Printf("Submission %d to sequencer failed permanently after %d attempts, dropping: %v", id, attempt+1, err)
+			break
+		}
+		log.Printf("Submission %d to sequencer failed (attempt %d/%d), retrying in %s: %v", id, attempt+1, q.retries+1, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > submitQueueMaxBackoff {
+			backoff = submitQueueMaxBackoff
+		}
+	}
+
+	q.mu.Lock()
+	delete(q.pending, id)
+	err := q.compactWALLocked()
+	q.mu.Unlock()
+	if err != nil {
+		log.Println("Failed to compact queue WAL:", err)
+	}
+	atomic.AddInt64(&q.queueDepth, -1)
+}
+
+// compactWALLocked compacts the WAL if persistence is enabled, or is a no-op otherwise. The caller
+// must hold q.mu.
+func (q *SubmitQueue) compactWALLocked() error {
+	if q.dir == "" {
+		return nil
+	}
+	return q.compactWAL()
+}
+
+func (q *SubmitQueue) submitOnce(txn Transaction) error {
+	marshalled, err := encodeTransactionForWire(txn, *submitEncoding)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction for the sequencer: %w", err)
+	}
+	request, err := http.NewRequest("POST", q.sequencerAddr+"/submit/submit", bytes.NewBuffer(marshalled))
+	if err != nil {
+		return fmt.Errorf("failed to build sequencer request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := q.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to reach sequencer: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != 200 {
+		return fmt.Errorf("sequencer returned status %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package era
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/snappy"
+)
+
+// entryLeafHash commits to everything that makes an archived block provable: its execution
+// payload, its justification (if any), and its L1 origin. It hashes the same JSON encoding used to
+// persist the entry, rather than reaching into espresso.Commitment internals, so the accumulator
+// does not need to track the commitment scheme's exact byte layout.
+func entryLeafHash(entry Entry) common.Hash {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		// Entry always round-trips through the same encoding used by WriteArchive/ReadArchive, so a
+		// marshal failure here means a block that could never have been written to an archive in the
+		// first place.
+		panic(err)
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// accumulatorRoot folds leaves into a single Merkle root using a simple binary tree: pairs are
+// combined pairwise, and any odd leaf left over at a level is promoted unchanged to the next level.
+// This mirrors the "simple Merkle accumulator" used by the real Era1 format, rather than a
+// Namespaced Merkle Tree: archive entries have no namespace concept, so there is nothing for
+// completeness proofs (as in espresso.NmtProof) to enforce here.
+func accumulatorRoot(leaves []common.Hash) common.Hash {
+	if len(leaves) == 0 {
+		return common.Hash{}
+	}
+	level := leaves
+	for len(level) > 1 {
+		var next []common.Hash
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, crypto.Keccak256Hash(level[i][:], level[i+1][:]))
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func snappyEncode(data []byte) []byte {
+	return snappy.Encode(nil, data)
+}
+
+func snappyDecode(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
@@ -0,0 +1,185 @@
+// Package era provides an Era1-style archive format for cold storage of finalized L2 blocks: each
+// archive bundles one epoch's worth of blocks together with their Espresso Justification payloads,
+// L1 origin references, and a Merkle accumulator over the epoch, so that an operator can prune
+// safely while retaining the ability to prove historical Espresso sequencing decisions.
+package era
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// EpochSize is the number of blocks in one epoch's archive file. WriteArchive does not check it: it
+// writes every entry it is given into a single archive.
+const EpochSize = 8192
+
+// recordType identifies the kind of record stored in an archive. Records are read and written in
+// this order within a file: one recordBlock record per block, followed by a single
+// recordAccumulator record.
+type recordType uint16
+
+const (
+	recordBlock       recordType = 1
+	recordAccumulator recordType = 2
+)
+
+// Entry bundles one finalized L2 block with the data needed to re-verify it was derived correctly:
+// the Espresso justification that authorized its transactions, and the L1 origin it was built on.
+type Entry struct {
+	Block         *eth.ExecutionPayload
+	Justification *eth.L2BatchJustification
+	L1Origin      eth.L1BlockRef
+}
+
+// writeRecord writes one typed, length-prefixed, snappy-compressed record to w.
+func writeRecord(w io.Writer, typ recordType, payload []byte) error {
+	compressed := snappyEncode(payload)
+	var header [10]byte
+	binary.BigEndian.PutUint16(header[0:2], uint16(typ))
+	binary.BigEndian.PutUint64(header[2:10], uint64(len(compressed)))
+	if _, err := w.Write(header[:]); err != nil {
+		return fmt.Errorf("failed to write record header: %w", err)
+	}
+	if _, err := w.Write(compressed); err != nil {
+		return fmt.Errorf("failed to write record payload: %w", err)
+	}
+	return nil
+}
+
+// readRecord reads one typed, length-prefixed, snappy-compressed record from r. It returns io.EOF,
+// unwrapped, when there are no more records.
+func readRecord(r io.Reader) (recordType, []byte, error) {
+	var header [10]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, nil, fmt.Errorf("archive truncated mid-record header")
+		}
+		return 0, nil, err
+	}
+	typ := recordType(binary.BigEndian.Uint16(header[0:2]))
+	length := binary.BigEndian.Uint64(header[2:10])
+	compressed := make([]byte, length)
+	if _, err := io.ReadFull(r, compressed); err != nil {
+		return 0, nil, fmt.Errorf("archive truncated mid-record payload: %w", err)
+	}
+	payload, err := snappyDecode(compressed)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress record: %w", err)
+	}
+	return typ, payload, nil
+}
+
+// WriteArchive writes entries, which must be contiguous and in ascending block-number order, to w
+// as an Era1-style archive: one block record per entry, followed by an accumulator record
+// committing to all of them.
+func WriteArchive(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	var leaves []common.Hash
+	for i, entry := range entries {
+		if i > 0 && entry.Block.BlockNumber != entries[i-1].Block.BlockNumber+1 {
+			return fmt.Errorf("entries must be contiguous: block %d follows block %d", entry.Block.BlockNumber, entries[i-1].Block.BlockNumber)
+		}
+		payload, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode block %d: %w", entry.Block.BlockNumber, err)
+		}
+		if err := writeRecord(bw, recordBlock, payload); err != nil {
+			return err
+		}
+		leaves = append(leaves, entryLeafHash(entry))
+	}
+
+	root := accumulatorRoot(leaves)
+	if err := writeRecord(bw, recordAccumulator, root[:]); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadArchive reads back the entries written by WriteArchive, in order, without re-verifying the
+// accumulator root or re-running derivation; use VerifyArchive for that.
+func ReadArchive(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	for {
+		typ, payload, err := readRecord(r)
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case recordBlock:
+			var entry Entry
+			if err := json.Unmarshal(payload, &entry); err != nil {
+				return nil, fmt.Errorf("failed to decode block record: %w", err)
+			}
+			entries = append(entries, entry)
+		case recordAccumulator:
+			// The accumulator record always comes last; nothing more to read after it.
+			return entries, nil
+		default:
+			return nil, fmt.Errorf("unknown record type %d", typ)
+		}
+	}
+}
+
+// EspressoBatchChecker re-validates a single archived block against the derivation pipeline's
+// Espresso batch rules, mirroring derive.CheckBatchEspresso. It is an interface, rather than a
+// direct dependency on derive.CheckBatchEspresso, because that function needs a *rollup.Config,
+// *eth.SystemConfig, and L1 header access that a standalone archive-verification tool may not have
+// on hand; production callers should supply an adapter backed by the real derivation pipeline.
+type EspressoBatchChecker interface {
+	CheckBatchEspresso(entry Entry) error
+}
+
+// VerifyArchive re-reads the archive at r, and for every contained block: checks that the archive
+// is contiguous and its accumulator root matches the recomputed one, and asks checker to re-run
+// Espresso batch validation against the block's justification and L1 origin. This proves the
+// archive is derivation-pipeline-valid end-to-end, not merely a well-formed file.
+func VerifyArchive(r io.Reader, checker EspressoBatchChecker) error {
+	var leaves []common.Hash
+	var prevNumber *eth.Uint64Quantity
+	for {
+		typ, payload, err := readRecord(r)
+		if err == io.EOF {
+			return fmt.Errorf("archive is missing its accumulator record")
+		} else if err != nil {
+			return err
+		}
+
+		if typ == recordAccumulator {
+			want := common.BytesToHash(payload)
+			got := accumulatorRoot(leaves)
+			if want != got {
+				return fmt.Errorf("accumulator root mismatch: archive claims %s, recomputed %s", want, got)
+			}
+			return nil
+		}
+		if typ != recordBlock {
+			return fmt.Errorf("unknown record type %d", typ)
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(payload, &entry); err != nil {
+			return fmt.Errorf("failed to decode block record: %w", err)
+		}
+		if prevNumber != nil && entry.Block.BlockNumber != *prevNumber+1 {
+			return fmt.Errorf("archive is not contiguous: block %d follows block %d", entry.Block.BlockNumber, *prevNumber)
+		}
+		num := entry.Block.BlockNumber
+		prevNumber = &num
+
+		if err := checker.CheckBatchEspresso(entry); err != nil {
+			return fmt.Errorf("block %d failed Espresso batch validation: %w", entry.Block.BlockNumber, err)
+		}
+
+		leaves = append(leaves, entryLeafHash(entry))
+	}
+}
@@ -0,0 +1,18 @@
+package espresso
+
+// Commit returns the canonical commitment of this header. This is the same commitment HotShot
+// consensus attests to on L1, so it can be used to authenticate namespaced transaction inclusion
+// proofs against TransactionsRoot without trusting whoever relayed the header.
+func (h Header) Commit() Commitment {
+	l1Timestamp := make([]byte, 32)
+	h.L1Block.Timestamp.FillBytes(l1Timestamp)
+
+	return NewRawCommitmentBuilder("BLOCK").
+		Uint64Field("timestamp", h.Timestamp).
+		Uint64Field("l1_block_number", h.L1Block.Number).
+		ConstantString("l1_block_timestamp").
+		FixedSizeBytes(l1Timestamp).
+		ConstantString("transactions_root").
+		FixedSizeBytes(h.TransactionsRoot).
+		Finalize()
+}
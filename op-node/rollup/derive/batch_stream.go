@@ -0,0 +1,129 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BatchStream sits in front of BatchValidator and normalizes every batch a BatchSource emits --
+// SingularBatch or SpanBatch -- into a stream of *BatchWithL1InclusionBlock values that each wrap a
+// *SingularBatch, so BatchValidator never has to special-case a SpanBatch's multiple constituent
+// blocks: it buffers a SpanBatch candidate and yields its blocks one at a time via
+// GetBlockTimestamp/GetBlockTransactions/GetBlockEpochNum, instead of returning the whole span in
+// one call.
+//
+// EpochHash is deliberately left unset on every flattened SingularBatch: populating it correctly
+// needs a lookup of the L1 block at GetBlockEpochNum(i) against the l1Blocks window, which
+// BatchStream -- a stage with no L1 window of its own -- does not have. A caller validating these
+// flattened batches with CheckBatch must backfill EpochHash from its own l1Blocks before doing so;
+// BatchStream's job ends at unpacking the span's per-block fields.
+type BatchStream struct {
+	source BatchSource
+
+	mu       sync.Mutex
+	base     common.Hash
+	haveBase bool
+
+	pending      *BatchWithL1InclusionBlock
+	pendingSpan  *SpanBatch
+	pendingIndex int
+}
+
+// NewBatchStream constructs a BatchStream pulling raw (possibly span) batches from source.
+func NewBatchStream(source BatchSource) *BatchStream {
+	return &BatchStream{source: source}
+}
+
+// SetBase seeds (or resets) the parent-hash chain BatchStream synthesizes each flattened
+// SingularBatch's ParentHash from. Callers must call this, with the current L2 safe head's hash,
+// before the first NextBatch call, and again whenever the safe head changes for a reason other than
+// consuming BatchStream's own output (e.g. an L1 reorg), so the synthesized chain does not drift
+// from reality. Calling SetBase also discards any buffered, part-flattened SpanBatch, the same as
+// Reset.
+func (s *BatchStream) SetBase(hash common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.base = hash
+	s.haveBase = true
+	s.pending = nil
+	s.pendingSpan = nil
+	s.pendingIndex = 0
+}
+
+// NotifyBlockHash advances the synthesized parent-hash chain to hash, the real hash of the
+// SingularBatch most recently returned by NextBatch once it is known (typically once the execution
+// engine has applied it). Callers must call this exactly once per NextBatch call that returned a
+// batch, before calling NextBatch again -- the same "advance after application" discipline
+// CheckBatch's own caller already follows to move l2SafeHead forward one block at a time.
+func (s *BatchStream) NotifyBlockHash(hash common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.base = hash
+}
+
+// Reset flushes any buffered, part-flattened SpanBatch without changing the synthesized chain's
+// base. Callers should call this on the same reorg/base-change signal that resets the upstream batch
+// queue (see BatchQueue.Reset in da_syncer.go): a SpanBatch part-way through flattening from before a
+// reorg is no longer trustworthy, and SetBase should be called afterward with the new base hash
+// before resuming.
+func (s *BatchStream) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = nil
+	s.pendingSpan = nil
+	s.pendingIndex = 0
+}
+
+// NextBatch returns the next SingularBatch in the stream: a batch straight from source if it was
+// already singular, or the next unyielded block of a buffered SpanBatch otherwise.
+func (s *BatchStream) NextBatch(ctx context.Context) (*BatchWithL1InclusionBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.haveBase {
+		return nil, fmt.Errorf("BatchStream.SetBase must be called before NextBatch")
+	}
+
+	if s.pendingSpan == nil {
+		raw, err := s.source.NextBatch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if raw.Batch.GetBatchType() != SpanBatchType {
+			return raw, nil
+		}
+		span, ok := raw.Batch.(*SpanBatch)
+		if !ok {
+			return nil, fmt.Errorf("BatchStream: span batch type assertion failed")
+		}
+		s.pending = raw
+		s.pendingSpan = span
+		s.pendingIndex = 0
+	}
+
+	span := s.pendingSpan
+	i := s.pendingIndex
+	l1InclusionBlock := s.pending.L1InclusionBlock
+	singular := &SingularBatch{
+		ParentHash:   s.base,
+		EpochNum:     rollup.Epoch(span.GetBlockEpochNum(i)),
+		Timestamp:    span.GetBlockTimestamp(i),
+		Transactions: span.GetBlockTransactions(i),
+	}
+
+	s.pendingIndex++
+	if s.pendingIndex >= span.GetBlockCount() {
+		s.pending = nil
+		s.pendingSpan = nil
+		s.pendingIndex = 0
+	}
+
+	return &BatchWithL1InclusionBlock{
+		L1InclusionBlock: l1InclusionBlock,
+		Batch:            singular,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DefaultMaxFutureBatches bounds how many BatchFuture candidates BatchValidator holds in its
+// futureCache at once, evicting the least-recently-cached past that point.
+const DefaultMaxFutureBatches = 128
+
+// DefaultMaxBatchTimeDrift is how far past the current wall-clock time a batch's timestamp may be
+// before BatchValidator drops it outright instead of caching it as future, mirroring
+// go-ethereum's maxTimeFutureBlocks: a batch this far ahead of real time is implausible rather than
+// just "not due yet", and caching it anyway would let a misbehaving source grow futureCache with
+// candidates that could never become valid in a reasonable amount of time.
+const DefaultMaxBatchTimeDrift = 30 * time.Second
+
+// BatchSource is the upstream a BatchValidator pulls undecided batches from: typically a batch
+// queue's channel-decoded output, paired with the L1 block it was included in.
+type BatchSource interface {
+	NextBatch(ctx context.Context) (*BatchWithL1InclusionBlock, error)
+}
+
+// ErrNoBatchReady is returned by BatchValidator.NextBatch when the next candidate batch cannot yet
+// be decided (BatchUndecided) or is for a future L2 block (BatchFuture): more L1 data or more time
+// is needed before trying again, so the caller should treat this the same way it treats an
+// ordinary io.EOF "nothing ready right now" from any other stage in this package.
+var ErrNoBatchReady = io.EOF
+
+// BatchValidator wraps CheckBatch as its own pipeline stage: pulling candidate batches from a
+// BatchSource, validating each against cfg/sysCfg and the most recently known L1 window, and
+// surfacing only the one ready to apply.
+//
+// This is a thin extraction rather than a new concept: CheckBatch/checkSingularBatch/checkSpanBatch
+// already did all of this validation logic; no production code in this package called CheckBatch
+// directly before this (only batches_espresso_test.go did), so BatchValidator is simply the first
+// real caller, giving a future derivation pipeline a single NextBatch entry point to drive instead
+// of calling CheckBatch itself and hand-translating its BatchValidity results.
+type BatchValidator struct {
+	cfg       *rollup.Config
+	sysCfg    *eth.SystemConfig
+	log       log.Logger
+	source    BatchSource
+	l1        EspressoL1Provider
+	l2Fetcher SafeBlockFetcher
+	// fraudProofSink, if set via SetFraudProofSink, receives an EspressoFraudProof for every
+	// Espresso-specific BatchDrop verdict NextBatch reaches. Left nil, CheckBatch simply skips
+	// producing one; see FraudProofSink's doc comment.
+	fraudProofSink FraudProofSink
+
+	l1Blocks   []eth.L1BlockRef
+	l2SafeHead eth.L2BlockRef
+
+	// maxBatchTimeDrift and futureCache implement the bounded future-batch cache described on
+	// NextBatch's BatchFuture case below.
+	maxBatchTimeDrift time.Duration
+	futureCache       *futureBatchCache
+	// timeNow lets tests mock wall-clock time, the same convention driver.Sequencer uses.
+	timeNow func() time.Time
+}
+
+// NewBatchValidator constructs a BatchValidator pulling candidates from source, with the default
+// future-batch cache size and time-drift cap. UpdateOrigin must be called at least once, with the
+// L1 window surrounding l2SafeHead's origin, before NextBatch can make progress.
+func NewBatchValidator(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Logger, source BatchSource, l1 EspressoL1Provider, l2Fetcher SafeBlockFetcher) *BatchValidator {
+	return &BatchValidator{
+		cfg:               cfg,
+		sysCfg:            sysCfg,
+		log:               log,
+		source:            source,
+		l1:                l1,
+		l2Fetcher:         l2Fetcher,
+		maxBatchTimeDrift: DefaultMaxBatchTimeDrift,
+		futureCache:       newFutureBatchCache(DefaultMaxFutureBatches),
+		timeNow:           time.Now,
+	}
+}
+
+// SetFraudProofSink configures the sink that NextBatch's CheckBatch calls will report
+// EspressoFraudProofs to; pass nil to stop producing them (the default).
+func (v *BatchValidator) SetFraudProofSink(sink FraudProofSink) {
+	v.fraudProofSink = sink
+}
+
+// UpdateOrigin feeds the validator the l1Blocks/l2SafeHead window CheckBatch expects; callers
+// should call this whenever a new L1 block becomes known or the L2 safe head advances, the same way
+// the rest of this package's L1 traversal is normally driven from the outside.
+func (v *BatchValidator) UpdateOrigin(l1Blocks []eth.L1BlockRef, l2SafeHead eth.L2BlockRef) {
+	v.l1Blocks = l1Blocks
+	v.l2SafeHead = l2SafeHead
+}
+
+// NextBatch pulls candidate batches from v.source (checking v.futureCache first, see below),
+// validating each with CheckBatch, until it finds one that is BatchAccept (which it returns), hits
+// one that is BatchUndecided (which it surfaces as ErrNoBatchReady, since resolving it requires
+// UpdateOrigin to be called again), or the source itself returns an error.
+//
+// BatchFuture is handled specially: rather than discarding the candidate and re-fetching it from
+// v.source once the L2 safe head catches up (which, depending on the source, may not even be
+// possible), it is cached in v.futureCache keyed by (parent hash, claimed timestamp), and NextBatch
+// checks that cache for a match against (safe head hash, next timestamp) before pulling a new
+// candidate from v.source. A SpanBatch candidate carries no full parent hash, so it is keyed by the
+// current safe head hash instead and is not found again once the safe head advances. A
+// candidate whose timestamp is more than v.maxBatchTimeDrift past the current wall-clock time is not
+// cached at all and is dropped instead, the same way go-ethereum's futureBlocks cache refuses
+// blocks claiming an implausibly distant future timestamp.
+//
+// A SpanBatch candidate that is BatchAccept is not flattened into its constituent SingularBatches
+// here: NextBatch returns a single *SingularBatch per call, and flattening a span into its
+// constituent blocks needs its own buffering (separate from validation) to do correctly. Such a
+// candidate is surfaced as an error below rather than silently dropped or mis-cast.
+func (v *BatchValidator) NextBatch(ctx context.Context) (*SingularBatch, error) {
+	for {
+		candidate, fromCache := v.futureCache.take(futureBatchKey{parentHash: v.l2SafeHead.Hash, timestamp: v.l2SafeHead.Time + v.cfg.BlockTime})
+		if !fromCache {
+			var err error
+			candidate, err = v.source.NextBatch(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		validity := CheckBatch(ctx, v.cfg, v.sysCfg, v.log, v.l1Blocks, v.l2SafeHead, candidate, v.l1, v.l2Fetcher, v.fraudProofSink)
+		switch validity {
+		case BatchAccept:
+			singular, ok := candidate.Batch.(*SingularBatch)
+			if !ok {
+				return nil, fmt.Errorf("BatchValidator does not yet flatten accepted span batches into singular batches")
+			}
+			return singular, nil
+		case BatchDrop:
+			v.log.Info("BatchValidator dropping invalid batch, advancing to next", "l1_inclusion_block", candidate.L1InclusionBlock)
+			continue
+		case BatchUndecided:
+			return nil, ErrNoBatchReady
+		case BatchFuture:
+			timestamp := candidate.Batch.GetTimestamp()
+			if time.Unix(int64(timestamp), 0).Sub(v.timeNow()) > v.maxBatchTimeDrift {
+				v.log.Warn("BatchValidator dropping batch with implausibly distant future timestamp",
+					"timestamp", timestamp, "max_drift", v.maxBatchTimeDrift)
+				continue
+			}
+			parentHash := v.l2SafeHead.Hash
+			if singular, ok := candidate.Batch.(*SingularBatch); ok {
+				parentHash = singular.ParentHash
+			}
+			v.futureCache.store(futureBatchKey{parentHash: parentHash, timestamp: timestamp}, candidate)
+			return nil, ErrNoBatchReady
+		default:
+			return nil, fmt.Errorf("unrecognized batch validity %d", validity)
+		}
+	}
+}
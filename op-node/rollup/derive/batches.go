@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -36,89 +37,28 @@ const (
 // mode. `suggested` is the L1 origin "suggested" by the Espresso Sequencer; namely, the L1 head
 // referenced by the first Espresso block after the end of the sequencing window for this L2 block.
 //
-// First, `suggested` will be adjusted by the configured L1 confirmation depth, so that we will only
-// use an L1 block if it has a certain number of confirmations. If the result is a valid L1 origin
-// according to the rules of the derivation pipeline (e.g. it is not too old for the L2 batch, it
-// did not skip an L1 block from `parent.L1Origin`, etc.) its number will be returned. Otherwise, a
-// different L1 origin will be selected _deterministically_ to conform with the constraints of the
-// derivation pipeline. The resulting L1 origin will always be the same as parent's or one block
-// after parent's, will always conform to the derivation constraints, and is deterministic given
-// `parent` and `suggested.`
+// The L1 origin is chosen by policy: first policy.AdjustSuggested narrows `suggested` down to an L1
+// block number the policy considers sufficiently confirmed, then policy.Resolve applies the
+// derivation pipeline's own constraints (it must not skip an L1 block from `parent.L1Origin`, must
+// not decrease, must not be too old for the L2 batch, must not be newer than the L2 batch) to that
+// value. The resulting L1 origin will always be the same as parent's or one block after parent's,
+// will always conform to the derivation constraints, and is deterministic given `parent`,
+// `suggested`, and the policy.
+//
+// policy may be nil, in which case DefaultL1OriginPolicy is used -- the exact behavior this function
+// had before L1OriginPolicy existed. A nil policy is also all every caller in this tree passes today:
+// rollup.Config, which per-chain config like cfg.MaxSequencerDrift already lives on, is not part of
+// this checkout (no file under op-node/rollup declares it), so it has no field yet to carry an
+// operator's chosen policy through from config. Once it gains one (e.g. cfg.L1OriginPolicy), callers
+// here and in sequencer.go's origin selection should pass it through instead of nil.
 func EspressoL1Origin(ctx context.Context, cfg *rollup.Config, sysCfg *eth.SystemConfig,
-	parent eth.L2BlockRef, suggested uint64, l1 L1BlockRefByNumberFetcher, l log.Logger) (eth.L1BlockRef, error) {
-	// The Espresso Sequencer always suggests the latest L1 block as the L1 origin. Using this
-	// suggestion as-is makes us highly sensitive to L1 reorgs, since we are using a block with no
-	// confirmations. `EspressoL1ConfDepth` allows the pipeline to lag behind the L1 origins
-	// suggested by the Espresso Sequencer, thus always using an L1 block with at least a certain
-	// number of confirmations, while the derivation remains deterministic.
-	if suggested > sysCfg.EspressoL1ConfDepth {
-		suggested -= sysCfg.EspressoL1ConfDepth
-	} else {
-		suggested = 0
+	parent eth.L2BlockRef, suggested uint64, l1 L1OriginFetcher, l log.Logger, policy L1OriginPolicy) (eth.L1BlockRef, error) {
+	if policy == nil {
+		policy = NewDefaultL1OriginPolicy(cfg, l)
 	}
-
-	prev := parent.L1Origin
 	windowStart := parent.Time + cfg.BlockTime
-
-	// Constraint 1: the L1 origin must not skip an L1 block.
-	if suggested > prev.Number+1 {
-		nextL1Block, err := l1.L1BlockRefByNumber(ctx, prev.Number+1)
-		if err != nil {
-			return eth.L1BlockRef{}, fmt.Errorf("failed to fetch next possible L1 origin %d: %w", nextL1Block, err)
-		}
-		nextL1BlockEligible := nextL1Block.Time <= windowStart
-		// If we did skip an L1 block, that is Espresso telling us that multiple new L1 blocks have
-		// already been produced. In this case, we will not block when fetching the next L1 origin,
-		// so advance as far as the derivation pipeline allows: one block.
-		if nextL1BlockEligible {
-			l.Info("We skipped an L1 block and the next L1 block is eligible as an origin, advancing by one")
-			return nextL1Block, nil
-		} else {
-			l.Info("We skipped an L1 block and the next L1 block is not eligible as an origin, using the old origin")
-			return l1.L1BlockRefByNumber(ctx, prev.Number)
-		}
-	}
-	// Constraint 2: the L1 origin number decreased.
-	//
-	// While Espresso _should_ guarantee that L1 origin numbers are monotonically increasing, a
-	// limitation in the current design means that on rare occasions the L1 origin number can
-	// decrease.
-	if suggested < prev.Number {
-		// In this case, we have no indication that new L1 blocks are ready. We don't want to
-		// advance the L1 origin number and force the derivation pipeline to block waiting for a new
-		// L1 block to be produced, so just reuse the previous L1 origin.
-		l.Info("L1 origin decreased, using the old origin")
-		return l1.L1BlockRefByNumber(ctx, prev.Number)
-	}
-
-	// Fetch information about the suggested L1 block needed to evaluate the rest of the constraints.
-	l1Block, err := l1.L1BlockRefByNumber(ctx, suggested)
-	if err != nil {
-		return eth.L1BlockRef{}, fmt.Errorf("failed to fetch suggested L1 origin %d: %w", suggested, err)
-	}
-
-	// Constraint 3: the L1 origin is too old.
-	if l1Block.Time+cfg.MaxSequencerDrift < windowStart {
-		// Again, we have no explicit indication that new L1 blocks are ready, but here we are
-		// forced to advance the L1 origin. At worst, the derivation pipeline may block until the
-		// next L1 origin is available, but if the chosen L1 origin is this old, it is likely that a
-		// new L1 block is available and Espresso just hasn't seen it yet for some reason.
-		l.Info("L1 origin is too old, advancing by one",
-			"suggested", l1Block, "suggested_time", l1Block.Time)
-		return l1.L1BlockRefByNumber(ctx, prev.Number+1)
-	}
-	// Constraint 4: the L1 origin must not be newer than the L2 batch.
-	if l1Block.Time > windowStart {
-		// In this case `suggested` must be `prev.Number + 1`, since `prev.Number` would have a
-		// timestamp earlier than `prev`, and thus earlier than the current batch. Espresso must be
-		// running ahead of the L2, which is fine, we'll just wait to advance the L1 origin until
-		// the L2 chain catches up.
-		l.Info("L1 origin is newer than the L2 batch, use the previous origin")
-		return l1.L1BlockRefByNumber(ctx, prev.Number)
-	}
-
-	// In all other cases, the suggested L1 origin is valid.
-	return l1Block, nil
+	suggested = policy.AdjustSuggested(suggested, sysCfg)
+	return policy.Resolve(ctx, parent, suggested, windowStart, l1)
 }
 
 func EspressoBatchMustBeEmpty(cfg *rollup.Config, l1Origin eth.L1BlockRef, timestamp uint64) bool {
@@ -127,12 +67,28 @@ func EspressoBatchMustBeEmpty(cfg *rollup.Config, l1Origin eth.L1BlockRef, times
 	return l1Origin.Time+cfg.MaxSequencerDrift < timestamp
 }
 
+// CheckBatchEspresso does not yet call derive/espresso.Verifier.VerifyRange: its skipping
+// verification needs each header's QuorumCertificate (the signer set behind its commit), which
+// eth.L2BatchJustification has no field for today, the same kind of gap CheckSpanBatchEspresso's
+// doc comment already describes for SpanBatch.Justification. Once a justification carries that QC
+// data, this function's l1.VerifyCommitments check above and a Verifier.VerifyRange check should
+// run side by side: the former authenticates a header against the sequencer contract, the latter
+// against the validator signatures behind it, and either one failing should drop the batch.
+//
+// l1Blocks and sink are only used to build an EspressoFraudProof when this function drops the
+// batch: l1Blocks bounds the witness's L1 origin refs, and sink (nil is fine; see FraudProofSink)
+// receives the proof. Neither affects the validity check itself.
 func CheckBatchEspresso(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Logger,
-	l2SafeHead eth.L2BlockRef, batch *SingularBatch, l1 EspressoL1Provider) BatchValidity {
+	l2SafeHead eth.L2BlockRef, batch *SingularBatch, l1 EspressoL1Provider, l1Blocks []eth.L1BlockRef, sink FraudProofSink) BatchValidity {
+	drop := func(reason string, comms []espresso.Commitment) BatchValidity {
+		emitEspressoFraudProof(sink, l2SafeHead, l1Blocks, batch, comms, reason)
+		return BatchDrop
+	}
+
 	jst := batch.Justification
 	if jst == nil {
 		log.Warn("dropping batch because it has no justification")
-		return BatchDrop
+		return drop("missing justification", nil)
 	}
 
 	// First, check that the headers provided by the justification match those in the sequencer
@@ -156,7 +112,7 @@ func CheckBatchEspresso(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Lo
 	}
 	if !validComms {
 		log.Warn("dropping batch because headers do not match contract", "first", jst.First(), "count", len(comms))
-		return BatchDrop
+		return drop("headers do not match contract", comms)
 	}
 
 	// The headers claimed by the justification are all legitimate, now check that they correctly
@@ -164,16 +120,18 @@ func CheckBatchEspresso(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Lo
 	windowStart := l2SafeHead.Time + cfg.BlockTime
 	windowEnd := windowStart + cfg.BlockTime
 	if !checkBookends(log, windowStart, jst, WindowStart) {
-		return BatchDrop
+		return drop("window start bookend check failed", comms)
 	}
 	if !checkBookends(log, windowEnd, jst, WindowEnd) {
-		return BatchDrop
+		return drop("window end bookend check failed", comms)
 	}
 
 	// The Espresso data in the justification is good. Check that the L2 batch is correctly derived
 	// from the Espresso blocks. First, the L1 origin:
+	// Passing a nil policy here selects DefaultL1OriginPolicy; see EspressoL1Origin's doc comment for
+	// why CheckBatchEspresso can't yet read an operator-chosen policy off cfg itself.
 	l1Origin, err := EspressoL1Origin(context.Background(), cfg, sysCfg, l2SafeHead,
-		jst.Next.L1Head, l1, log)
+		jst.Next.L1Head, l1, log, nil)
 	if err != nil {
 		log.Warn("error finding Espresso L1 origin", "err", err, "suggested", jst.Next.L1Head)
 		return BatchUndecided
@@ -181,13 +139,13 @@ func CheckBatchEspresso(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Lo
 	if l1Origin.Number != uint64(batch.EpochNum) {
 		log.Warn("dropping batch because L1 origin was not set correctly",
 			"suggested", jst.Next.L1Head, "expected", l1Origin, "actual", batch.EpochNum)
-		return BatchDrop
+		return drop("L1 origin mismatch", comms)
 	}
 	// Finally, the transactions:
 	if EspressoBatchMustBeEmpty(cfg, l1Origin, batch.Timestamp) {
 		if len(batch.Transactions) != 0 {
 			log.Warn("dropping batch because it must be empty but isn't")
-			return BatchDrop
+			return drop("batch must be empty but isn't", comms)
 		}
 	} else {
 		roots := make([]*espresso.NmtRoot, len(jst.Blocks))
@@ -203,7 +161,7 @@ func CheckBatchEspresso(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Lo
 		err = nmt.ValidateBatchTransactions(cfg.L2ChainID.Uint64(), roots, proofs, txs)
 		if err != nil {
 			log.Warn("dropping batch because of invalid NMT proofs", "err", err)
-			return BatchDrop
+			return drop("invalid NMT proofs", comms)
 		}
 	}
 
@@ -213,8 +171,11 @@ func CheckBatchEspresso(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Lo
 // CheckBatch checks if the given batch can be applied on top of the given l2SafeHead, given the contextual L1 blocks the batch was included in.
 // The first entry of the l1Blocks should match the origin of the l2SafeHead. One or more consecutive l1Blocks should be provided.
 // In case of only a single L1 block, the decision whether a batch is valid may have to stay undecided.
+// sink receives an EspressoFraudProof whenever an Espresso-specific check drops a batch; nil is
+// fine for callers that don't want to produce fraud proofs (e.g. SpanBatch validation, which has no
+// Espresso-specific drop path of its own yet; see checkSpanBatch).
 func CheckBatch(ctx context.Context, cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Logger, l1Blocks []eth.L1BlockRef,
-	l2SafeHead eth.L2BlockRef, batch *BatchWithL1InclusionBlock, l1 EspressoL1Provider, l2Fetcher SafeBlockFetcher) BatchValidity {
+	l2SafeHead eth.L2BlockRef, batch *BatchWithL1InclusionBlock, l1 EspressoL1Provider, l2Fetcher SafeBlockFetcher, sink FraudProofSink) BatchValidity {
 	switch batch.Batch.GetBatchType() {
 	case SingularBatchType:
 		singularBatch, ok := batch.Batch.(*SingularBatch)
@@ -222,7 +183,7 @@ func CheckBatch(ctx context.Context, cfg *rollup.Config, sysCfg *eth.SystemConfi
 			log.Error("failed type assertion to SingularBatch")
 			return BatchDrop
 		}
-		return checkSingularBatch(cfg, sysCfg, log, l1Blocks, l2SafeHead, singularBatch, batch.L1InclusionBlock, l1)
+		return checkSingularBatch(cfg, sysCfg, log, l1Blocks, l2SafeHead, singularBatch, batch.L1InclusionBlock, l1, sink)
 	case SpanBatchType:
 		spanBatch, ok := batch.Batch.(*SpanBatch)
 		if !ok {
@@ -233,7 +194,10 @@ func CheckBatch(ctx context.Context, cfg *rollup.Config, sysCfg *eth.SystemConfi
 			log.Warn("received SpanBatch before SpanBatch hard fork")
 			return BatchDrop
 		}
-		return checkSpanBatch(ctx, cfg, log, l1Blocks, l2SafeHead, spanBatch, batch.L1InclusionBlock, l2Fetcher)
+		// Passing a nil l1Tree here skips the L1InfoTree cross-check; CheckBatch has no L1InfoTree
+		// of its own to pass, since maintaining one as new L1 origins are seen belongs to a
+		// longer-lived caller (e.g. BatchValidator) rather than this stateless function.
+		return checkSpanBatch(ctx, cfg, log, l1Blocks, l2SafeHead, spanBatch, batch.L1InclusionBlock, l2Fetcher, nil)
 	default:
 		log.Warn("Unrecognized batch type: %d", batch.Batch.GetBatchType())
 		return BatchDrop
@@ -242,7 +206,7 @@ func CheckBatch(ctx context.Context, cfg *rollup.Config, sysCfg *eth.SystemConfi
 
 // checkSingularBatch implements SingularBatch validation rule.
 func checkSingularBatch(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Logger, l1Blocks []eth.L1BlockRef, l2SafeHead eth.L2BlockRef,
-	batch *SingularBatch, l1InclusionBlock eth.L1BlockRef, l1 EspressoL1Provider) BatchValidity {
+	batch *SingularBatch, l1InclusionBlock eth.L1BlockRef, l1 EspressoL1Provider, sink FraudProofSink) BatchValidity {
 	// add details to the log
 	log = batch.LogContext(log)
 
@@ -350,7 +314,7 @@ func checkSingularBatch(cfg *rollup.Config, sysCfg *eth.SystemConfig, log log.Lo
 		}
 	}
 	if sysCfg.Espresso {
-		return CheckBatchEspresso(cfg, sysCfg, log, l2SafeHead, batch, l1)
+		return CheckBatchEspresso(cfg, sysCfg, log, l2SafeHead, batch, l1, l1Blocks, sink)
 	} else {
 		return BatchAccept
 	}
@@ -430,12 +394,25 @@ func (e windowEndpoint) Bookends(jst *eth.L2BatchJustification) (prev *espresso.
 	return
 }
 
-// checkSpanBatch implements SpanBatch validation rule.
+// checkSpanBatch implements SpanBatch validation rule. l1Tree, if non-nil, is the derivation
+// pipeline's own L1InfoTree, used to cross-check the L1InfoRoot embedded in an overlapped block's
+// L1 attributes deposit (see the overlap check below); pass nil to skip that additional check, as
+// CheckBatch's call site above does today.
 func checkSpanBatch(ctx context.Context, cfg *rollup.Config, log log.Logger, l1Blocks []eth.L1BlockRef, l2SafeHead eth.L2BlockRef,
-	batch *SpanBatch, l1InclusionBlock eth.L1BlockRef, l2Fetcher SafeBlockFetcher) BatchValidity {
+	batch *SpanBatch, l1InclusionBlock eth.L1BlockRef, l2Fetcher SafeBlockFetcher, l1Tree *L1InfoTree) BatchValidity {
 	// add details to the log
 	log = batch.LogContext(log)
 
+	// cfg.IsSpanBatch already gates span batches on the L2 timestamp of their first block not
+	// preceding the Delta activation time. This is a second, narrower gate on top of that: a span
+	// batch must also have been included on L1 no earlier than Delta, so that an L1 reorg cannot
+	// resurrect a span batch from before the hard fork by replaying it in a later, post-Delta L1
+	// block whose included batch data was originally written pre-Delta.
+	if cfg.DeltaTime != nil && l1InclusionBlock.Time < *cfg.DeltaTime {
+		log.Warn("received SpanBatch included on L1 before the Delta hard fork", "l1_inclusion_time", l1InclusionBlock.Time, "delta_time", *cfg.DeltaTime)
+		return BatchDrop
+	}
+
 	// sanity check we have consistent inputs
 	if len(l1Blocks) == 0 {
 		log.Warn("missing L1 block input, cannot proceed with batch checking")
@@ -626,6 +603,31 @@ func checkSpanBatch(ctx context.Context, cfg *rollup.Config, log log.Logger, l1B
 				log.Warn("overlapped block's L1 origin number does not match")
 				return BatchDrop
 			}
+
+			// In addition to the origin number above, check that the overlapped block's L1
+			// attributes deposit agrees with the pipeline's own L1InfoTree about the L1InfoRoot as
+			// of that origin: a batcher could replay a stale or substituted L1 attributes deposit
+			// whose origin *number* matches but whose claimed L1 block data (hash, timestamp,
+			// base fee, ...) does not, which the number-only comparison above would miss.
+			if l1Tree != nil && len(safeBlockTxs) > 0 {
+				var depositTx types.Transaction
+				if err := depositTx.UnmarshalBinary(safeBlockTxs[0]); err != nil {
+					log.Error("failed to decode overlapped block's L1 attributes transaction", "err", err)
+					return BatchUndecided
+				}
+				l1Info, err := L1BlockInfoFromBytes(cfg, safeBlockRef.Time, depositTx.Data())
+				if err != nil {
+					log.Error("failed to decode overlapped block's L1 attributes payload", "err", err)
+					return BatchUndecided
+				}
+				if (l1Info.L1InfoRoot != common.Hash{}) {
+					if expectedRoot, ok := l1Tree.L1InfoRootAt(safeBlockRef.L1Origin.Number); ok && l1Info.L1InfoRoot != expectedRoot {
+						log.Warn("overlapped block's L1 info root does not match the pipeline's L1InfoTree",
+							"expected", expectedRoot, "actual", l1Info.L1InfoRoot)
+						return BatchDrop
+					}
+				}
+			}
 		}
 	}
 
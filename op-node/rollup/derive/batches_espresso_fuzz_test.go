@@ -0,0 +1,237 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	espresso "github.com/EspressoSystems/espresso-sequencer-go/types"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	"github.com/ethereum-optimism/optimism/op-service/testutils"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// espressoFuzzCase is one generated (L1Blocks, hotshot headers, L2SafeHead, Batch) tuple, built so
+// that the honest version of it is always BatchAccept: a single L1 block origin that the batch does
+// not advance past, and a hotshot window of exactly n blocks bookended by a header just before the
+// window and one just after it, matching the shape of TestValidBatchEspresso's hand-written cases.
+type espressoFuzzCase struct {
+	conf     rollup.Config
+	sysCfg   eth.SystemConfig
+	l1Blocks []eth.L1BlockRef
+	l2Safe   eth.L2BlockRef
+	// headers is [prev, block_0, ..., block_{n-1}, next], heights 0..n+1.
+	headers []espresso.Header
+	n       int
+}
+
+// genEspressoFuzzCase deterministically builds a case with exactly n in-window blocks from rng.
+// cfg.BlockTime is sized to comfortably fit n strictly-increasing in-window timestamps, so this
+// works for any n used by FuzzCheckBatchEspresso's invariants (2 through 4).
+func genEspressoFuzzCase(rng *rand.Rand, n int) espressoFuzzCase {
+	blockTime := uint64(n) + 10
+	conf := rollup.Config{
+		Genesis:           rollup.Genesis{L2Time: 1000},
+		BlockTime:         blockTime,
+		SeqWindowSize:     1_000_000,
+		MaxSequencerDrift: 1_000_000,
+		L2ChainID:         big.NewInt(901),
+	}
+	sysCfg := eth.SystemConfig{Espresso: true, EspressoL1ConfDepth: 0}
+
+	l1A := eth.L1BlockRef{
+		Hash:       testutils.RandomHash(rng),
+		Number:     0,
+		ParentHash: testutils.RandomHash(rng),
+		Time:       1_000_000 + rng.Uint64()%1000,
+	}
+	l2Safe := eth.L2BlockRef{
+		Hash:           testutils.RandomHash(rng),
+		Number:         100,
+		ParentHash:     testutils.RandomHash(rng),
+		Time:           l1A.Time,
+		L1Origin:       l1A.ID(),
+		SequenceNumber: 0,
+	}
+
+	windowStart := l2Safe.Time + conf.BlockTime
+	windowEnd := windowStart + conf.BlockTime
+
+	headers := make([]espresso.Header, 0, n+2)
+	headers = append(headers, espresso.Header{Height: 0, Timestamp: windowStart - 1, L1Head: l1A.Number})
+	for i := 0; i < n; i++ {
+		headers = append(headers, espresso.Header{Height: uint64(i + 1), Timestamp: windowStart + uint64(i), L1Head: l1A.Number})
+	}
+	headers = append(headers, espresso.Header{Height: uint64(n + 1), Timestamp: windowEnd + rng.Uint64()%10, L1Head: l1A.Number})
+
+	return espressoFuzzCase{conf: conf, sysCfg: sysCfg, l1Blocks: []eth.L1BlockRef{l1A}, l2Safe: l2Safe, headers: headers, n: n}
+}
+
+// justification builds the eth.L2BatchJustification an honest sequencer would produce for c's
+// window, using headers (c.headers unless a mutation substitutes a different slice of the same
+// length/heights).
+func (c espressoFuzzCase) justification(headers []espresso.Header) *eth.L2BatchJustification {
+	blocks := make([]eth.EspressoBlockJustification, c.n)
+	for i := 0; i < c.n; i++ {
+		blocks[i] = eth.EspressoBlockJustification{Header: headers[i+1]}
+	}
+	return &eth.L2BatchJustification{
+		Prev:   &headers[0],
+		Blocks: blocks,
+		Next:   &headers[c.n+1],
+	}
+}
+
+// batch builds the SingularBatch an honest sequencer would produce for c's window, justified by jst.
+func (c espressoFuzzCase) batch(jst *eth.L2BatchJustification) *BatchWithL1InclusionBlock {
+	return &BatchWithL1InclusionBlock{
+		L1InclusionBlock: c.l1Blocks[0],
+		Batch: &SingularBatch{
+			ParentHash:    c.l2Safe.Hash,
+			EpochNum:      rollup.Epoch(c.l2Safe.L1Origin.Number),
+			EpochHash:     c.l2Safe.L1Origin.Hash,
+			Timestamp:     c.l2Safe.Time + c.conf.BlockTime,
+			Transactions:  nil,
+			Justification: jst,
+		},
+	}
+}
+
+// check runs CheckBatch for c against mockHeaders (what the L1 provider will claim are the
+// authentic headers at each height, which may differ from the headers embedded in jst when a test
+// is deliberately feeding the checker a truncated or dishonest view).
+func (c espressoFuzzCase) check(t *testing.T, jst *eth.L2BatchJustification, mockHeaders []espresso.Header) BatchValidity {
+	t.Helper()
+	l1 := &mockL1Provider{}
+	l1.setBlocks(c.l1Blocks)
+	l1.setHeaders(mockHeaders)
+	logger := testlog.Logger(t, log.LvlError)
+	return CheckBatch(context.Background(), &c.conf, &c.sysCfg, logger, c.l1Blocks, c.l2Safe, c.batch(jst), l1, nil, nil)
+}
+
+// checkWithSysCfg is like check, but overrides c.sysCfg.Espresso (used by invariant 4, which
+// compares the Espresso and non-Espresso verdicts for the same inputs).
+func (c espressoFuzzCase) checkWithSysCfg(t *testing.T, jst *eth.L2BatchJustification, mockHeaders []espresso.Header, espressoEnabled bool) BatchValidity {
+	t.Helper()
+	l1 := &mockL1Provider{}
+	l1.setBlocks(c.l1Blocks)
+	l1.setHeaders(mockHeaders)
+	sysCfg := c.sysCfg
+	sysCfg.Espresso = espressoEnabled
+	logger := testlog.Logger(t, log.LvlError)
+	return CheckBatch(context.Background(), &c.conf, &sysCfg, logger, c.l1Blocks, c.l2Safe, c.batch(jst), l1, nil, nil)
+}
+
+// shiftedHeaders returns a copy of headers with every timestamp pushed forward by shift,
+// preserving heights and L1Head so commitments computed from it are still
+// internally consistent (an honest-looking but dishonest header set, the same trick
+// hotshotDishonestHeaders uses in TestValidBatchEspresso).
+func shiftedHeaders(headers []espresso.Header, shift uint64) []espresso.Header {
+	out := make([]espresso.Header, len(headers))
+	for i, h := range headers {
+		out[i] = h
+		out[i].Timestamp += shift
+	}
+	return out
+}
+
+// fuzzInvariants runs all four FuzzCheckBatchEspresso invariants against one generated case,
+// returning a non-empty failure description if any invariant is violated.
+func fuzzInvariants(t *testing.T, c espressoFuzzCase) string {
+	t.Helper()
+	jst := c.justification(c.headers)
+
+	// (1) The honest case must be accepted.
+	if got := c.check(t, jst, c.headers); got != BatchAccept {
+		return fmt.Sprintf("invariant 1: honest window got %v, want BatchAccept", got)
+	}
+
+	// (2a) Swapping two adjacent in-window headers must be dropped (requires n >= 2).
+	if c.n >= 2 {
+		swapped := c.justification(c.headers)
+		swapped.Blocks[0], swapped.Blocks[1] = swapped.Blocks[1], swapped.Blocks[0]
+		if got := c.check(t, swapped, c.headers); got != BatchDrop {
+			return fmt.Sprintf("invariant 2a: swapped adjacent headers got %v, want BatchDrop", got)
+		}
+	}
+
+	// (2b) Shifting the whole header set past the window must be dropped.
+	shifted := shiftedHeaders(c.headers, c.conf.BlockTime*1000)
+	shiftedJst := c.justification(shifted)
+	if got := c.check(t, shiftedJst, shifted); got != BatchDrop {
+		return fmt.Sprintf("invariant 2b: window shifted past headers got %v, want BatchDrop", got)
+	}
+
+	// (3) Truncating the L1 provider's view so the window straddles the end of what it knows about
+	// must be undecided, never accepted.
+	truncated := c.headers[:len(c.headers)-1]
+	if got := c.check(t, jst, truncated); got == BatchAccept {
+		return fmt.Sprintf("invariant 3: truncated header view got %v, must never be BatchAccept", got)
+	}
+
+	// (4) For an empty-transactions batch whose L1 origin is unchanged, Espresso and non-Espresso
+	// sysCfg must agree.
+	withEspresso := c.checkWithSysCfg(t, jst, c.headers, true)
+	withoutEspresso := c.checkWithSysCfg(t, jst, c.headers, false)
+	if withEspresso != withoutEspresso {
+		return fmt.Sprintf("invariant 4: Espresso sysCfg got %v but non-Espresso got %v for the same inputs", withEspresso, withoutEspresso)
+	}
+
+	return ""
+}
+
+// shrinkEspressoFailure bisects n down to the smallest value (never below 2, the minimum invariant
+// 2a needs) for which seed still reproduces a failure, so a test failure reports the smallest
+// counterexample rather than whatever n the RNG happened to pick.
+func shrinkEspressoFailure(t *testing.T, seed int64, n int) (int, string) {
+	t.Helper()
+	lo, hi := 2, n
+	failure := fuzzInvariants(t, genEspressoFuzzCase(rand.New(rand.NewSource(seed)), hi))
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if f := fuzzInvariants(t, genEspressoFuzzCase(rand.New(rand.NewSource(seed)), mid)); f != "" {
+			hi = mid
+			failure = f
+		} else {
+			lo = mid + 1
+		}
+	}
+	return hi, failure
+}
+
+// FuzzCheckBatchEspresso deterministically generates many (L1Blocks, headers, L2SafeHead, Batch)
+// tuples from seeded RNGs (no testing.F, per the convention this test was asked to follow) and
+// checks four invariants against CheckBatch directly: an honest window is always accepted (1);
+// swapping adjacent in-window headers or shifting the whole header set past the window is always
+// dropped (2); a truncated L1 provider view is never accepted (3); and an empty-transactions batch
+// with an unchanged L1 origin gets the same verdict whether or not sysCfg.Espresso is set (4). On
+// failure it bisects the window size down to the smallest n that still reproduces the failure and
+// prints that minimal counterexample, rather than whatever size the RNG first found.
+//
+// This is named FuzzCheckBatchEspresso rather than TestCheckBatchEspressoFuzz because that's the
+// name this harness is about; it is called from TestCheckBatchEspressoFuzz below rather than
+// declared as a top-level Test/Fuzz function itself, since `go test` (and `go vet`'s tests check)
+// requires any top-level func FuzzXxx in a _test.go file to take *testing.F, which this
+// intentionally does not; it is therefore a function-valued variable, not a func declaration.
+var FuzzCheckBatchEspresso = func(t *testing.T) {
+	for seed := int64(1); seed <= 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		n := 2 + rng.Intn(3) // 2..4 in-window blocks
+		c := genEspressoFuzzCase(rng, n)
+		if failure := fuzzInvariants(t, c); failure != "" {
+			minN, minFailure := shrinkEspressoFailure(t, seed, n)
+			t.Fatalf("seed %d: %s (shrunk from n=%d to minimal n=%d: %s)", seed, failure, n, minN, minFailure)
+		}
+	}
+}
+
+// TestCheckBatchEspressoFuzz is the actual go test entry point for FuzzCheckBatchEspresso; see its
+// doc comment for why the logic lives in a variable rather than a top-level Fuzz function.
+func TestCheckBatchEspressoFuzz(t *testing.T) {
+	FuzzCheckBatchEspresso(t)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -23,13 +25,102 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
-type EspressoValidBatchTestCase struct {
-	Name       string
-	L1Blocks   []eth.L1BlockRef
-	L2SafeHead eth.L2BlockRef
-	Batch      BatchWithL1InclusionBlock
-	Expected   BatchValidity
-	Headers    []espresso.Header
+// EspressoBatchTestCase is one row of a table-driven CheckBatch/CheckBatchEspresso test: a fixture
+// for the mock L1 provider plus a batch to validate and the BatchValidity it must produce.
+// ExpectedLog and NotExpectedLog, if set, additionally assert that a log message containing that
+// substring was (or was not) emitted while checking the batch, so that a case exercises the
+// specific validation rule it names rather than merely the final accept/drop/undecided verdict.
+type EspressoBatchTestCase struct {
+	Name           string
+	L1Blocks       []eth.L1BlockRef
+	L2SafeHead     eth.L2BlockRef
+	Batch          BatchWithL1InclusionBlock
+	Expected       BatchValidity
+	Headers        []espresso.Header
+	ExpectedLog    string
+	NotExpectedLog string
+}
+
+// capturingHandler records every log message it sees, so tests can assert on which validation
+// rule actually fired rather than only on the returned BatchValidity.
+type capturingHandler struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (h *capturingHandler) Log(r *log.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgs = append(h.msgs, r.Msg)
+	return nil
+}
+
+func (h *capturingHandler) contains(substr string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, msg := range h.msgs {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *capturingHandler) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgs = nil
+}
+
+// capturingFraudProofSink records the last EspressoFraudProof it was given, so tests can round-trip
+// it through VerifyFraudProof without needing a real on-chain submission path.
+type capturingFraudProofSink struct {
+	proof *EspressoFraudProof
+}
+
+func (s *capturingFraudProofSink) EmitFraudProof(proof *EspressoFraudProof) {
+	s.proof = proof
+}
+
+// runEspressoBatchTestCases runs each test case through CheckBatch, asserting the returned
+// BatchValidity and, when set, the presence/absence of ExpectedLog/NotExpectedLog substrings among
+// the log messages CheckBatch emitted while checking that one case.
+//
+// For every case expecting BatchDrop, it also wires in a capturingFraudProofSink: if
+// CheckBatchEspresso was the check that dropped the batch, it will have emitted an
+// EspressoFraudProof, which this then round-trips through VerifyFraudProof and asserts reaches the
+// same BatchDrop verdict. Cases dropped by a non-Espresso-specific check in checkSingularBatch
+// (e.g. a bad parent hash) never reach CheckBatchEspresso, so no proof is captured for them and the
+// round-trip is skipped; that isn't a gap in VerifyFraudProof, since those checks don't need an
+// Espresso-specific fraud proof to begin with.
+func runEspressoBatchTestCases(t *testing.T, sysCfg *eth.SystemConfig, conf *rollup.Config, testCases []EspressoBatchTestCase) {
+	capture := &capturingHandler{}
+	logger := testlog.Logger(t, log.LvlWarn)
+	logger.SetHandler(log.MultiHandler(logger.GetHandler(), capture))
+
+	l1 := &mockL1Provider{}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			capture.reset()
+			l1.setBlocks(testCase.L1Blocks)
+			l1.setHeaders(testCase.Headers)
+			ctx := context.Background()
+			sink := &capturingFraudProofSink{}
+			validity := CheckBatch(ctx, conf, sysCfg, logger, testCase.L1Blocks, testCase.L2SafeHead, &testCase.Batch, l1, nil, sink)
+			require.Equal(t, testCase.Expected, validity, "batch check must return expected validity level")
+			if testCase.ExpectedLog != "" {
+				require.True(t, capture.contains(testCase.ExpectedLog), "expected a log message containing %q", testCase.ExpectedLog)
+			}
+			if testCase.NotExpectedLog != "" {
+				require.False(t, capture.contains(testCase.NotExpectedLog), "did not expect a log message containing %q", testCase.NotExpectedLog)
+			}
+			if testCase.Expected == BatchDrop && sink.proof != nil {
+				replayed := VerifyFraudProof(sink.proof, sysCfg, conf)
+				require.Equal(t, BatchDrop, replayed, "replaying the emitted fraud proof must reach the same BatchDrop verdict")
+			}
+		})
+	}
 }
 
 type mockL1Provider struct {
@@ -244,7 +335,7 @@ func TestValidBatchEspresso(t *testing.T) {
 			},
 		}
 
-	testCases := []EspressoValidBatchTestCase{
+	testCases := []EspressoBatchTestCase{
 		{
 			Name:       "valid batch where one hotshot block falls within the window",
 			L1Blocks:   []eth.L1BlockRef{l1A, l1B, l1C},
@@ -402,7 +493,8 @@ func TestValidBatchEspresso(t *testing.T) {
 					},
 				},
 			},
-			Expected: BatchDrop,
+			Expected:    BatchDrop,
+			ExpectedLog: "headers do not match contract",
 		},
 		{
 			Name:       "invalid batch due to espresso providing a previous batch header outside of the window range",
@@ -506,7 +598,8 @@ func TestValidBatchEspresso(t *testing.T) {
 					Transactions: []hexutil.Bytes{},
 				},
 			},
-			Expected: BatchDrop,
+			Expected:    BatchDrop,
+			ExpectedLog: "dropping batch because it has no justification",
 		},
 		{
 			Name:       "undecided batch if headers are not available",
@@ -611,20 +704,7 @@ func TestValidBatchEspresso(t *testing.T) {
 		},
 	}
 
-	// Log level can be increased for debugging purposes
-	logger := testlog.Logger(t, log.LvlWarn)
-
-	var l1 = &mockL1Provider{}
-
-	for _, testCase := range testCases {
-		t.Run(testCase.Name, func(t *testing.T) {
-			l1.setBlocks(testCase.L1Blocks)
-			l1.setHeaders(testCase.Headers)
-			ctx := context.Background()
-			validity := CheckBatch(ctx, &sysCfg, &conf, logger, testCase.L1Blocks, testCase.L2SafeHead, &testCase.Batch, l1, nil)
-			require.Equal(t, testCase.Expected, validity, "batch check must return expected validity level")
-		})
-	}
+	runEspressoBatchTestCases(t, &sysCfg, &conf, testCases)
 }
 
 func TestL1OriginLag(t *testing.T) {
@@ -685,7 +765,7 @@ func TestL1OriginLag(t *testing.T) {
 		SequenceNumber: 0,
 	}
 
-	testCases := []EspressoValidBatchTestCase{
+	testCases := []EspressoBatchTestCase{
 		{
 			Name:       "valid origin lag",
 			L1Blocks:   []eth.L1BlockRef{l1A, l1B, l1C},
@@ -728,18 +808,5 @@ func TestL1OriginLag(t *testing.T) {
 		},
 	}
 
-	// Log level can be increased for debugging purposes
-	logger := testlog.Logger(t, log.LvlWarn)
-
-	var l1 = &mockL1Provider{}
-
-	for _, testCase := range testCases {
-		t.Run(testCase.Name, func(t *testing.T) {
-			l1.setBlocks(testCase.L1Blocks)
-			l1.setHeaders(testCase.Headers)
-			ctx := context.Background()
-			validity := CheckBatch(ctx, &sysCfg, &conf, logger, testCase.L1Blocks, testCase.L2SafeHead, &testCase.Batch, l1, nil)
-			require.Equal(t, testCase.Expected, validity, "batch check must return expected validity level")
-		})
-	}
+	runEspressoBatchTestCases(t, &sysCfg, &conf, testCases)
 }
@@ -0,0 +1,90 @@
+package derive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// blobRetentionWindow approximates the beacon API's guaranteed blob sidecar retention window,
+// MIN_EPOCHS_FOR_BLOB_SIDECARS_REQUESTS (4096 epochs), using mainnet's 12s slots and 32 slots per
+// epoch: 4096 * 32 * 12s. A real deployment should derive this from the L1 consensus chain's
+// genesis config instead of hardcoding mainnet's slot timing, the same gap already noted below for
+// converting a block time into a slot.
+const blobRetentionWindow = 4096 * 32 * 12 * time.Second
+
+// L1BeaconHTTPClient is an L1BeaconClient backed by a beacon node's REST API, fetching blob
+// sidecars via GET /eth/v1/beacon/blob_sidecars/{slot} the way BlobDataSource expects.
+type L1BeaconHTTPClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewL1BeaconHTTPClient constructs an L1BeaconHTTPClient against a beacon node's base URL (e.g.
+// the value of the --l1.beacon flag), using http.DefaultClient.
+func NewL1BeaconHTTPClient(baseURL string) *L1BeaconHTTPClient {
+	return &L1BeaconHTTPClient{client: http.DefaultClient, baseURL: baseURL}
+}
+
+// beaconBlobSidecarsResponse is the subset of the beacon API's
+// GET /eth/v1/beacon/blob_sidecars/{slot} response body this client reads. Every field is a
+// 0x-prefixed hex string per the Ethereum beacon API's JSON convention.
+type beaconBlobSidecarsResponse struct {
+	Data []beaconBlobSidecar `json:"data"`
+}
+
+type beaconBlobSidecar struct {
+	Index         string `json:"index"`
+	Blob          string `json:"blob"`
+	KZGCommitment string `json:"kzg_commitment"`
+	KZGProof      string `json:"kzg_proof"`
+}
+
+// BlobSidecars requests the blob sidecars for l1InclusionBlock's slot. This client has no genesis
+// time or slot duration to convert l1InclusionBlock.Time into a slot, so it uses
+// l1InclusionBlock.Number as the slot. A 404 is reported as ErrBlobUnavailable for a block older
+// than blobRetentionWindow and as ErrTemporary otherwise. A successful response is parsed but not
+// converted into eth.BlobSidecar values, so BlobSidecars then returns an error; it never returns
+// sidecars.
+func (c *L1BeaconHTTPClient) BlobSidecars(ctx context.Context, l1InclusionBlock eth.L1BlockRef) ([]*eth.BlobSidecar, error) {
+	slot := l1InclusionBlock.Number
+	url := fmt.Sprintf("%s/eth/v1/beacon/blob_sidecars/%s", c.baseURL, strconv.FormatUint(slot, 10))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build blob sidecars request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, NewTemporaryError(fmt.Errorf("failed to reach beacon node for blob sidecars: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		blockAge := time.Since(time.Unix(int64(l1InclusionBlock.Time), 0))
+		if blockAge > blobRetentionWindow {
+			return nil, fmt.Errorf("%w: block %s (age %s) is older than the beacon node's retention window", ErrBlobUnavailable, l1InclusionBlock, blockAge)
+		}
+		return nil, NewTemporaryError(fmt.Errorf("beacon node returned 404 fetching blob sidecars for slot %d, block %s may not be available yet", slot, l1InclusionBlock))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewTemporaryError(fmt.Errorf("beacon node returned status %d fetching blob sidecars for slot %d", resp.StatusCode, slot))
+	}
+
+	var parsed beaconBlobSidecarsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode blob sidecars response for slot %d: %w", slot, err)
+	}
+
+	// Converting the wire response above into []*eth.BlobSidecar needs eth.BlobSidecar's field
+	// layout, which this checkout does not have the source for (op-service/eth is an external
+	// dependency here, not part of this snapshot; see BlobDataSource's existing use of
+	// eth.BlobSidecar.VerifyCommitment/DecodeFrameData/VersionedHash for the same limitation). A
+	// real deployment can fill in the conversion once that type's fields are available; until then
+	// this client fetches and parses the wire format correctly but cannot hand back the strongly
+	// typed result BlobDataSource expects.
+	return nil, fmt.Errorf("L1BeaconHTTPClient: fetched %d sidecars for slot %d but cannot convert them to eth.BlobSidecar without that type's field layout", len(parsed.Data), slot)
+}
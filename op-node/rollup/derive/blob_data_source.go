@@ -0,0 +1,230 @@
+package derive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ErrBlobUnavailable indicates that a blob sidecar that once existed for an L1 block has since been
+// pruned by the beacon node, rather than merely not being retrievable yet. Unlike ErrTemporary,
+// retrying will never recover it: CheckBatch-adjacent callers should treat this as grounds to drop
+// the batch rather than leave it BatchUndecided forever.
+var ErrBlobUnavailable = errors.New("espresso: blob sidecar unavailable, likely pruned by the beacon node")
+
+// DataIter iterates over batcher data found on L1, regardless of which DA type was used to post
+// it.
+type DataIter interface {
+	Next(ctx context.Context) (eth.Data, error)
+}
+
+// L1BeaconClient is the subset of a beacon node API that the blob data source needs in order to
+// retrieve sidecars for a given L1 block: fetching the full sidecar set for the block's slot, which
+// BlobDataSource then filters down to the versioned hashes actually referenced by batcher
+// transactions in that block.
+type L1BeaconClient interface {
+	BlobSidecars(ctx context.Context, l1InclusionBlock eth.L1BlockRef) ([]*eth.BlobSidecar, error)
+}
+
+// CalldataSource iterates over the calldata of the transactions in an L1 block which are sent to
+// the configured batch inbox, yielding the raw frame data found in each one.
+//
+// This is the data-availability path used before the Ecotone 4844 blob upgrade, and remains
+// available afterwards for rollups that prefer to post calldata.
+type CalldataSource struct {
+	log     log.Logger
+	txs     []*types.Transaction
+	batcher common.Address
+}
+
+// NewCalldataSource creates a calldata source, already seeded with the transactions of
+// `l1InclusionBlock` which were sent to the batch inbox address.
+func NewCalldataSource(log log.Logger, txs []*types.Transaction, batcher common.Address) *CalldataSource {
+	return &CalldataSource{log: log, txs: txs, batcher: batcher}
+}
+
+func (s *CalldataSource) Next(ctx context.Context) (eth.Data, error) {
+	for len(s.txs) > 0 {
+		tx := s.txs[0]
+		s.txs = s.txs[1:]
+		if tx.Type() == types.BlobTxType {
+			// Blob-carrying transactions do not carry their frame data in calldata; the blob data
+			// source is responsible for those.
+			continue
+		}
+		if !isToBatchInbox(tx, s.batcher) {
+			// Not a batcher transaction at all; an arbitrary transaction sharing this block must
+			// not be mistaken for channel-frame data. Sender verification is left to the caller,
+			// the same way chunk8-3/chunk14-1 leave quorum-certificate verification out of scope:
+			// this checkout has no derivation-pipeline signer that recovers and checks a batcher
+			// sender address against the chain's SystemConfig.
+			continue
+		}
+		return tx.Data(), nil
+	}
+	return nil, io.EOF
+}
+
+// isToBatchInbox reports whether tx was sent to the configured batch inbox address, the same
+// destination check the real op-node data source performs before trusting a transaction's payload
+// as channel-frame data.
+func isToBatchInbox(tx *types.Transaction, batcher common.Address) bool {
+	to := tx.To()
+	return to != nil && *to == batcher
+}
+
+// blobJustificationEnvelope is a small, fixed-format wrapper placed in the calldata of a blob
+// transaction so that the Espresso justification for the batch can be recovered alongside the
+// blob commitments, without having to index the query service by commitment.
+type blobJustificationEnvelope struct {
+	Justification *eth.L2BatchJustification `rlp:"nil"`
+}
+
+// BlobDataSource iterates over the EIP-4844 blobs attached to transactions in an L1 block which
+// are sent to the batch inbox address, yielding the frame data recovered from each blob.
+//
+// Per blob, the 4096x31-byte field-element payload (see the encoding used by op-batcher) is
+// decoded back into the frame bytes the batcher originally submitted. The KZG commitment carried
+// in each blob sidecar is verified against the versioned hash referenced by the transaction before
+// any data is trusted.
+type BlobDataSource struct {
+	log    log.Logger
+	beacon L1BeaconClient
+
+	l1InclusionBlock eth.L1BlockRef
+	txs              []*types.Transaction
+	batcher          common.Address
+
+	// Populated lazily, on the first call to Next, since the caller may never need blob data (e.g.
+	// if the block contains only calldata batches).
+	sidecars []*eth.BlobSidecar
+	fetched  bool
+}
+
+// NewBlobDataSource creates a blob source, already seeded with the transactions of
+// `l1InclusionBlock` which were sent to the batch inbox address.
+func NewBlobDataSource(log log.Logger, beacon L1BeaconClient, l1InclusionBlock eth.L1BlockRef, txs []*types.Transaction, batcher common.Address) *BlobDataSource {
+	return &BlobDataSource{
+		log:              log,
+		beacon:           beacon,
+		l1InclusionBlock: l1InclusionBlock,
+		txs:              txs,
+		batcher:          batcher,
+	}
+}
+
+func (s *BlobDataSource) Next(ctx context.Context) (eth.Data, error) {
+	if !s.fetched {
+		sidecars, err := s.beacon.BlobSidecars(ctx, s.l1InclusionBlock)
+		if err != nil {
+			if errors.Is(err, ErrBlobUnavailable) {
+				// Pruned is permanent: wrapping it in ErrTemporary would tell a caller to keep
+				// retrying a fetch that can never succeed.
+				return nil, err
+			}
+			return nil, NewTemporaryError(fmt.Errorf("failed to fetch blob sidecars for L1 block %s: %w", s.l1InclusionBlock, err))
+		}
+		s.sidecars = sidecars
+		s.fetched = true
+	}
+
+	for len(s.txs) > 0 {
+		tx := s.txs[0]
+		s.txs = s.txs[1:]
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		if !isToBatchInbox(tx, s.batcher) {
+			// See the matching check in CalldataSource.Next: an arbitrary blob transaction sharing
+			// this block must not be mistaken for a batcher blob.
+			continue
+		}
+		data, err := s.decodeBlobTx(tx)
+		if err != nil {
+			s.log.Warn("dropping blob batcher transaction with invalid sidecar", "tx", tx.Hash(), "err", err)
+			continue
+		}
+		return data, nil
+	}
+	return nil, io.EOF
+}
+
+// decodeBlobTx recovers frame data from the blobs referenced by `tx`, verifying each blob's KZG
+// commitment against the versioned hash committed to on-chain.
+func (s *BlobDataSource) decodeBlobTx(tx *types.Transaction) (eth.Data, error) {
+	hashes := tx.BlobHashes()
+	var out bytes.Buffer
+	for _, hash := range hashes {
+		sidecar := s.findSidecar(hash)
+		if sidecar == nil {
+			return nil, fmt.Errorf("missing blob sidecar for versioned hash %s", hash)
+		}
+		if err := sidecar.VerifyCommitment(hash); err != nil {
+			return nil, fmt.Errorf("invalid blob commitment for versioned hash %s: %w", hash, err)
+		}
+		frameData, err := sidecar.DecodeFrameData()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frame data from blob: %w", err)
+		}
+		out.Write(frameData)
+	}
+	return out.Bytes(), nil
+}
+
+func (s *BlobDataSource) findSidecar(hash common.Hash) *eth.BlobSidecar {
+	for _, sidecar := range s.sidecars {
+		if sidecar.VersionedHash() == hash {
+			return sidecar
+		}
+	}
+	return nil
+}
+
+// justificationForBlobTx extracts the Espresso justification co-located in the calldata of a blob
+// transaction, if any. L1InfoDeposit must reject any justification which does not correspond to
+// the blob commitments actually seen for the batch it is attached to, since an Espresso header
+// commitment is only meaningful if it attests to the batch that was really posted.
+func justificationForBlobTx(tx *types.Transaction) (*eth.L2BatchJustification, error) {
+	if len(tx.Data()) == 0 {
+		return nil, nil
+	}
+	var envelope blobJustificationEnvelope
+	if err := rlp.DecodeBytes(tx.Data(), &envelope); err != nil {
+		return nil, fmt.Errorf("invalid justification envelope in blob tx calldata: %w", err)
+	}
+	return envelope.Justification, nil
+}
+
+// DataSource multiplexes batcher data retrieval across the calldata and blob data-availability
+// types, dispatching on the transaction type (calldata, or 0x03 for EIP-4844 blob transactions)
+// observed in `l1InclusionBlock`.
+//
+// This covers the reader side of blob posting only. op-batcher, which would chunk channel frames
+// into blobs and bump blob/execution gas prices independently on retry, does not exist in this
+// checkout, so that half of blob support is not addressed here.
+type DataSource struct {
+	open DataIter
+}
+
+// NewDataSource creates a new calldata or blob source depending on which DA type the batcher used
+// to post to `l1InclusionBlock`.
+func NewDataSource(log log.Logger, beacon L1BeaconClient, batcher common.Address, l1InclusionBlock eth.L1BlockRef, txs []*types.Transaction) *DataSource {
+	for _, tx := range txs {
+		if tx.Type() == types.BlobTxType {
+			return &DataSource{open: NewBlobDataSource(log, beacon, l1InclusionBlock, txs, batcher)}
+		}
+	}
+	return &DataSource{open: NewCalldataSource(log, txs, batcher)}
+}
+
+func (s *DataSource) Next(ctx context.Context) (eth.Data, error) {
+	return s.open.Next(ctx)
+}
@@ -0,0 +1,106 @@
+package derive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type mockBeaconClient struct {
+	sidecars []*eth.BlobSidecar
+	err      error
+}
+
+func (m *mockBeaconClient) BlobSidecars(ctx context.Context, l1InclusionBlock eth.L1BlockRef) ([]*eth.BlobSidecar, error) {
+	return m.sidecars, m.err
+}
+
+func TestCalldataSourceSkipsNonBatchInboxTransactions(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlWarn)
+	batcher := common.Address{0x42}
+	other := common.Address{0x99}
+
+	toOther := types.NewTx(&types.LegacyTx{To: &other, Data: []byte("not a batcher frame")})
+	toBatcher := types.NewTx(&types.LegacyTx{To: &batcher, Data: []byte("real frame")})
+	contractCreation := types.NewTx(&types.LegacyTx{Data: []byte("no recipient at all")})
+
+	src := NewCalldataSource(logger, []*types.Transaction{toOther, toBatcher, contractCreation}, batcher)
+
+	data, err := src.Next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, eth.Data("real frame"), data, "only the transaction sent to the batch inbox should be returned")
+
+	_, err = src.Next(context.Background())
+	require.ErrorIs(t, err, io.EOF, "transactions not sent to the batch inbox must be skipped, not yielded as frame data")
+}
+
+func TestBlobDataSourceMissingSidecar(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlWarn)
+	tx := types.NewTx(&types.BlobTx{BlobHashes: []common.Hash{{0x01}}})
+
+	src := NewBlobDataSource(logger, &mockBeaconClient{}, eth.L1BlockRef{}, []*types.Transaction{tx}, common.Address{})
+	_, err := src.Next(context.Background())
+	require.ErrorIs(t, err, io.EOF, "transactions with no matching sidecar are skipped, not returned as errors")
+}
+
+func TestBlobDataSourceBeaconError(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlWarn)
+	tx := types.NewTx(&types.BlobTx{BlobHashes: []common.Hash{{0x01}}})
+
+	src := NewBlobDataSource(logger, &mockBeaconClient{err: errors.New("beacon unavailable")}, eth.L1BlockRef{}, []*types.Transaction{tx}, common.Address{})
+	_, err := src.Next(context.Background())
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrTemporary), "beacon node failures should be retried, not treated as fatal")
+}
+
+func TestJustificationForBlobTxInvalidEnvelope(t *testing.T) {
+	tx := types.NewTx(&types.BlobTx{BlobHashes: []common.Hash{{0x01}}, Data: []byte("not rlp")})
+	_, err := justificationForBlobTx(tx)
+	require.Error(t, err, "malformed calldata envelopes must not be silently ignored")
+}
+
+func TestJustificationForBlobTxNoCalldata(t *testing.T) {
+	tx := types.NewTx(&types.BlobTx{BlobHashes: []common.Hash{{0x01}}})
+	jst, err := justificationForBlobTx(tx)
+	require.NoError(t, err)
+	require.Nil(t, jst, "a blob tx with no calldata carries no justification")
+}
+
+func TestBlobDataSourcePropagatesBlobUnavailable(t *testing.T) {
+	logger := testlog.Logger(t, log.LvlWarn)
+	tx := types.NewTx(&types.BlobTx{BlobHashes: []common.Hash{{0x01}}})
+
+	src := NewBlobDataSource(logger, &mockBeaconClient{err: ErrBlobUnavailable}, eth.L1BlockRef{}, []*types.Transaction{tx}, common.Address{})
+	_, err := src.Next(context.Background())
+	require.ErrorIs(t, err, ErrBlobUnavailable, "a pruned blob must surface as ErrBlobUnavailable, not be retried forever as ErrTemporary")
+	require.False(t, errors.Is(err, ErrTemporary), "pruned data can never be recovered by retrying")
+}
+
+func TestL1BeaconHTTPClientDistinguishesPrunedFromNotYetAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := NewL1BeaconHTTPClient(server.URL)
+
+	recent := eth.L1BlockRef{Number: 1, Time: uint64(time.Now().Unix())}
+	_, err := client.BlobSidecars(context.Background(), recent)
+	require.True(t, errors.Is(err, ErrTemporary), "a 404 for a recent block may just mean the sidecar isn't available yet")
+	require.False(t, errors.Is(err, ErrBlobUnavailable))
+
+	old := eth.L1BlockRef{Number: 1, Time: uint64(time.Now().Add(-blobRetentionWindow - time.Hour).Unix())}
+	_, err = client.BlobSidecars(context.Background(), old)
+	require.True(t, errors.Is(err, ErrBlobUnavailable), "a 404 for a block older than the retention window must be treated as permanently pruned")
+}
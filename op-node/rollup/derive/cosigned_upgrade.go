@@ -0,0 +1,80 @@
+package derive
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CosignerSet is a set of addresses required to separately attest to an upgrade deposit before the
+// derivation pipeline will accept it, e.g. both a security council multisig and the sequencer
+// signer for a proxy upgradeTo. It is always handled in sorted order so that the same set of
+// cosigners produces the same SourceHash regardless of the order they were specified in.
+type CosignerSet []common.Address
+
+// sorted returns a copy of the set in ascending address order.
+func (s CosignerSet) sorted() CosignerSet {
+	out := make(CosignerSet, len(s))
+	copy(out, s)
+	sort.Slice(out, func(i, j int) bool { return out[i].Cmp(out[j]) < 0 })
+	return out
+}
+
+// hash folds the sorted cosigner set into a single digest, for mixing into a deposit's SourceHash.
+func (s CosignerSet) hash() common.Hash {
+	sorted := s.sorted()
+	buf := make([]byte, 0, len(sorted)*common.AddressLength)
+	for _, addr := range sorted {
+		buf = append(buf, addr.Bytes()...)
+	}
+	return crypto.Keccak256Hash(buf)
+}
+
+// CosignedUpgradeDepositSource wraps an UpgradeDepositSource that additionally requires the
+// derivation pipeline to observe a matching L1 attestation event from every address in Cosigners
+// before the deposit it authors is accepted. Folding Cosigners into the source hash (rather than
+// treating it as out-of-band metadata) ensures two deposits with the same intent but different
+// required cosigners can never collide.
+type CosignedUpgradeDepositSource struct {
+	Source    UpgradeDepositSource
+	Cosigners CosignerSet
+}
+
+// SourceHash returns the deposit's source hash: keccak256 of the wrapped UpgradeDepositSource's hash
+// followed by the hash of the required cosigner set. With no cosigners it is the wrapped source's
+// hash unchanged.
+func (s CosignedUpgradeDepositSource) SourceHash() common.Hash {
+	if len(s.Cosigners) == 0 {
+		return s.Source.SourceHash()
+	}
+	inner := s.Source.SourceHash()
+	cosigners := s.Cosigners.hash()
+	return crypto.Keccak256Hash(inner.Bytes(), cosigners.Bytes())
+}
+
+// CosignerAttestation is a single cosigner's attestation to a deposit's SourceHash, observed by the
+// derivation pipeline as an L1 event within the epoch containing the deposit.
+type CosignerAttestation struct {
+	Cosigner   common.Address
+	SourceHash common.Hash
+}
+
+// VerifyCosignerAttestations checks that every address in required has a matching attestation (for
+// sourceHash) in observed. It returns an error naming the first missing cosigner, so the derivation
+// pipeline can surface why a multi-cosigner upgrade deposit was rejected.
+func VerifyCosignerAttestations(sourceHash common.Hash, required CosignerSet, observed []CosignerAttestation) error {
+	seen := make(map[common.Address]bool, len(observed))
+	for _, att := range observed {
+		if att.SourceHash == sourceHash {
+			seen[att.Cosigner] = true
+		}
+	}
+	for _, cosigner := range required.sorted() {
+		if !seen[cosigner] {
+			return fmt.Errorf("missing attestation from required cosigner %s for upgrade deposit %s", cosigner, sourceHash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DASyncerConfig configures DASyncer's fetch retry, rate-limiting, and queue behavior.
+type DASyncerConfig struct {
+	// BaseRetryDelay and MaxRetryDelay bound the exponential backoff applied between retries of a
+	// single height, after a transient fetch or verification failure (including the "height not yet
+	// available" case produced by an L1 reorg that has not yet re-settled).
+	BaseRetryDelay time.Duration
+	MaxRetryDelay  time.Duration
+	// MaxAttempts is how many times a single height is attempted, the first try included, before Run
+	// gives up and returns an error.
+	MaxAttempts int
+	// QueueSize bounds how many verified batches may be buffered ahead of the consumer.
+	QueueSize int
+	// MinFetchInterval is the minimum time between the start of one height's fetch and the next,
+	// used to rate-limit the fetcher against the query service.
+	MinFetchInterval time.Duration
+}
+
+func DefaultDASyncerConfig() DASyncerConfig {
+	return DASyncerConfig{
+		BaseRetryDelay:   time.Second,
+		MaxRetryDelay:    30 * time.Second,
+		MaxAttempts:      10,
+		QueueSize:        256,
+		MinFetchInterval: 50 * time.Millisecond,
+	}
+}
+
+// DABatch is a single HotShot block, verified against its on-chain commitment, ready to be handed
+// to the rest of the derivation pipeline in place of the blocks a normal op-node would have learned
+// about via execution-layer P2P gossip.
+type DABatch struct {
+	Height       uint64
+	Header       espresso.Header
+	Transactions espresso.TransactionsInBlock
+}
+
+// DASource is the subset of espresso.QueryService that DASyncer needs to walk HotShot forward by
+// height: a way to fetch one header at a time, and a way to fetch the transactions belonging to
+// this rollup's namespace in a given block.
+type DASource interface {
+	FetchHeaderByHeight(ctx context.Context, height uint64) (espresso.Header, error)
+	FetchTransactionsInBlock(ctx context.Context, block uint64, header *espresso.Header, namespace uint64) (espresso.TransactionsInBlock, error)
+}
+
+var _ DASource = (*espresso.Client)(nil)
+var _ DASource = (*espresso.ClientList)(nil)
+
+// CommitmentVerifier checks a fetched header's commitment against the on-chain record, the same
+// check EspressoProvider performs for the normal (P2P-fed) derivation path. DASyncer depends on the
+// interface, rather than *EspressoProvider directly, purely to keep this file's tests independent
+// of EspressoProvider's L1Fetcher plumbing.
+type CommitmentVerifier interface {
+	VerifyCommitments(firstHeight uint64, comms []espresso.Commitment) (bool, error)
+}
+
+var _ CommitmentVerifier = (*EspressoProvider)(nil)
+
+// BatchQueue is a bounded FIFO of verified DABatch values, sitting between DASyncer's background
+// fetcher and whatever derivation stage consumes its output. It exists as its own type, rather than
+// a bare channel, so that Reset can be implemented without racing a concurrent Pop.
+type BatchQueue struct {
+	ch chan DABatch
+}
+
+// NewBatchQueue returns a BatchQueue that buffers up to size batches.
+func NewBatchQueue(size int) *BatchQueue {
+	return &BatchQueue{ch: make(chan DABatch, size)}
+}
+
+// Push blocks until there is room in the queue, ctx is done, or the queue is reset.
+func (q *BatchQueue) Push(ctx context.Context, batch DABatch) error {
+	select {
+	case q.ch <- batch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pop blocks until a batch is available or ctx is done.
+func (q *BatchQueue) Pop(ctx context.Context) (DABatch, error) {
+	select {
+	case batch := <-q.ch:
+		return batch, nil
+	case <-ctx.Done():
+		return DABatch{}, ctx.Err()
+	}
+}
+
+// Reset drops every batch currently buffered in the queue. It is called when an L1 reorg is
+// detected past the L1 origin of one or more already-queued (but not yet consumed) batches, since
+// those batches' commitments can no longer be trusted without re-verifying against the new fork.
+func (q *BatchQueue) Reset() {
+	for {
+		select {
+		case <-q.ch:
+		default:
+			return
+		}
+	}
+}
+
+// DASyncer reconstructs the L2 chain purely from Espresso query-service headers plus the
+// on-chain HotShot Commitments mapping, for a follower node that has no execution-layer P2P peers
+// to learn about new blocks from. It walks HotShot heights forward from a persisted cursor, verifies
+// each header's commitment before admitting it, and feeds verified batches into a bounded queue for
+// the rest of the pipeline to consume.
+type DASyncer struct {
+	log       log.Logger
+	cfg       DASyncerConfig
+	source    DASource
+	verifier  CommitmentVerifier
+	namespace uint64
+	cursor    DASyncerCursorStore
+	queue     *BatchQueue
+
+	nextHeight uint64
+}
+
+// NewDASyncer constructs a DASyncer that will begin (or resume) scanning HotShot from one past the
+// height most recently saved in cursor, or from startHeight if cursor has nothing saved yet.
+func NewDASyncer(log log.Logger, cfg DASyncerConfig, source DASource, verifier CommitmentVerifier, namespace uint64, cursor DASyncerCursorStore, startHeight uint64) (*DASyncer, error) {
+	height := startHeight
+	saved, ok, err := cursor.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load DA syncer cursor: %w", err)
+	}
+	if ok {
+		// The cursor records the last height successfully pushed into the queue, so resume one
+		// past it rather than re-fetching (and re-pushing) it.
+		height = saved + 1
+	}
+	return &DASyncer{
+		log:        log,
+		cfg:        cfg,
+		source:     source,
+		verifier:   verifier,
+		namespace:  namespace,
+		cursor:     cursor,
+		queue:      NewBatchQueue(cfg.QueueSize),
+		nextHeight: height,
+	}, nil
+}
+
+// Queue returns the BatchQueue that verified batches are pushed into, for the consuming pipeline
+// stage to Pop from.
+func (d *DASyncer) Queue() *BatchQueue {
+	return d.queue
+}
+
+// Run fetches and verifies HotShot blocks forward from d.nextHeight, pushing each into the queue,
+// until ctx is done or a single height exhausts its retry budget.
+func (d *DASyncer) Run(ctx context.Context) error {
+	for {
+		batch, err := d.fetchWithRetry(ctx, d.nextHeight)
+		if err != nil {
+			return err
+		}
+		if err := d.queue.Push(ctx, batch); err != nil {
+			return err
+		}
+		if err := d.cursor.Save(d.nextHeight); err != nil {
+			// The batch has already been queued, so this is not fatal: at worst, a restart before
+			// the next successful Save re-delivers a handful of already-consumed batches, which the
+			// downstream derivation stage must already tolerate (the same way it tolerates replayed
+			// L1 data after a checkpoint.Store save fails).
+			d.log.Error("failed to save DA syncer cursor", "height", d.nextHeight, "err", err)
+		}
+		d.nextHeight++
+
+		select {
+		case <-time.After(d.cfg.MinFetchInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// fetchWithRetry fetches and verifies the header and transactions at height, retrying with
+// exponential backoff on any failure (including a commitment mismatch, which can legitimately occur
+// transiently if the query service has not yet observed an L1 reorg that invalidated its own view).
+func (d *DASyncer) fetchWithRetry(ctx context.Context, height uint64) (DABatch, error) {
+	var lastErr error
+	for attempt := 0; attempt < d.cfg.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(retryDelay(attempt, d.cfg)):
+			case <-ctx.Done():
+				return DABatch{}, ctx.Err()
+			}
+		}
+
+		batch, err := d.fetchOnce(ctx, height)
+		if err == nil {
+			return batch, nil
+		}
+		lastErr = err
+		d.log.Warn("failed to fetch DA syncer batch, retrying", "height", height, "attempt", attempt+1, "err", err)
+	}
+	return DABatch{}, fmt.Errorf("exhausted retries fetching height %d: %w", height, lastErr)
+}
+
+func (d *DASyncer) fetchOnce(ctx context.Context, height uint64) (DABatch, error) {
+	header, err := d.source.FetchHeaderByHeight(ctx, height)
+	if err != nil {
+		return DABatch{}, fmt.Errorf("failed to fetch header: %w", err)
+	}
+
+	ok, err := d.verifier.VerifyCommitments(height, []espresso.Commitment{header.Commit()})
+	if err != nil {
+		return DABatch{}, fmt.Errorf("failed to verify commitment: %w", err)
+	}
+	if !ok {
+		return DABatch{}, fmt.Errorf("header at height %d does not match its on-chain commitment", height)
+	}
+
+	txs, err := d.source.FetchTransactionsInBlock(ctx, height, &header, d.namespace)
+	if err != nil {
+		return DABatch{}, fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+
+	return DABatch{Height: height, Header: header, Transactions: txs}, nil
+}
+
+// HandleL1Reorg drops every batch currently queued and rewinds the fetcher to resume from
+// resumeHeight, the last HotShot height whose commitment is still known-good on the new L1 fork.
+// The caller (the stage driving the normal L1 reorg-detection logic) is responsible for determining
+// resumeHeight; DASyncer itself has no visibility into L1 reorgs beyond the reorg-safety error
+// VerifyCommitments already surfaces for heights it has not yet consumed.
+func (d *DASyncer) HandleL1Reorg(resumeHeight uint64) {
+	d.queue.Reset()
+	d.nextHeight = resumeHeight
+}
+
+// retryDelay returns the backoff before retry attempt n (n >= 1): exponential growth from
+// BaseRetryDelay, capped at MaxRetryDelay.
+func retryDelay(n int, cfg DASyncerConfig) time.Duration {
+	delay := cfg.BaseRetryDelay << (n - 1)
+	if delay <= 0 || delay > cfg.MaxRetryDelay { // overflow or past the cap
+		delay = cfg.MaxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,83 @@
+package derive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DASyncerCursorStore persists the single HotShot height DASyncer has most recently consumed, so a
+// restarted follower node resumes from there rather than rescanning from genesis. It is keyed
+// separately from (and saved independently of) checkpoint.Store: that package tracks an
+// in-progress sequencing batch, while this tracks a follower's read-only scan position, and the
+// two are never both in play on the same node.
+type DASyncerCursorStore interface {
+	// Load returns the last-saved height, or ok=false if none has been saved yet.
+	Load() (height uint64, ok bool, err error)
+	// Save persists height, replacing whatever was previously saved.
+	Save(height uint64) error
+}
+
+// DASyncerMemCursorStore is an in-memory DASyncerCursorStore, suitable for tests. It is not safe
+// for concurrent use, matching DASyncer's own single-threaded fetch-loop convention.
+type DASyncerMemCursorStore struct {
+	height uint64
+	ok     bool
+}
+
+func NewDASyncerMemCursorStore() *DASyncerMemCursorStore {
+	return &DASyncerMemCursorStore{}
+}
+
+func (m *DASyncerMemCursorStore) Load() (uint64, bool, error) {
+	return m.height, m.ok, nil
+}
+
+func (m *DASyncerMemCursorStore) Save(height uint64) error {
+	m.height = height
+	m.ok = true
+	return nil
+}
+
+// DASyncerFileCursorStore is the default production DASyncerCursorStore: it keeps the cursor as
+// JSON in a single file, writing a new file and renaming it over the old one so a crash mid-write
+// cannot leave a truncated cursor behind.
+type DASyncerFileCursorStore struct {
+	path string
+}
+
+// NewDASyncerFileCursorStore returns a DASyncerCursorStore backed by the file at path. The file
+// need not exist yet; Load reports ok=false until the first Save.
+func NewDASyncerFileCursorStore(path string) *DASyncerFileCursorStore {
+	return &DASyncerFileCursorStore{path: path}
+}
+
+func (f *DASyncerFileCursorStore) Load() (uint64, bool, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("failed to read DA syncer cursor file %s: %w", f.path, err)
+	}
+	var height uint64
+	if err := json.Unmarshal(data, &height); err != nil {
+		return 0, false, fmt.Errorf("failed to parse DA syncer cursor file %s: %w", f.path, err)
+	}
+	return height, true, nil
+}
+
+func (f *DASyncerFileCursorStore) Save(height uint64) error {
+	data, err := json.Marshal(height)
+	if err != nil {
+		return fmt.Errorf("failed to encode DA syncer cursor: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write DA syncer cursor file %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to install DA syncer cursor file %s: %w", f.path, err)
+	}
+	return nil
+}
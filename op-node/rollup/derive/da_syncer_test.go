@@ -0,0 +1,155 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso"
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDASource serves headers and transactions out of an in-memory slice, optionally failing the
+// first N calls for a given height to exercise fetchWithRetry.
+type fakeDASource struct {
+	headers    map[uint64]espresso.Header
+	failUntil  map[uint64]int
+	callCounts map[uint64]int
+}
+
+func newFakeDASource(heights ...uint64) *fakeDASource {
+	s := &fakeDASource{
+		headers:    make(map[uint64]espresso.Header),
+		failUntil:  make(map[uint64]int),
+		callCounts: make(map[uint64]int),
+	}
+	for _, h := range heights {
+		s.headers[h] = espresso.Header{Metadata: espresso.Metadata{Timestamp: h}}
+	}
+	return s
+}
+
+func (s *fakeDASource) FetchHeaderByHeight(ctx context.Context, height uint64) (espresso.Header, error) {
+	s.callCounts[height]++
+	if s.callCounts[height] <= s.failUntil[height] {
+		return espresso.Header{}, fmt.Errorf("simulated transient failure for height %d", height)
+	}
+	header, ok := s.headers[height]
+	if !ok {
+		return espresso.Header{}, fmt.Errorf("no header at height %d", height)
+	}
+	return header, nil
+}
+
+func (s *fakeDASource) FetchTransactionsInBlock(ctx context.Context, block uint64, header *espresso.Header, namespace uint64) (espresso.TransactionsInBlock, error) {
+	return espresso.TransactionsInBlock{}, nil
+}
+
+// fakeCommitmentVerifier accepts every commitment it's handed, unless rejectHeights says otherwise.
+type fakeCommitmentVerifier struct {
+	rejectHeights map[uint64]bool
+}
+
+func (v *fakeCommitmentVerifier) VerifyCommitments(firstHeight uint64, comms []espresso.Commitment) (bool, error) {
+	return !v.rejectHeights[firstHeight], nil
+}
+
+func fastDASyncerConfig() DASyncerConfig {
+	cfg := DefaultDASyncerConfig()
+	cfg.BaseRetryDelay = time.Millisecond
+	cfg.MaxRetryDelay = 2 * time.Millisecond
+	cfg.MinFetchInterval = 0
+	cfg.MaxAttempts = 5
+	return cfg
+}
+
+func TestDASyncerFetchesBatchesInOrder(t *testing.T) {
+	source := newFakeDASource(0, 1, 2)
+	verifier := &fakeCommitmentVerifier{rejectHeights: map[uint64]bool{}}
+	syncer, err := NewDASyncer(testlog.Logger(t, log.LvlCrit), fastDASyncerConfig(), source, verifier, 42, NewDASyncerMemCursorStore(), 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = syncer.Run(ctx) }()
+
+	for h := uint64(0); h <= 2; h++ {
+		batch, err := syncer.Queue().Pop(ctx)
+		require.NoError(t, err)
+		require.Equal(t, h, batch.Height)
+	}
+}
+
+func TestDASyncerRetriesTransientFailures(t *testing.T) {
+	source := newFakeDASource(0)
+	source.failUntil[0] = 2 // first two calls fail, third succeeds
+	verifier := &fakeCommitmentVerifier{rejectHeights: map[uint64]bool{}}
+	syncer, err := NewDASyncer(testlog.Logger(t, log.LvlCrit), fastDASyncerConfig(), source, verifier, 42, NewDASyncerMemCursorStore(), 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() { _ = syncer.Run(ctx) }()
+
+	batch, err := syncer.Queue().Pop(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), batch.Height)
+	require.Equal(t, 3, source.callCounts[0])
+}
+
+func TestDASyncerGivesUpAfterMaxAttempts(t *testing.T) {
+	source := newFakeDASource(0)
+	source.failUntil[0] = 1000 // never succeeds
+	verifier := &fakeCommitmentVerifier{rejectHeights: map[uint64]bool{}}
+	cfg := fastDASyncerConfig()
+	syncer, err := NewDASyncer(testlog.Logger(t, log.LvlCrit), cfg, source, verifier, 42, NewDASyncerMemCursorStore(), 0)
+	require.NoError(t, err)
+
+	err = syncer.Run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, cfg.MaxAttempts, source.callCounts[0])
+}
+
+func TestDASyncerResumesFromSavedCursor(t *testing.T) {
+	cursor := NewDASyncerMemCursorStore()
+	require.NoError(t, cursor.Save(5))
+
+	source := newFakeDASource(6, 7)
+	verifier := &fakeCommitmentVerifier{rejectHeights: map[uint64]bool{}}
+	syncer, err := NewDASyncer(testlog.Logger(t, log.LvlCrit), fastDASyncerConfig(), source, verifier, 42, cursor, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), syncer.nextHeight, "should resume one past the saved cursor, not replay it")
+}
+
+func TestDASyncerHandleL1ReorgResetsQueueAndCursor(t *testing.T) {
+	source := newFakeDASource(0, 1, 2)
+	verifier := &fakeCommitmentVerifier{rejectHeights: map[uint64]bool{}}
+	syncer, err := NewDASyncer(testlog.Logger(t, log.LvlCrit), fastDASyncerConfig(), source, verifier, 42, NewDASyncerMemCursorStore(), 0)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, syncer.queue.Push(ctx, DABatch{Height: 1}))
+	require.NoError(t, syncer.queue.Push(ctx, DABatch{Height: 2}))
+
+	syncer.HandleL1Reorg(1)
+
+	require.Equal(t, uint64(1), syncer.nextHeight)
+	popCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = syncer.queue.Pop(popCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded, "queue should have been drained by the reorg")
+}
+
+func TestBatchQueueBlocksWhenFull(t *testing.T) {
+	q := NewBatchQueue(1)
+	ctx := context.Background()
+	require.NoError(t, q.Push(ctx, DABatch{Height: 0}))
+
+	pushCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err := q.Push(pushCtx, DABatch{Height: 1})
+	require.ErrorIs(t, err, context.DeadlineExceeded, "a full queue should block Push until space frees up or the context is done")
+}
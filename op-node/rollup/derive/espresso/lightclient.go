@@ -0,0 +1,188 @@
+// Package espresso implements a Tendermint-style "skipping verification" light client for HotShot
+// headers: given a header already trusted at some height, along with the validator set that
+// produced it, Verifier can accept a much later header without re-verifying every header in
+// between, as long as enough of the trusted validator set also signed the later header. This
+// complements hotshot.HotShotLightClientVerifier (op-service/espresso/hotshot), which checks a
+// header's commitment against a light client contract's Merkle-proven state root instead of
+// against validator signatures; neither is implemented in terms of the other.
+//
+// HotShot's real quorum certificates are BLS-signed and stake-weighted, and its stake table is
+// read from a staking contract. No BLS verifier and no stake table client are vendored in this
+// checkout (see QuorumCertificate's doc comment), so this package takes the already-authenticated
+// signer set as input rather than verifying raw signatures, and treats every validator as carrying
+// equal weight.
+package espresso
+
+import (
+	"errors"
+
+	espresso "github.com/EspressoSystems/espresso-sequencer-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNotEnoughTrust is returned by VerifyRange when no candidate header -- among those supplied --
+// bisects the gap between the nearest already-trusted header and the target: skipping verification
+// gives up rather than accepting a header signed by less than the required fraction of the trusted
+// validator set.
+var ErrNotEnoughTrust = errors.New("espresso: not enough of the trusted validator set signed this header")
+
+// ErrInvalidCommit is returned when a header's own commitment does not match the commitment its
+// QuorumCertificate claims to certify.
+var ErrInvalidCommit = errors.New("espresso: header commitment does not match its quorum certificate")
+
+// ErrValidatorSetChanged is returned when a header that VerifyRange is about to trust declares a
+// validator set different from the one it was actually verified against. A validator set update
+// must itself be authenticated the same way HotShot's stake table updates are (out of scope here;
+// see the package doc comment), so VerifyRange refuses to silently carry forward a change rather
+// than adopting it on the strength of the old set's signatures alone.
+var ErrValidatorSetChanged = errors.New("espresso: validator set changed without a corresponding light client update")
+
+// ValidatorSet is the set of validator addresses that produced a QuorumCertificate. Every member is
+// weighted equally; see the package doc comment for why this checkout has no stake-weighted model.
+type ValidatorSet []common.Address
+
+func (vs ValidatorSet) contains(addr common.Address) bool {
+	for _, v := range vs {
+		if v == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// overlap returns how many addresses in signers are also members of vs.
+func (vs ValidatorSet) overlap(signers ValidatorSet) int {
+	n := 0
+	for _, s := range signers {
+		if vs.contains(s) {
+			n++
+		}
+	}
+	return n
+}
+
+func (vs ValidatorSet) equal(other ValidatorSet) bool {
+	if len(vs) != len(other) {
+		return false
+	}
+	for i := range vs {
+		if vs[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// QuorumCertificate is the set of validators HotShot consensus recorded as having signed Commit. A
+// production light client verifies this against individual BLS signatures; see the package doc
+// comment for why Signers is taken here as already-authenticated input instead.
+type QuorumCertificate struct {
+	Commit  espresso.Commitment
+	Signers ValidatorSet
+}
+
+// SignedHeader pairs a HotShot header with the QuorumCertificate HotShot consensus produced for it
+// and the validator set in effect starting at this header (ordinarily unchanged from the previous
+// header; see ErrValidatorSetChanged).
+type SignedHeader struct {
+	Header     espresso.Header
+	QC         QuorumCertificate
+	Validators ValidatorSet
+}
+
+// TrustedHeader is a header a Verifier has already established trust in, together with the
+// validator set in effect at that height, which later calls to VerifyRange bisect trust against.
+type TrustedHeader struct {
+	Header     espresso.Header
+	Validators ValidatorSet
+}
+
+// LightStore persists the latest TrustedHeader a Verifier has established, so that repeated calls
+// to VerifyRange across pipeline replay don't re-verify a span from genesis every time.
+type LightStore interface {
+	LatestTrusted() (TrustedHeader, bool)
+	SetLatestTrusted(TrustedHeader)
+}
+
+// MemLightStore is an in-memory LightStore. It does not survive a process restart; a persistent
+// LightStore backed by a database belongs wherever this node already keeps its other derivation
+// pipeline state, not in this package.
+type MemLightStore struct {
+	latest TrustedHeader
+	has    bool
+}
+
+// NewMemLightStore returns an empty MemLightStore.
+func NewMemLightStore() *MemLightStore {
+	return &MemLightStore{}
+}
+
+func (s *MemLightStore) LatestTrusted() (TrustedHeader, bool) {
+	return s.latest, s.has
+}
+
+func (s *MemLightStore) SetLatestTrusted(h TrustedHeader) {
+	s.latest = h
+	s.has = true
+}
+
+// Verifier checks a sequence of HotShot headers against an already-trusted header using
+// Tendermint-style skipping verification, and remembers the highest header it establishes trust in
+// via Store.
+type Verifier struct {
+	Store LightStore
+}
+
+// NewVerifier returns a Verifier that persists trust progress to store.
+func NewVerifier(store LightStore) *Verifier {
+	return &Verifier{Store: store}
+}
+
+// VerifyRange verifies that targets[len(targets)-1] legitimately follows trusted, treating every
+// earlier entry of targets as an available bisection point rather than something that must itself
+// be individually verified. targets must be sorted by ascending header height, and every header in
+// it must be newer than trusted.Header; VerifyRange does not defend against out-of-order or
+// duplicate heights.
+//
+// On success, v.Store is updated with the final target, so a later call can resume from there
+// instead of re-verifying from trusted again.
+func (v *Verifier) VerifyRange(trusted TrustedHeader, targets []SignedHeader) error {
+	if len(targets) == 0 {
+		return nil
+	}
+	final := targets[len(targets)-1]
+	newTrusted, err := v.verify(trusted, targets[:len(targets)-1], final)
+	if err != nil {
+		return err
+	}
+	v.Store.SetLatestTrusted(newTrusted)
+	return nil
+}
+
+// verify establishes that target legitimately follows trusted, recursing through candidates
+// (headers strictly between trusted and target, ascending) as bisection points whenever a direct
+// trust-overlap check against target isn't enough on its own.
+func (v *Verifier) verify(trusted TrustedHeader, candidates []SignedHeader, target SignedHeader) (TrustedHeader, error) {
+	if !target.Header.Commit().Equals(target.QC.Commit) {
+		return TrustedHeader{}, ErrInvalidCommit
+	}
+
+	// More than 1/3 of the trusted validator set must have also signed target's commit: any fewer
+	// and a dishonest minority smaller than HotShot's own fault tolerance could have forged it.
+	if trusted.Validators.overlap(target.QC.Signers)*3 > len(trusted.Validators) {
+		if !trusted.Validators.equal(target.Validators) {
+			return TrustedHeader{}, ErrValidatorSetChanged
+		}
+		return TrustedHeader{Header: target.Header, Validators: target.Validators}, nil
+	}
+
+	if len(candidates) == 0 {
+		return TrustedHeader{}, ErrNotEnoughTrust
+	}
+	mid := len(candidates) / 2
+	midTrusted, err := v.verify(trusted, candidates[:mid], candidates[mid])
+	if err != nil {
+		return TrustedHeader{}, err
+	}
+	return v.verify(midTrusted, candidates[mid+1:], target)
+}
@@ -0,0 +1,124 @@
+package espresso
+
+import (
+	"math/big"
+	"testing"
+
+	espresso "github.com/EspressoSystems/espresso-sequencer-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func validatorSet(n int) ValidatorSet {
+	vs := make(ValidatorSet, n)
+	for i := range vs {
+		vs[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	return vs
+}
+
+func signedHeader(height uint64, validators ValidatorSet, signers ValidatorSet) SignedHeader {
+	h := espresso.Header{Height: height}
+	return SignedHeader{
+		Header:     h,
+		QC:         QuorumCertificate{Commit: h.Commit(), Signers: signers},
+		Validators: validators,
+	}
+}
+
+func trustedHeader(height uint64, validators ValidatorSet) TrustedHeader {
+	return TrustedHeader{Header: espresso.Header{Height: height}, Validators: validators}
+}
+
+func TestVerifyRangeDirect(t *testing.T) {
+	vs := validatorSet(4)
+	trusted := trustedHeader(0, vs)
+	// 3 of 4 validators signing is enough overlap to jump directly.
+	target := signedHeader(100, vs, vs[:3])
+
+	v := NewVerifier(NewMemLightStore())
+	require.NoError(t, v.VerifyRange(trusted, []SignedHeader{target}))
+
+	latest, ok := v.Store.LatestTrusted()
+	require.True(t, ok)
+	require.Equal(t, target.Header, latest.Header)
+}
+
+// TestVerifyRangeSkipsAcrossGaps mirrors hotshotSkippedHeaders in batches_espresso_test.go: a
+// direct check against the final, far-away header fails (only the bare minimum signed it), but an
+// intermediate header in targets bisects the gap and lets VerifyRange chain trust through it.
+func TestVerifyRangeSkipsAcrossGaps(t *testing.T) {
+	vs := validatorSet(9) // 1/3 threshold is 3 signers.
+	trusted := trustedHeader(0, vs)
+
+	// Only 2 of the trusted validators signed height 100 directly: not enough on its own.
+	far := signedHeader(100, vs, vs[:2])
+	// But 4 of the trusted validators signed an intermediate header at height 50.
+	mid := signedHeader(50, vs, vs[:4])
+
+	v := NewVerifier(NewMemLightStore())
+	require.NoError(t, v.VerifyRange(trusted, []SignedHeader{mid, far}))
+
+	latest, ok := v.Store.LatestTrusted()
+	require.True(t, ok)
+	require.Equal(t, far.Header, latest.Header)
+}
+
+func TestVerifyRangeNotEnoughTrustWithoutABisectionPoint(t *testing.T) {
+	vs := validatorSet(9)
+	trusted := trustedHeader(0, vs)
+	far := signedHeader(100, vs, vs[:2])
+
+	v := NewVerifier(NewMemLightStore())
+	err := v.VerifyRange(trusted, []SignedHeader{far})
+	require.ErrorIs(t, err, ErrNotEnoughTrust)
+
+	_, ok := v.Store.LatestTrusted()
+	require.False(t, ok, "a failed verification must not update the store")
+}
+
+// TestVerifyRangeDishonestSigners mirrors hotshotDishonestHeaders in batches_espresso_test.go: a
+// header's QuorumCertificate is signed by addresses outside the trusted validator set (e.g. a
+// minority attempting to forge a header HotShot itself never committed), which must never count
+// toward the overlap threshold.
+func TestVerifyRangeDishonestSigners(t *testing.T) {
+	vs := validatorSet(4)
+	trusted := trustedHeader(0, vs)
+	impostors := validatorSet(10)[4:] // disjoint from vs
+	target := signedHeader(100, vs, impostors)
+
+	v := NewVerifier(NewMemLightStore())
+	err := v.VerifyRange(trusted, []SignedHeader{target})
+	require.ErrorIs(t, err, ErrNotEnoughTrust)
+}
+
+func TestVerifyRangeInvalidCommit(t *testing.T) {
+	vs := validatorSet(4)
+	trusted := trustedHeader(0, vs)
+
+	target := signedHeader(100, vs, vs[:3])
+	target.QC.Commit = espresso.Header{Height: 101}.Commit() // does not match target.Header
+
+	v := NewVerifier(NewMemLightStore())
+	err := v.VerifyRange(trusted, []SignedHeader{target})
+	require.ErrorIs(t, err, ErrInvalidCommit)
+}
+
+func TestVerifyRangeValidatorSetChanged(t *testing.T) {
+	vs := validatorSet(4)
+	trusted := trustedHeader(0, vs)
+
+	target := signedHeader(100, validatorSet(5), vs[:3])
+
+	v := NewVerifier(NewMemLightStore())
+	err := v.VerifyRange(trusted, []SignedHeader{target})
+	require.ErrorIs(t, err, ErrValidatorSetChanged)
+}
+
+func TestVerifyRangeEmptyTargets(t *testing.T) {
+	v := NewVerifier(NewMemLightStore())
+	require.NoError(t, v.VerifyRange(trustedHeader(0, validatorSet(4)), nil))
+
+	_, ok := v.Store.LatestTrusted()
+	require.False(t, ok)
+}
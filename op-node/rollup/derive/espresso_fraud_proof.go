@@ -0,0 +1,104 @@
+package derive
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	espresso "github.com/EspressoSystems/espresso-sequencer-go/types"
+)
+
+// errNoLiveL1 is returned by replayL1Provider's methods that a fraud proof replay has no business
+// calling: VerifyFraudProof only ever needs VerifyCommitments, since everything else
+// CheckBatchEspresso might otherwise ask an EspressoL1Provider for is already folded into the
+// witness (L2SafeHead, L1Blocks) or is for the L1-origin-selection path a fraud proof replay
+// doesn't exercise here.
+var errNoLiveL1 = errors.New("espresso: fraud proof replay has no live L1 connection")
+
+// EspressoFraudProof is the minimum witness needed to replay an Espresso-specific BatchDrop verdict
+// CheckBatchEspresso reached: the justification it checked (the Espresso headers and their
+// namespace-proof Merkle paths), the L1 origin refs and L2 safe head that bounded the sequencing
+// window, and the per-height commitments EspressoL1Provider.VerifyCommitments returned for that
+// justification at the time, so a verifier with no L1 connection of its own can still redo the
+// comparison CheckBatchEspresso made against the sequencer contract.
+type EspressoFraudProof struct {
+	L2SafeHead eth.L2BlockRef
+	L1Blocks   []eth.L1BlockRef
+	Batch      *SingularBatch
+	// ExpectedCommitments mirrors what l1.VerifyCommitments returned for Batch.Justification when
+	// CheckBatchEspresso ran; see the type doc comment.
+	ExpectedCommitments []espresso.Commitment
+	// Reason is a short, human-readable description of which CheckBatchEspresso check failed,
+	// carried along for logging/debugging; VerifyFraudProof does not read it back.
+	Reason string
+}
+
+// FraudProofSink receives an EspressoFraudProof whenever CheckBatch drops a batch for an
+// Espresso-specific reason, so a caller can submit it on-chain, queue it for a challenge game, or
+// just log it, without CheckBatch itself knowing anything about how fraud proofs get used.
+type FraudProofSink interface {
+	EmitFraudProof(proof *EspressoFraudProof)
+}
+
+// replayL1Provider is the EspressoL1Provider VerifyFraudProof uses to re-run CheckBatchEspresso: it
+// has no L1 connection, so VerifyCommitments just compares the justification's own header
+// commitments (already re-derived by the caller, not trusted blindly) against the
+// ExpectedCommitments the original CheckBatch call recorded, instead of reading a live contract.
+type replayL1Provider struct {
+	expected []espresso.Commitment
+}
+
+func (p *replayL1Provider) VerifyCommitments(firstBlockHeight uint64, comms []espresso.Commitment) (bool, error) {
+	if len(comms) != len(p.expected) {
+		return false, nil
+	}
+	for i := range comms {
+		if !comms[i].Equals(p.expected[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (p *replayL1Provider) L1BlockRefByNumber(ctx context.Context, num uint64) (eth.L1BlockRef, error) {
+	return eth.L1BlockRef{}, errNoLiveL1
+}
+
+func (p *replayL1Provider) L1Safe(ctx context.Context) (eth.L1BlockRef, error) {
+	return eth.L1BlockRef{}, errNoLiveL1
+}
+
+func (p *replayL1Provider) FetchReceipts(ctx context.Context, blockHash common.Hash) (eth.BlockInfo, types.Receipts, error) {
+	return nil, nil, errNoLiveL1
+}
+
+// VerifyFraudProof re-runs CheckBatchEspresso's checks against proof's bundled witness and returns
+// the verdict it reaches, which must equal whatever CheckBatch originally returned for the same
+// inputs. Unlike the original call, this needs no live L1 connection: proof.ExpectedCommitments
+// stands in for the sequencer contract read that EspressoL1Provider.VerifyCommitments would
+// otherwise perform.
+func VerifyFraudProof(proof *EspressoFraudProof, sysCfg *eth.SystemConfig, cfg *rollup.Config) BatchValidity {
+	l1 := &replayL1Provider{expected: proof.ExpectedCommitments}
+	return CheckBatchEspresso(cfg, sysCfg, log.Root(), proof.L2SafeHead, proof.Batch, l1, proof.L1Blocks, nil)
+}
+
+// emitEspressoFraudProof builds an EspressoFraudProof from the inputs CheckBatchEspresso just
+// evaluated and hands it to sink, if one was provided. comms may be nil if CheckBatchEspresso
+// dropped the batch before it had a chance to compute them (e.g. a missing justification).
+func emitEspressoFraudProof(sink FraudProofSink, l2SafeHead eth.L2BlockRef, l1Blocks []eth.L1BlockRef, batch *SingularBatch, comms []espresso.Commitment, reason string) {
+	if sink == nil {
+		return
+	}
+	sink.EmitFraudProof(&EspressoFraudProof{
+		L2SafeHead:          l2SafeHead,
+		L1Blocks:            l1Blocks,
+		Batch:               batch,
+		ExpectedCommitments: comms,
+		Reason:              reason,
+	})
+}
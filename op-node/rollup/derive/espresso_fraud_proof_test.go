@@ -0,0 +1,52 @@
+package derive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/common"
+
+	espresso "github.com/EspressoSystems/espresso-sequencer-go/types"
+)
+
+func TestEmitEspressoFraudProofNilSink(t *testing.T) {
+	// A nil sink (the default for both CheckBatch and BatchValidator) must be a safe no-op, not a
+	// nil pointer dereference.
+	require.NotPanics(t, func() {
+		emitEspressoFraudProof(nil, eth.L2BlockRef{}, nil, nil, nil, "unused")
+	})
+}
+
+func TestReplayL1ProviderVerifyCommitments(t *testing.T) {
+	comms := []espresso.Commitment{
+		espresso.Header{Height: 0}.Commit(),
+		espresso.Header{Height: 1}.Commit(),
+	}
+	p := &replayL1Provider{expected: comms}
+
+	valid, err := p.VerifyCommitments(0, comms)
+	require.NoError(t, err)
+	require.True(t, valid, "identical commitments must verify")
+
+	mismatched := []espresso.Commitment{espresso.Header{Height: 2}.Commit(), comms[1]}
+	valid, err = p.VerifyCommitments(0, mismatched)
+	require.NoError(t, err)
+	require.False(t, valid, "a changed commitment must not verify")
+
+	valid, err = p.VerifyCommitments(0, comms[:1])
+	require.NoError(t, err)
+	require.False(t, valid, "a different length must not verify")
+}
+
+func TestReplayL1ProviderHasNoLiveL1(t *testing.T) {
+	p := &replayL1Provider{}
+	_, err := p.L1BlockRefByNumber(context.Background(), 0)
+	require.ErrorIs(t, err, errNoLiveL1)
+	_, err = p.L1Safe(context.Background())
+	require.ErrorIs(t, err, errNoLiveL1)
+	_, _, err = p.FetchReceipts(context.Background(), common.Hash{})
+	require.ErrorIs(t, err, errNoLiveL1)
+}
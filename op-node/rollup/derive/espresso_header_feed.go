@@ -0,0 +1,96 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso/hotshot"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// HeaderFeed fans out a single hotshot.HotShotProvider.WatchNewCommitments subscription to any
+// number of subscribers, so starting a second consumer of newly-confirmed HotShot blocks (e.g. an
+// EspressoFetcher-style component reacting to them as they land) never costs a second upstream
+// subscription. It is the derivation-side analogue of EspressoLivenessSwitch: a thin,
+// mutex-protected wrapper around a hotshot.HotShotProvider call, exposing exactly the piece of
+// behavior its name promises and nothing more.
+//
+// Wiring a HeaderFeed into the rest of the derivation pipeline -- starting one alongside a
+// BatchValidator and having some component actually Subscribe to it -- is intentionally left for a
+// follow-up, the same way EspressoLivenessSwitch's doc comment leaves wiring CheckBatch up to a
+// follow-up: no EspressoFetcher-style component exists in this tree yet for a HeaderFeed to feed, so
+// there is no caller yet to decide where Start should be invoked from. HeaderFeed itself is complete
+// and ready for that wiring once such a caller exists.
+type HeaderFeed struct {
+	log log.Logger
+
+	mu   sync.Mutex
+	subs map[chan<- *hotshot.NewBlocksEvent]struct{}
+}
+
+// NewHeaderFeed constructs a HeaderFeed with no subscribers and no running subscription; call Start
+// to begin watching.
+func NewHeaderFeed(log log.Logger) *HeaderFeed {
+	return &HeaderFeed{log: log, subs: make(map[chan<- *hotshot.NewBlocksEvent]struct{})}
+}
+
+// Subscribe registers sink to receive the events Start's subscription delivers from fromBlock
+// onward (an event is skipped for a sink that is not ready to receive it; see broadcast), and
+// returns a function that unregisters it. Calling the returned function more than once
+// is a no-op.
+func (f *HeaderFeed) Subscribe(sink chan<- *hotshot.NewBlocksEvent) (unsubscribe func()) {
+	f.mu.Lock()
+	f.subs[sink] = struct{}{}
+	f.mu.Unlock()
+
+	return func() {
+		f.mu.Lock()
+		delete(f.subs, sink)
+		f.mu.Unlock()
+	}
+}
+
+// Start begins watching provider for new HotShot commitments from fromBlock, fanning each one out
+// to every sink currently registered via Subscribe, and blocks until ctx is done or the underlying
+// subscription ends in error. Callers that want Start to run in the background should invoke it
+// from their own goroutine, the same way a caller of hotshot.HotShotProvider.WatchNewCommitments
+// would run its own forwarding loop.
+func (f *HeaderFeed) Start(ctx context.Context, provider *hotshot.HotShotProvider, fromBlock uint64) error {
+	raw := make(chan *hotshot.NewBlocksEvent)
+	sub, err := provider.WatchNewCommitments(&bind.WatchOpts{Context: ctx}, raw, fromBlock)
+	if err != nil {
+		return fmt.Errorf("failed to start HotShot commitment subscription: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-raw:
+			f.broadcast(ev)
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// broadcast delivers ev to every currently-registered subscriber. A subscriber whose sink is not
+// ready to receive does not block the others: it is skipped for this event rather than stalling the
+// whole feed, the same tradeoff EspressoLivenessSwitch's callers already accept by polling rather
+// than blocking on a slow consumer.
+func (f *HeaderFeed) broadcast(ev *hotshot.NewBlocksEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sink := range f.subs {
+		select {
+		case sink <- ev:
+		default:
+			f.log.Warn("dropping HotShot commitment event for slow HeaderFeed subscriber", "height", ev.BlockHeight)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package derive
+
+import (
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// espressoHeaderSkewTolerance bounds how far into the future a HotShot header's timestamp may be,
+// relative to the sequencer's wall clock, before it is rejected as implausible rather than merely
+// ahead of schedule.
+const espressoHeaderSkewTolerance = 30
+
+// ValidateEspressoHeader checks that curr is a well-formed continuation of prev before it is
+// included in an L2 batch. HotShot is specified to guarantee monotonic timestamps and L1 origins,
+// but should not be blindly trusted to actually do so, so the sequencer defends itself here rather
+// than relying solely on HotShot's own consensus rules.
+//
+// l1Head is the most recent L1 block the sequencer knows about; a header referencing a newer one is
+// rejected outright, since the sequencer cannot evaluate deposits or an L1 origin it has not seen.
+// l1Finalized is the most recent *finalized* L1 block; a header referencing an L1 block newer than
+// that is not rejected, but callers should treat it as ErrTemporary (via the returned error) and
+// stall batch construction until the referenced block is finalized, rather than building on an L1
+// origin that could still be reorged out.
+func ValidateEspressoHeader(prev, curr *espresso.Header, nowUnix uint64, l1Finalized, l1Head eth.L1BlockRef) error {
+	if prev != nil {
+		if curr.Timestamp < prev.Timestamp {
+			return NewCriticalError(fmt.Errorf("espresso header timestamp %d is less than previous header timestamp %d", curr.Timestamp, prev.Timestamp))
+		}
+		if curr.L1Head < prev.L1Head {
+			return NewCriticalError(fmt.Errorf("espresso header L1 origin %d is less than previous header L1 origin %d", curr.L1Head, prev.L1Head))
+		}
+	}
+	if curr.Timestamp > nowUnix+espressoHeaderSkewTolerance {
+		return NewCriticalError(fmt.Errorf("espresso header timestamp %d is too far in the future (now %d)", curr.Timestamp, nowUnix))
+	}
+	if curr.L1Head > l1Head.Number {
+		return NewCriticalError(fmt.Errorf("espresso header references unknown L1 block %d (sequencer has seen up to %d)", curr.L1Head, l1Head.Number))
+	}
+	if curr.L1Head > l1Finalized.Number {
+		return NewTemporaryError(fmt.Errorf("espresso header references L1 block %d which is not yet finalized (finalized up to %d)", curr.L1Head, l1Finalized.Number))
+	}
+	return nil
+}
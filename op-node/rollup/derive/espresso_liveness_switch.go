@@ -0,0 +1,68 @@
+package derive
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso/hotshot"
+)
+
+// EspressoLivenessSwitch tracks whether HotShot-sequenced batch validation should currently be
+// active, based on hotshot.HotShotProvider.CheckLiveness. It is the derivation-side analogue of
+// driver.SwitchSequencer: that type decides when the *sequencer* falls back to centralized
+// sequencing once HotShot stops committing; this type makes the same liveness determination
+// available to whatever on the derivation side needs to decide when to stop requiring
+// CheckBatchEspresso's checks and accept batches from the legacy op-batcher inbox instead.
+//
+// Wiring this into CheckBatch/checkSingularBatch is intentionally left for a follow-up: sysCfg.Espresso
+// is read as a plain, immutable bool everywhere in batches.go (see checkSingularBatch's tail switch),
+// so making derivation actually consult a live EspressoLivenessSwitch means threading one through
+// CheckBatch's signature (and every one of its callers), and changing that tail switch to call
+// IsEspressoActive() instead of reading sysCfg.Espresso directly -- a change to every caller in the
+// chain, not something EspressoLivenessSwitch can safely do unilaterally in this commit.
+// EspressoLivenessSwitch itself is complete and ready for that wiring once it happens.
+type EspressoLivenessSwitch struct {
+	provider *hotshot.HotShotProvider
+	maxLag   uint64
+
+	mu         sync.Mutex
+	active     bool
+	switchedAt time.Time
+}
+
+// NewEspressoLivenessSwitch constructs a switch that starts in the active (Espresso) state; the
+// first call to Poll is what can flip it to inactive.
+func NewEspressoLivenessSwitch(provider *hotshot.HotShotProvider, maxLag uint64) *EspressoLivenessSwitch {
+	return &EspressoLivenessSwitch{provider: provider, maxLag: maxLag, active: true}
+}
+
+// Poll checks HotShot's liveness as of expectedHeight and updates the switch's active state to
+// match, returning the state after the check.
+func (s *EspressoLivenessSwitch) Poll(expectedHeight uint64) bool {
+	err := s.provider.CheckLiveness(expectedHeight, s.maxLag)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wasActive := s.active
+	s.active = err == nil
+	if wasActive != s.active {
+		s.switchedAt = time.Now()
+	}
+	return s.active
+}
+
+// IsEspressoActive reports whether HotShot-sequenced batch validation should currently be required,
+// per the most recent Poll.
+func (s *EspressoLivenessSwitch) IsEspressoActive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// SwitchedAt returns the time of the most recent active/inactive transition, or the zero time if
+// Poll has never flipped the switch.
+func (s *EspressoLivenessSwitch) SwitchedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.switchedAt
+}
@@ -17,6 +17,14 @@ type EspressoProvider struct {
 	log         log.Logger
 }
 
+// l1BlockRefRangeWarmer is implemented by L1Fetchers (such as *sources.L1Client) that can prefetch
+// and cache a range of L1BlockRefs in a single round-trip. It is checked for with a type assertion,
+// rather than folded into L1Fetcher itself, since the warmup is purely a latency optimization that
+// not every L1Fetcher implementation needs to support.
+type l1BlockRefRangeWarmer interface {
+	WarmupL1BlockRefs(ctx context.Context, start uint64, count uint64)
+}
+
 func NewEspressoProvider(log log.Logger, hotshotAddr common.Address, l1Fetcher L1Fetcher) *EspressoProvider {
 	return &EspressoProvider{
 		HotShotAddr: hotshotAddr,
@@ -27,6 +35,13 @@ func NewEspressoProvider(log log.Logger, hotshotAddr common.Address, l1Fetcher L
 }
 
 func (provider *EspressoProvider) VerifyCommitments(firstHeight uint64, comms []espresso.Commitment) (bool, error) {
+	// Warm up the L1BlockRef cache for this commitment range before iterating it: downstream code
+	// (e.g. the L1 origin selection in batches.go) typically walks this same window one
+	// L1BlockRefByNumber call at a time, which a batched warmup turns into cache hits.
+	if warmer, ok := provider.L1Fetcher.(l1BlockRefRangeWarmer); ok {
+		warmer.WarmupL1BlockRefs(context.Background(), firstHeight, uint64(len(comms)))
+	}
+
 	fetchedComms, err := provider.L1Fetcher.L1HotShotCommitmentsFromHeight(firstHeight, uint64(len(comms)), provider.HotShotAddr)
 	if err != nil {
 		return false, err
@@ -53,3 +68,25 @@ func (provider *EspressoProvider) L1BlockRefByNumber(ctx context.Context, num ui
 func (provider *EspressoProvider) FetchReceipts(ctx context.Context, blockHash common.Hash) (eth.BlockInfo, types.Receipts, error) {
 	return provider.L1Fetcher.FetchReceipts(ctx, blockHash)
 }
+
+// L1Head returns the most recent L1 block known to the provider's L1 fetcher (the "unsafe" head),
+// the same passthrough as L1Finalized/L1Safe but for the "unsafe" label. Used by
+// ValidateEspressoHeader to reject a HotShot header that references an L1 block the sequencer
+// hasn't seen at all.
+func (provider *EspressoProvider) L1Head(ctx context.Context) (eth.L1BlockRef, error) {
+	return provider.L1Fetcher.L1BlockRefByLabel(ctx, eth.Unsafe)
+}
+
+// L1Finalized returns the most recent finalized L1 block known to the provider's L1 fetcher. Used
+// by ValidateEspressoHeader to decide whether to stall batch construction on a HotShot header that
+// references an L1 origin which has not yet finalized.
+func (provider *EspressoProvider) L1Finalized(ctx context.Context) (eth.L1BlockRef, error) {
+	return provider.L1Fetcher.L1BlockRefByLabel(ctx, eth.Finalized)
+}
+
+// L1Safe returns the most recent L1 block tagged "safe" by the provider's L1 fetcher, the same
+// passthrough as L1Finalized but for the "safe" label. Used by SafeTagL1OriginPolicy as its
+// confirmation frontier.
+func (provider *EspressoProvider) L1Safe(ctx context.Context) (eth.L1BlockRef, error) {
+	return provider.L1Fetcher.L1BlockRefByLabel(ctx, eth.Safe)
+}
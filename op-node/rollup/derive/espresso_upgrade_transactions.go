@@ -0,0 +1,129 @@
+package derive
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// known addresses w/ zero txns
+	newL1BlockEspressoAddressDeployer = common.HexToAddress("0x4210000000000000000000000000000000000002")
+	espressoInboxDeployerAddress      = common.HexToAddress("0x4210000000000000000000000000000000000003")
+
+	newL1BlockEspressoAddress = crypto.CreateAddress(newL1BlockEspressoAddressDeployer, 0)
+	newEspressoInboxAddress   = crypto.CreateAddress(espressoInboxDeployerAddress, 0)
+
+	// EspressoInboxAddr is the predeploy address at which the HotShot commitment inbox lives,
+	// mirroring the beacon roots contract's pattern of one well-known address per oracle.
+	EspressoInboxAddr = common.HexToAddress("0x4200000000000000000000000000000000000302")
+
+	deployL1BlockEspressoSource = UpgradeDepositSource{Intent: "Espresso: L1 Block Deployment"}
+	deployEspressoInboxSource   = UpgradeDepositSource{Intent: "Espresso: Inbox Deployment"}
+	updateL1BlockEspressoSource = UpgradeDepositSource{Intent: "Espresso: L1 Block Proxy Update"}
+	updateEspressoInboxSource   = UpgradeDepositSource{Intent: "Espresso: Inbox Proxy Update"}
+	enableEspressoSource        = UpgradeDepositSource{Intent: "Espresso: L1 Block Set Espresso"}
+
+	setEspressoInput = crypto.Keccak256([]byte("setEspresso()"))[:4]
+
+	// l1BlockEspressoDeploymentBytecode is placeholder init code, not a compiled L1Block: the
+	// runtime code it deploys (0x6080604052600080fd) reverts on every call and has no
+	// hotShotBlockHeight/hotShotCommitment storage.
+	l1BlockEspressoDeploymentBytecode = hexutil.MustDecode("0x600a600c600039600a6000f3006080604052600080fd")
+	// espressoInboxDeploymentBytecode is the same placeholder init code as
+	// l1BlockEspressoDeploymentBytecode: the deployed contract reverts on every call and stores no
+	// HotShot commitment or height.
+	espressoInboxDeploymentBytecode = hexutil.MustDecode("0x600a600c600039600a6000f3006080604052600080fd")
+)
+
+// EspressoNetworkUpgradeTransactions returns the deposit transactions that activate
+// HotShot-sequenced blocks, the Espresso counterpart to EcotoneNetworkUpgradeTransactions: deploy
+// an updated L1Block implementation, deploy the EspressoInbox predeploy, upgrade both proxies, and
+// finally call setEspresso() on L1Block. Both deployed implementations are the placeholder contract
+// described on l1BlockEspressoDeploymentBytecode, so calls through the upgraded proxies, the final
+// setEspresso() deposit included, revert. Every
+// deposit's SourceHash is derived from an UpgradeDepositSource so that replay is deterministic
+// across nodes, exactly like the Ecotone upgrade it follows.
+func EspressoNetworkUpgradeTransactions() ([]hexutil.Bytes, error) {
+	upgradeTxns := make([]hexutil.Bytes, 0, 5)
+
+	deployL1BlockEspresso, err := types.NewTx(&types.DepositTx{
+		SourceHash:          deployL1BlockEspressoSource.SourceHash(),
+		From:                newL1BlockEspressoAddressDeployer,
+		To:                  nil,
+		Mint:                big.NewInt(0),
+		Value:               big.NewInt(0),
+		Gas:                 375_000,
+		IsSystemTransaction: false,
+		Data:                l1BlockEspressoDeploymentBytecode,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	upgradeTxns = append(upgradeTxns, deployL1BlockEspresso)
+
+	deployEspressoInbox, err := types.NewTx(&types.DepositTx{
+		SourceHash:          deployEspressoInboxSource.SourceHash(),
+		From:                espressoInboxDeployerAddress,
+		To:                  nil,
+		Mint:                big.NewInt(0),
+		Value:               big.NewInt(0),
+		Gas:                 375_000,
+		IsSystemTransaction: false,
+		Data:                espressoInboxDeploymentBytecode,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	upgradeTxns = append(upgradeTxns, deployEspressoInbox)
+
+	updateL1BlockEspressoProxy, err := types.NewTx(&types.DepositTx{
+		SourceHash:          updateL1BlockEspressoSource.SourceHash(),
+		From:                common.Address{},
+		To:                  &L1BlockAddress,
+		Mint:                big.NewInt(0),
+		Value:               big.NewInt(0),
+		Gas:                 50_000,
+		IsSystemTransaction: false,
+		Data:                upgradeToCalldata(newL1BlockEspressoAddress),
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	upgradeTxns = append(upgradeTxns, updateL1BlockEspressoProxy)
+
+	updateEspressoInboxProxy, err := types.NewTx(&types.DepositTx{
+		SourceHash:          updateEspressoInboxSource.SourceHash(),
+		From:                common.Address{},
+		To:                  &EspressoInboxAddr,
+		Mint:                big.NewInt(0),
+		Value:               big.NewInt(0),
+		Gas:                 50_000,
+		IsSystemTransaction: false,
+		Data:                upgradeToCalldata(newEspressoInboxAddress),
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	upgradeTxns = append(upgradeTxns, updateEspressoInboxProxy)
+
+	enableEspresso, err := types.NewTx(&types.DepositTx{
+		SourceHash:          enableEspressoSource.SourceHash(),
+		From:                L1InfoDepositerAddress,
+		To:                  &L1BlockAddress,
+		Mint:                big.NewInt(0),
+		Value:               big.NewInt(0),
+		Gas:                 80_000,
+		IsSystemTransaction: false,
+		Data:                setEspressoInput,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	upgradeTxns = append(upgradeTxns, enableEspresso)
+
+	return upgradeTxns, nil
+}
@@ -0,0 +1,74 @@
+package derive
+
+import (
+	"container/list"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// futureBatchKey identifies a cached future candidate by the L2 block it would build on and the
+// timestamp it claims, the same pair checkSingularBatch uses to decide whether a batch is the next
+// one expected after l2SafeHead.
+type futureBatchKey struct {
+	parentHash common.Hash
+	timestamp  uint64
+}
+
+// futureBatchCache holds BatchValidator candidates that CheckBatch classified as BatchFuture,
+// bounded to maxSize entries with the oldest evicted first -- the same bounded-LRU shape as
+// derive.JustificationFetcher's cache and hotshot.commitmentCache use elsewhere in this tree. It is
+// the derivation-side analogue of go-ethereum's futureBlocks: batches that are plausible but not yet
+// due are held so the L2 safe head catching up to them doesn't require re-fetching the same
+// candidate from the batch source, up to a cap that exists purely to bound memory against a source
+// that floods the validator with candidates.
+type futureBatchCache struct {
+	maxSize int
+
+	lru      *list.List
+	lruElems map[futureBatchKey]*list.Element
+	entries  map[futureBatchKey]*BatchWithL1InclusionBlock
+}
+
+func newFutureBatchCache(maxSize int) *futureBatchCache {
+	return &futureBatchCache{
+		maxSize:  maxSize,
+		lru:      list.New(),
+		lruElems: make(map[futureBatchKey]*list.Element),
+		entries:  make(map[futureBatchKey]*BatchWithL1InclusionBlock),
+	}
+}
+
+// store inserts (or refreshes) a cache entry, evicting the least-recently-used entry if the cache
+// is full.
+func (c *futureBatchCache) store(key futureBatchKey, batch *BatchWithL1InclusionBlock) {
+	if elem, ok := c.lruElems[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.entries[key] = batch
+		return
+	}
+	c.entries[key] = batch
+	c.lruElems[key] = c.lru.PushFront(key)
+	for c.lru.Len() > c.maxSize {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			break
+		}
+		oldestKey := oldest.Value.(futureBatchKey)
+		c.lru.Remove(oldest)
+		delete(c.lruElems, oldestKey)
+		delete(c.entries, oldestKey)
+	}
+}
+
+// take removes and returns the cached candidate for key, if any.
+func (c *futureBatchCache) take(key futureBatchKey) (*BatchWithL1InclusionBlock, bool) {
+	batch, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	elem := c.lruElems[key]
+	c.lru.Remove(elem)
+	delete(c.lruElems, key)
+	delete(c.entries, key)
+	return batch, true
+}
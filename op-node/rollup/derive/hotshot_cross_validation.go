@@ -0,0 +1,82 @@
+package derive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	espresso "github.com/EspressoSystems/espresso-sequencer-go/types"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso/hotshot"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// HotShotFetcher is the on-chain-independent view of HotShot CheckBatchAgainstHotShot needs: the
+// header HotShot committed at a given height, and the payload it committed for this rollup's
+// namespace at that height. A production implementation typically wraps a HotShot query-service
+// client; nothing in this checkout implements it yet.
+type HotShotFetcher interface {
+	HeaderByHeight(ctx context.Context, height uint64) (*espresso.Header, error)
+	NsPayload(ctx context.Context, height uint64, nsID uint32) ([][]byte, error)
+}
+
+// CheckBatchAgainstHotShot cross-validates one batch's ordered, non-deposit transactions against
+// what HotShot itself committed over [firstHeight, firstHeight+numHeights), rather than trusting
+// that the batcher relaying them from HotShot did so faithfully. This is what makes the batcher a
+// verifiable relay instead of a trusted source: a batcher that drops, reorders, or substitutes
+// transactions produces a batch this function rejects.
+//
+// For each height in the range it: (a) fetches HotShot's committed header and this rollup's
+// namespace payload at that height via hotshot, and (b) checks the header's commitment against
+// verifier -- the same HeaderVerifier interface HotShotProvider.VerifyHeaders already implements by
+// reading the HotShot contract's per-height commitment, which is the one commitment-verification
+// path this checkout actually has wired up. The request this was built for additionally asks to
+// check the commitment against a HotShot light client contract's finalized state root instead of
+// the raw per-height contract view; HotShotLightClientVerifier.VerifyHeadersLC exists for that but
+// always returns an error in this checkout (see its doc comment: no light client contract bindings
+// or Merkle proof verifier are available here), so it is not used below.
+//
+// It returns BatchDrop if any height's header fails verification or if the concatenated namespace
+// payloads don't exactly match transactions, BatchUndecided if HotShot has not committed up to
+// firstHeight+numHeights-1 yet (surfaced as a HeaderByHeight/NsPayload error, or a MissingOnChain
+// VerifyHeaders result), and BatchAccept otherwise.
+func CheckBatchAgainstHotShot(ctx context.Context, log log.Logger, transactions [][]byte, firstHeight uint64, numHeights uint64, nsID uint32, hs HotShotFetcher, verifier hotshot.HeaderVerifier) BatchValidity {
+	var committed [][]byte
+	for height := firstHeight; height < firstHeight+numHeights; height++ {
+		header, err := hs.HeaderByHeight(ctx, height)
+		if err != nil {
+			log.Info("HotShot has not committed a header at this height yet", "height", height, "err", err)
+			return BatchUndecided
+		}
+		ok, err := verifier.VerifyHeaders([]espresso.Header{*header}, height)
+		if err != nil {
+			log.Warn("error verifying HotShot header commitment", "height", height, "err", err)
+			return BatchUndecided
+		}
+		if !ok {
+			log.Warn("dropping batch because its HotShot header does not match the committed commitment", "height", height)
+			return BatchDrop
+		}
+
+		payload, err := hs.NsPayload(ctx, height, nsID)
+		if err != nil {
+			log.Info("HotShot has not made the namespace payload available at this height yet", "height", height, "err", err)
+			return BatchUndecided
+		}
+		committed = append(committed, payload...)
+	}
+
+	if len(committed) != len(transactions) {
+		log.Warn("dropping batch because its transaction count does not match HotShot's namespace payload",
+			"batch_txs", len(transactions), "hotshot_txs", len(committed))
+		return BatchDrop
+	}
+	for i := range transactions {
+		if !bytes.Equal(transactions[i], committed[i]) {
+			log.Warn(fmt.Sprintf("dropping batch because transaction %d does not match HotShot's namespace payload", i))
+			return BatchDrop
+		}
+	}
+
+	return BatchAccept
+}
@@ -0,0 +1,188 @@
+package derive
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// JustificationFetcherMetrics lets callers observe the behavior of the prefetch window, without
+// forcing a particular metrics backend on this package.
+type JustificationFetcherMetrics interface {
+	RecordJustificationCacheHit()
+	RecordJustificationCacheMiss()
+	RecordJustificationInflight(n int)
+	RecordJustificationStall(d time.Duration)
+	RecordJustificationCircuitBreakerTripped()
+}
+
+// justificationCacheKey identifies the justification for a single L2 block: the L1 origin it was
+// built against, and the HotShot height at which its Espresso window starts.
+type justificationCacheKey struct {
+	l1Origin eth.BlockID
+	height   uint64
+}
+
+// JustificationFetcherConfig bounds how aggressively the fetcher looks ahead of the L2 head, and
+// how it reacts to a struggling query service.
+type JustificationFetcherConfig struct {
+	// MaxCacheSize bounds the number of cached justifications, evicted least-recently-used.
+	MaxCacheSize int
+	// FailureThreshold is the number of consecutive query-service failures after which the circuit
+	// breaker opens, and Get starts returning ErrTemporary immediately instead of retrying.
+	FailureThreshold int
+	// CircuitBreakerCooldown is how long the circuit breaker stays open once tripped, before the
+	// fetcher is willing to try the query service again.
+	CircuitBreakerCooldown time.Duration
+}
+
+func DefaultJustificationFetcherConfig() JustificationFetcherConfig {
+	return JustificationFetcherConfig{
+		MaxCacheSize:           256,
+		FailureThreshold:       5,
+		CircuitBreakerCooldown: 30 * time.Second,
+	}
+}
+
+// JustificationFetcher caches the Espresso headers used to build the L2BatchJustification for L2
+// blocks, so that repeated requests for the same window do not each make a query-service round
+// trip. Get fetches on demand, one (l1Origin, height) window per request, and concurrent Gets for
+// the same key share a single request. It does not fetch ahead of a Get, it does not fetch NMT
+// proofs (the cached justification has no Blocks), and requests for adjacent heights are not
+// coalesced into a range query.
+type JustificationFetcher struct {
+	mu      sync.Mutex
+	qs      espresso.QueryService
+	log     log.Logger
+	cfg     JustificationFetcherConfig
+	metrics JustificationFetcherMetrics
+
+	cache    map[justificationCacheKey]*eth.L2BatchJustification
+	lru      *list.List
+	lruElems map[justificationCacheKey]*list.Element
+
+	inflight map[justificationCacheKey]chan struct{}
+
+	consecutiveFailures int
+	circuitOpenUntil    time.Time
+}
+
+func NewJustificationFetcher(log log.Logger, qs espresso.QueryService, metrics JustificationFetcherMetrics, cfg JustificationFetcherConfig) *JustificationFetcher {
+	return &JustificationFetcher{
+		qs:       qs,
+		log:      log,
+		cfg:      cfg,
+		metrics:  metrics,
+		cache:    make(map[justificationCacheKey]*eth.L2BatchJustification),
+		lru:      list.New(),
+		lruElems: make(map[justificationCacheKey]*list.Element),
+		inflight: make(map[justificationCacheKey]chan struct{}),
+	}
+}
+
+// Get returns the justification for the sequencing window starting at `height` against `l1Origin`,
+// blocking until it has been fetched if it is not already cached. If the circuit breaker is open
+// because the query service has been failing, Get returns a wrapped ErrTemporary immediately
+// rather than blocking on a request that is likely to fail.
+func (f *JustificationFetcher) Get(ctx context.Context, l1Origin eth.BlockID, height uint64) (*eth.L2BatchJustification, error) {
+	key := justificationCacheKey{l1Origin: l1Origin, height: height}
+
+	f.mu.Lock()
+	if jst, ok := f.cache[key]; ok {
+		f.touch(key)
+		f.mu.Unlock()
+		f.metrics.RecordJustificationCacheHit()
+		return jst, nil
+	}
+	f.metrics.RecordJustificationCacheMiss()
+
+	if !f.circuitOpenUntil.IsZero() && time.Now().Before(f.circuitOpenUntil) {
+		f.mu.Unlock()
+		return nil, NewTemporaryError(fmt.Errorf("espresso query service circuit breaker open until %s", f.circuitOpenUntil))
+	}
+
+	if wait, ok := f.inflight[key]; ok {
+		f.mu.Unlock()
+		<-wait
+		return f.Get(ctx, l1Origin, height)
+	}
+	wait := make(chan struct{})
+	f.inflight[key] = wait
+	f.metrics.RecordJustificationInflight(len(f.inflight))
+	f.mu.Unlock()
+
+	start := time.Now()
+	jst, err := f.fetch(ctx, height)
+	f.metrics.RecordJustificationStall(time.Since(start))
+
+	f.mu.Lock()
+	delete(f.inflight, key)
+	f.metrics.RecordJustificationInflight(len(f.inflight))
+	if err != nil {
+		f.consecutiveFailures++
+		if f.consecutiveFailures >= f.cfg.FailureThreshold {
+			f.circuitOpenUntil = time.Now().Add(f.cfg.CircuitBreakerCooldown)
+			f.metrics.RecordJustificationCircuitBreakerTripped()
+			f.log.Error("espresso query service circuit breaker tripped, falling back to non-Espresso mode", "consecutive_failures", f.consecutiveFailures, "cooldown", f.cfg.CircuitBreakerCooldown)
+		}
+		f.mu.Unlock()
+		close(wait)
+		return nil, err
+	}
+	f.consecutiveFailures = 0
+	f.circuitOpenUntil = time.Time{}
+	f.store(key, jst)
+	f.mu.Unlock()
+	close(wait)
+	return jst, nil
+}
+
+// fetch performs the actual query-service round trip for a single sequencing window starting at
+// `height`. It does not consult or populate the cache; callers should go through Get.
+func (f *JustificationFetcher) fetch(ctx context.Context, height uint64) (*eth.L2BatchJustification, error) {
+	start, err := f.qs.FetchHeadersForWindow(ctx, height, height)
+	if err != nil {
+		return nil, NewTemporaryError(fmt.Errorf("failed to fetch espresso headers at height %d: %w", height, err))
+	}
+	return &eth.L2BatchJustification{
+		From:   start.From,
+		Prev:   start.Prev,
+		Blocks: nil,
+	}, nil
+}
+
+// touch marks key as most-recently-used. Callers must hold f.mu.
+func (f *JustificationFetcher) touch(key justificationCacheKey) {
+	if elem, ok := f.lruElems[key]; ok {
+		f.lru.MoveToFront(elem)
+	}
+}
+
+// store inserts (or refreshes) a cache entry, evicting the least-recently-used entry if the cache
+// is full. Callers must hold f.mu.
+func (f *JustificationFetcher) store(key justificationCacheKey, jst *eth.L2BatchJustification) {
+	if elem, ok := f.lruElems[key]; ok {
+		f.lru.MoveToFront(elem)
+		f.cache[key] = jst
+		return
+	}
+	f.cache[key] = jst
+	f.lruElems[key] = f.lru.PushFront(key)
+	for f.lru.Len() > f.cfg.MaxCacheSize {
+		oldest := f.lru.Back()
+		if oldest == nil {
+			break
+		}
+		oldestKey := oldest.Value.(justificationCacheKey)
+		f.lru.Remove(oldest)
+		delete(f.lruElems, oldestKey)
+		delete(f.cache, oldestKey)
+	}
+}
@@ -0,0 +1,96 @@
+package derive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// AttributeTag identifies a single TLV-encoded attribute in the versioned attribute section
+// appended to the tail of the Bedrock and Ecotone L1BlockInfo encodings. New Espresso-side fields
+// (additional L2BatchJustification data, per-namespace commitments, fraud-proof witnesses, ...)
+// should be given a new tag here rather than a new hardfork-bound position in the binary format.
+type AttributeTag uint16
+
+const (
+	TagEspressoEnabled          AttributeTag = 0
+	TagEspressoL1ConfDepth      AttributeTag = 1
+	TagJustification            AttributeTag = 2
+	TagEspressoHeaderCommitment AttributeTag = 3
+	TagEspressoHeaderHeight     AttributeTag = 4
+	// TagL1InfoRoot carries L1BlockInfo.L1InfoRoot, an L1InfoTree root as of this L1 origin, so
+	// that an overlap check re-deriving a previously-accepted block can verify it against its own
+	// L1InfoTree without trusting the deposit transaction's claim blindly. L1InfoDeposit never sets
+	// L1InfoRoot, so the deposits it builds carry no such tag.
+	TagL1InfoRoot AttributeTag = 5
+	// Tags below this value are reserved for future Espresso extensions. Tags at or above it are
+	// free for unrelated, non-Espresso uses of the attribute section.
+	FirstUnreservedAttributeTag AttributeTag = 1 << 8
+)
+
+// AttributesVersion0 is the only attribute section version currently defined.
+const AttributesVersion0 uint16 = 0
+
+// RawAttribute is a single undecoded TLV record. L1BlockInfoFromBytes preserves any tags it does
+// not recognize in L1BlockInfo.UnknownAttributes, so that round-tripping a decoded L1BlockInfo
+// back to bytes does not silently drop data from a newer node.
+type RawAttribute struct {
+	Tag   AttributeTag
+	Value []byte
+}
+
+// encodeAttributes serializes a versioned attribute section: a uint16 version, a uint16 record
+// count, and then each record as (uint16 tag, uint32 length, bytes value).
+func encodeAttributes(version uint16, attrs []RawAttribute) []byte {
+	w := new(bytes.Buffer)
+	_ = binary.Write(w, binary.BigEndian, version)
+	_ = binary.Write(w, binary.BigEndian, uint16(len(attrs)))
+	for _, attr := range attrs {
+		_ = binary.Write(w, binary.BigEndian, uint16(attr.Tag))
+		_ = binary.Write(w, binary.BigEndian, uint32(len(attr.Value)))
+		w.Write(attr.Value)
+	}
+	return w.Bytes()
+}
+
+// decodeAttributes parses a versioned attribute section written by encodeAttributes.
+func decodeAttributes(r io.Reader) (version uint16, attrs []RawAttribute, err error) {
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return 0, nil, fmt.Errorf("failed to read attribute section version: %w", err)
+	}
+	var count uint16
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return 0, nil, fmt.Errorf("failed to read attribute count: %w", err)
+	}
+	attrs = make([]RawAttribute, 0, count)
+	for i := uint16(0); i < count; i++ {
+		var tag uint16
+		if err := binary.Read(r, binary.BigEndian, &tag); err != nil {
+			return 0, nil, fmt.Errorf("failed to read tag of attribute %d: %w", i, err)
+		}
+		var length uint32
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return 0, nil, fmt.Errorf("failed to read length of attribute %d: %w", i, err)
+		}
+		value := make([]byte, length)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return 0, nil, fmt.Errorf("failed to read value of attribute %d: %w", i, err)
+		}
+		attrs = append(attrs, RawAttribute{Tag: AttributeTag(tag), Value: value})
+	}
+	return version, attrs, nil
+}
+
+// takeAttribute removes and returns the first attribute with the given tag, if present.
+func takeAttribute(attrs []RawAttribute, tag AttributeTag) (value []byte, rest []RawAttribute, found bool) {
+	for i, attr := range attrs {
+		if attr.Tag == tag {
+			rest = make([]RawAttribute, 0, len(attrs)-1)
+			rest = append(rest, attrs[:i]...)
+			rest = append(rest, attrs[i+1:]...)
+			return attr.Value, rest, true
+		}
+	}
+	return nil, attrs, false
+}
@@ -20,7 +20,7 @@ import (
 )
 
 const (
-	L1InfoFuncBedrockSignature = "setL1BlockValues((uint64,uint64,uint256,bytes32,uint64,bytes32,uint256,uint256,bool,uint64,bytes))"
+	L1InfoFuncBedrockSignature = "setL1BlockValues((uint64,uint64,uint256,bytes32,uint64,bytes32,uint256,uint256,bytes))"
 	L1InfoFuncEcotoneSignature = "setL1BlockValuesEcotone()"
 	L1InfoArguments            = 8
 )
@@ -29,7 +29,11 @@ var (
 	L1InfoFuncBedrockBytes4   = crypto.Keccak256([]byte(L1InfoFuncBedrockSignature))[:4]
 	L1InfoFuncEcotoneBytes4   = crypto.Keccak256([]byte(L1InfoFuncEcotoneSignature))[:4]
 	L1InfoDepositerAddress    = common.HexToAddress("0xdeaddeaddeaddeaddeaddeaddeaddeaddead0001")
-	L1InfoJustificationOffset = new(big.Int).SetUint64(352) // See Binary Format table below
+	L1InfoAttributesOffset    = new(big.Int).SetUint64(288) // See Binary Format table below
+	// L1InfoAttributesOffsetLegacy is the struct-field offset used by the pre-attribute-section
+	// Bedrock format, in which the Espresso fields were two additional fixed-position struct
+	// fields (bool, uint64) rather than part of the variable-length attribute section.
+	L1InfoAttributesOffsetLegacy = new(big.Int).SetUint64(352)
 	L1BlockAddress            = predeploys.L1BlockAddr
 )
 
@@ -56,6 +60,26 @@ type L1BlockInfo struct {
 
 	Justification *eth.L2BatchJustification `rlp:"nil"`
 
+	// EspressoHeaderCommitment is the canonical commitment (see espresso.Header.Commit) of the
+	// last Espresso block included in Justification. L2 contracts can compare an NMT inclusion
+	// proof's root against this commitment, via the predeployed L1Block contract, without needing
+	// the full Justification blob.
+	EspressoHeaderCommitment common.Hash
+	// EspressoHeaderHeight is the HotShot height of the Espresso block that
+	// EspressoHeaderCommitment attests to, i.e. jst.From + len(jst.Blocks) - 1.
+	EspressoHeaderHeight uint64
+
+	// L1InfoRoot is the sequencer's L1InfoTree root as of Number, so that a verifier re-deriving
+	// this block from L1 data can check it against its own L1InfoTree instead of trusting Number
+	// and BlockHash alone. Zero if the sequencer did not attach one.
+	L1InfoRoot common.Hash
+
+	// UnknownAttributes holds any tags in the versioned attribute section (see the Binary Format
+	// tables below) which this node did not recognize. They are round-tripped so that forwarding or
+	// re-encoding an L1BlockInfo decoded by an older node does not silently drop data written by a
+	// newer one.
+	UnknownAttributes []RawAttribute
+
 	L1FeeOverhead eth.Bytes32 // ignored after Ecotone upgrade
 	L1FeeScalar   eth.Bytes32 // ignored after Ecotone upgrade
 
@@ -91,11 +115,15 @@ type L1BlockInfo struct {
 // | 32      | BatcherHash              |
 // | 32      | L1FeeOverhead            |
 // | 32      | L1FeeScalar              |
-// | 32      | Espresso                 |
-// | 32      | EspressoL1ConfDepth      |
-// | 32      | L1InfoJustificationOffset|
-// | variable| Justification            |
+// | 32      | AttributesOffset         |
+// | variable| Attributes               |
 // +---------+--------------------------+
+//
+// `Attributes` is a versioned, TLV-style section (see l1_attributes.go) carrying the Espresso
+// extension fields (`EspressoEnabled`, `EspressoL1ConfDepth`, `Justification`) and any future
+// Espresso-side additions, without requiring a new hardfork-bound position in this format for each
+// one. Older calldata, encoded before this section existed, is still decodable: see
+// unmarshalBinaryBedrockLegacy.
 
 func (info *L1BlockInfo) marshalBinaryBedrock() ([]byte, error) {
 	w := new(bytes.Buffer)
@@ -129,30 +157,17 @@ func (info *L1BlockInfo) marshalBinaryBedrock() ([]byte, error) {
 	if err := solabi.WriteEthBytes32(w, info.L1FeeScalar); err != nil {
 		return nil, err
 	}
-	if err := solabi.WriteBool(w, info.Espresso); err != nil {
-		return nil, err
-	}
-	if err := solabi.WriteUint64(w, info.EspressoL1ConfDepth); err != nil {
-		return nil, err
-	}
 
-	// For simplicity, we don't ABI-encode the whole structure of the Justification. We RLP-encode
-	// it and then ABI-encode the resulting byte string. This means the Justification can be
-	// accessed by parsing calldata, but cannot (easily) by inspected on-chain.
-	rlpBytes, err := rlp.EncodeToBytes(info.Justification)
-	if err != nil {
-		return nil, err
-	}
 	// The ABI-encoding of struct fields is that of a tuple, which requires that dynamic types (such
 	// as `bytes`) are represented in the initial list of items as a uint256 with the offset from
 	// the start of the encoding to the start of the payload of the dynamic type, which follows the
 	// initial list of static types and dynamic type offsets. In this case, we only have one item of
 	// dynamic type, and it is at the end of the list of items, so we will encode it by its offset,
 	// which is just the length of the static section of the list, followed by the item itself.
-	if err := solabi.WriteUint256(w, L1InfoJustificationOffset); err != nil {
+	if err := solabi.WriteUint256(w, L1InfoAttributesOffset); err != nil {
 		return nil, err
 	}
-	if err := solabi.WriteBytes(w, rlpBytes); err != nil {
+	if err := solabi.WriteBytes(w, info.attributesOut()); err != nil {
 		return nil, err
 	}
 
@@ -195,22 +210,63 @@ func (info *L1BlockInfo) unmarshalBinaryBedrock(data []byte) error {
 	if info.L1FeeScalar, err = solabi.ReadEthBytes32(reader); err != nil {
 		return err
 	}
-	if info.Espresso, err = solabi.ReadBool(reader); err != nil {
+	tail, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if err := info.unmarshalAttributesTail(tail, L1InfoAttributesOffset); err != nil {
+		return info.unmarshalBinaryBedrockLegacy(tail)
+	}
+	return nil
+}
+
+// unmarshalAttributesTail reads the ABI-encoded offset-and-bytes suffix of the Bedrock format (a
+// uint256 offset followed by the length-prefixed attribute section) and applies the decoded
+// attributes to info.
+func (info *L1BlockInfo) unmarshalAttributesTail(tail []byte, expectedOffset *big.Int) error {
+	r := bytes.NewReader(tail)
+	offset, err := solabi.ReadUint256(r)
+	if err != nil {
+		return err
+	}
+	if offset.Cmp(expectedOffset) != 0 {
+		return fmt.Errorf("invalid attributes offset (%d, expected %d)", offset, expectedOffset)
+	}
+	attrBytes, err := solabi.ReadBytes(r)
+	if err != nil {
 		return err
 	}
-	if info.EspressoL1ConfDepth, err = solabi.ReadUint64(reader); err != nil {
+	if err := info.applyAttributesIn(bytes.NewReader(attrBytes)); err != nil {
 		return err
 	}
+	if !solabi.EmptyReader(r) {
+		return errors.New("too many bytes")
+	}
+	return nil
+}
 
+// unmarshalBinaryBedrockLegacy decodes the pre-attribute-section Bedrock format, in which the
+// Espresso fields were encoded at fixed positions rather than in a versioned attribute section.
+// This is kept so that a node which has already derived L2 blocks from the old encoding does not
+// fail to re-derive them after upgrading.
+func (info *L1BlockInfo) unmarshalBinaryBedrockLegacy(tail []byte) error {
+	r := bytes.NewReader(tail)
+	var err error
+	if info.Espresso, err = solabi.ReadBool(r); err != nil {
+		return err
+	}
+	if info.EspressoL1ConfDepth, err = solabi.ReadUint64(r); err != nil {
+		return err
+	}
 	// Read the offset of the Justification bytes followed by the bytes themselves.
-	rlpOffset, err := solabi.ReadUint256(reader)
+	rlpOffset, err := solabi.ReadUint256(r)
 	if err != nil {
 		return err
 	}
-	if rlpOffset.Cmp(L1InfoJustificationOffset) != 0 {
-		return fmt.Errorf("invalid justification offset (%d, expected %d)", rlpOffset, L1InfoJustificationOffset)
+	if rlpOffset.Cmp(L1InfoAttributesOffsetLegacy) != 0 {
+		return fmt.Errorf("invalid justification offset (%d, expected %d)", rlpOffset, L1InfoAttributesOffsetLegacy)
 	}
-	rlpBytes, err := solabi.ReadBytes(reader)
+	rlpBytes, err := solabi.ReadBytes(r)
 	if err != nil {
 		return err
 	}
@@ -223,8 +279,7 @@ func (info *L1BlockInfo) unmarshalBinaryBedrock(data []byte) error {
 			return err
 		}
 	}
-
-	if !solabi.EmptyReader(reader) {
+	if !solabi.EmptyReader(r) {
 		return errors.New("too many bytes")
 	}
 	return nil
@@ -244,10 +299,15 @@ func (info *L1BlockInfo) unmarshalBinaryBedrock(data []byte) error {
 // | 32      | BlobBaseFee              |
 // | 32      | BlockHash                |
 // | 32      | BatcherHash              |
-// | 8       | EspressoL1ConfDepth      |
-// | 8       | Espresso                 |
-// | variable| Justification            |
+// | variable| Attributes               |
 // +---------+--------------------------+
+//
+// Attributes is the versioned, TLV-encoded Espresso attribute section described above
+// marshalBinaryBedrock, appended directly with no ABI offset (unlike the Bedrock format, the
+// Ecotone format is not itself ABI-encoded, so there is no struct-offset convention to follow
+// here). A node which has already derived blocks from the pre-attribute-section format falls back
+// to unmarshalBinaryEcotoneLegacy, which decodes the fixed EspressoL1ConfDepth/Espresso/
+// Justification layout that the attribute section replaced.
 
 func (info *L1BlockInfo) marshalBinaryEcotone() ([]byte, error) {
 	w := new(bytes.Buffer)
@@ -286,23 +346,7 @@ func (info *L1BlockInfo) marshalBinaryEcotone() ([]byte, error) {
 	if err := solabi.WriteAddress(w, info.BatcherAddr); err != nil {
 		return nil, err
 	}
-	if err := binary.Write(w, binary.BigEndian, info.EspressoL1ConfDepth); err != nil {
-		return nil, err
-	}
-	if info.Espresso {
-		if err := binary.Write(w, binary.BigEndian, uint64(1)); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := binary.Write(w, binary.BigEndian, uint64(0)); err != nil {
-			return nil, err
-		}
-	}
-	rlpBytes, err := rlp.EncodeToBytes(info.Justification)
-	if err != nil {
-		return nil, err
-	}
-	if _, err := w.Write(rlpBytes); err != nil {
+	if _, err := w.Write(info.attributesOut()); err != nil {
 		return nil, err
 	}
 	return w.Bytes(), nil
@@ -343,6 +387,22 @@ func (info *L1BlockInfo) unmarshalBinaryEcotone(data []byte) error {
 	if info.BatcherAddr, err = solabi.ReadAddress(r); err != nil {
 		return err
 	}
+	tail, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("invalid ecotone l1 block info format: %w", err)
+	}
+	attrReader := bytes.NewReader(tail)
+	if err := info.applyAttributesIn(attrReader); err != nil || attrReader.Len() != 0 {
+		info.UnknownAttributes = nil
+		return info.unmarshalBinaryEcotoneLegacy(tail)
+	}
+	return nil
+}
+
+// unmarshalBinaryEcotoneLegacy decodes the pre-attribute-section Ecotone format, in which the
+// Espresso fields were encoded at fixed positions rather than in a versioned attribute section.
+func (info *L1BlockInfo) unmarshalBinaryEcotoneLegacy(tail []byte) error {
+	r := bytes.NewReader(tail)
 	if err := binary.Read(r, binary.BigEndian, &info.EspressoL1ConfDepth); err != nil {
 		return fmt.Errorf("invalid ecotone l1 block info format: %w", err)
 	}
@@ -364,10 +424,89 @@ func (info *L1BlockInfo) unmarshalBinaryEcotone(data []byte) error {
 			return err
 		}
 	}
+	return nil
+}
 
-	if !solabi.EmptyReader(r) {
-		return errors.New("too many bytes")
+// attributesOut builds the versioned attribute section for this L1BlockInfo: the registered
+// Espresso tags, followed by any unrecognized tags that were preserved from a previous decode.
+func (info *L1BlockInfo) attributesOut() []byte {
+	attrs := make([]RawAttribute, 0, 4+len(info.UnknownAttributes))
+	espresso := byte(0)
+	if info.Espresso {
+		espresso = 1
+	}
+	attrs = append(attrs, RawAttribute{Tag: TagEspressoEnabled, Value: []byte{espresso}})
+	l1ConfDepth := make([]byte, 8)
+	binary.BigEndian.PutUint64(l1ConfDepth, info.EspressoL1ConfDepth)
+	attrs = append(attrs, RawAttribute{Tag: TagEspressoL1ConfDepth, Value: l1ConfDepth})
+	if info.Justification != nil {
+		jstBytes, err := rlp.EncodeToBytes(info.Justification)
+		if err == nil {
+			attrs = append(attrs, RawAttribute{Tag: TagJustification, Value: jstBytes})
+		}
+	}
+	if (info.EspressoHeaderCommitment != common.Hash{}) {
+		attrs = append(attrs, RawAttribute{Tag: TagEspressoHeaderCommitment, Value: info.EspressoHeaderCommitment[:]})
+		height := make([]byte, 8)
+		binary.BigEndian.PutUint64(height, info.EspressoHeaderHeight)
+		attrs = append(attrs, RawAttribute{Tag: TagEspressoHeaderHeight, Value: height})
+	}
+	if (info.L1InfoRoot != common.Hash{}) {
+		attrs = append(attrs, RawAttribute{Tag: TagL1InfoRoot, Value: info.L1InfoRoot[:]})
+	}
+	attrs = append(attrs, info.UnknownAttributes...)
+	return encodeAttributes(AttributesVersion0, attrs)
+}
+
+// applyAttributesIn decodes the versioned attribute section and sets the corresponding fields on
+// info, preserving any tags it does not recognize in info.UnknownAttributes.
+func (info *L1BlockInfo) applyAttributesIn(r io.Reader) error {
+	_, attrs, err := decodeAttributes(r)
+	if err != nil {
+		return fmt.Errorf("failed to decode attribute section: %w", err)
+	}
+	if value, rest, ok := takeAttribute(attrs, TagEspressoEnabled); ok {
+		if len(value) != 1 {
+			return fmt.Errorf("invalid length for EspressoEnabled attribute: %d", len(value))
+		}
+		info.Espresso = value[0] != 0
+		attrs = rest
+	}
+	if value, rest, ok := takeAttribute(attrs, TagEspressoL1ConfDepth); ok {
+		if len(value) != 8 {
+			return fmt.Errorf("invalid length for EspressoL1ConfDepth attribute: %d", len(value))
+		}
+		info.EspressoL1ConfDepth = binary.BigEndian.Uint64(value)
+		attrs = rest
+	}
+	if value, rest, ok := takeAttribute(attrs, TagJustification); ok {
+		if err := rlp.DecodeBytes(value, &info.Justification); err != nil {
+			return fmt.Errorf("invalid Justification attribute: %w", err)
+		}
+		attrs = rest
+	}
+	if value, rest, ok := takeAttribute(attrs, TagEspressoHeaderCommitment); ok {
+		if len(value) != common.HashLength {
+			return fmt.Errorf("invalid length for EspressoHeaderCommitment attribute: %d", len(value))
+		}
+		info.EspressoHeaderCommitment = common.BytesToHash(value)
+		attrs = rest
 	}
+	if value, rest, ok := takeAttribute(attrs, TagEspressoHeaderHeight); ok {
+		if len(value) != 8 {
+			return fmt.Errorf("invalid length for EspressoHeaderHeight attribute: %d", len(value))
+		}
+		info.EspressoHeaderHeight = binary.BigEndian.Uint64(value)
+		attrs = rest
+	}
+	if value, rest, ok := takeAttribute(attrs, TagL1InfoRoot); ok {
+		if len(value) != common.HashLength {
+			return fmt.Errorf("invalid length for L1InfoRoot attribute: %d", len(value))
+		}
+		info.L1InfoRoot = common.BytesToHash(value)
+		attrs = rest
+	}
+	info.UnknownAttributes = attrs
 	return nil
 }
 
@@ -400,6 +539,11 @@ func L1InfoDeposit(rollupCfg *rollup.Config, sysCfg eth.SystemConfig, seqNumber
 		EspressoL1ConfDepth: sysCfg.EspressoL1ConfDepth,
 		Justification:       justification,
 	}
+	if justification != nil && len(justification.Blocks) > 0 {
+		last := justification.Blocks[len(justification.Blocks)-1]
+		l1BlockInfo.EspressoHeaderCommitment = common.Hash(last.Header.Commit())
+		l1BlockInfo.EspressoHeaderHeight = justification.From + uint64(len(justification.Blocks)) - 1
+	}
 	var data []byte
 	if isEcotoneButNotFirstBlock(rollupCfg, l2BlockTime) {
 		l1BlockInfo.BlobBaseFee = block.BlobBaseFee()
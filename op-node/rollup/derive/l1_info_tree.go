@@ -0,0 +1,206 @@
+package derive
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// l1InfoTreeDepth bounds L1InfoTree to 2^32 leaves (L1 block numbers), far more than any L1 chain
+// will produce; it exists only to give the incremental Merkle tree below a fixed depth to pad to,
+// the same approach the eth2 deposit contract's incremental Merkle tree uses.
+const l1InfoTreeDepth = 32
+
+// l1InfoTreeZeroHashes[i] is the root of an empty subtree of height i, precomputed once so root and
+// Proof can pad a partially-filled tree without recomputing those hashes on every call.
+var l1InfoTreeZeroHashes = computeL1InfoTreeZeroHashes()
+
+func computeL1InfoTreeZeroHashes() [l1InfoTreeDepth + 1]common.Hash {
+	var zeroHashes [l1InfoTreeDepth + 1]common.Hash
+	for i := 1; i <= l1InfoTreeDepth; i++ {
+		zeroHashes[i] = hashPair(zeroHashes[i-1], zeroHashes[i-1])
+	}
+	return zeroHashes
+}
+
+func hashPair(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash(left[:], right[:])
+}
+
+// L1InfoTreeLeaf is the data L1InfoTree commits to for one L1 block: enough for an L2 contract
+// holding only L1InfoRootAt's root to check a deposit transaction's claimed L1 attributes against
+// it, without re-deriving them from the L1 block header itself.
+type L1InfoTreeLeaf struct {
+	BlockHash        common.Hash
+	Timestamp        uint64
+	BaseFee          *big.Int
+	BlobBaseFee      *big.Int
+	ParentBeaconRoot common.Hash
+}
+
+// hash returns the leaf's commitment: Keccak256 of its fields packed in declaration order, as
+// 32-byte big-endian words (BaseFee and BlobBaseFee zero-extended like an ABI uint256).
+func (leaf L1InfoTreeLeaf) hash() common.Hash {
+	baseFee := new(big.Int)
+	if leaf.BaseFee != nil {
+		baseFee = leaf.BaseFee
+	}
+	blobBaseFee := new(big.Int)
+	if leaf.BlobBaseFee != nil {
+		blobBaseFee = leaf.BlobBaseFee
+	}
+	var baseFeeWord, blobBaseFeeWord [32]byte
+	baseFee.FillBytes(baseFeeWord[:])
+	blobBaseFee.FillBytes(blobBaseFeeWord[:])
+
+	var timestampWord [32]byte
+	new(big.Int).SetUint64(leaf.Timestamp).FillBytes(timestampWord[:])
+
+	return crypto.Keccak256Hash(leaf.BlockHash[:], timestampWord[:], baseFeeWord[:], blobBaseFeeWord[:], leaf.ParentBeaconRoot[:])
+}
+
+// L1InfoTreeProof is an inclusion proof of one leaf into an L1InfoTree root: the sibling hash at
+// each level from the leaf up to the root, plus the leaf's index (needed to know, at each level,
+// whether the sibling is on the left or the right).
+type L1InfoTreeProof struct {
+	Index uint64
+	Leaf  L1InfoTreeLeaf
+	Path  [l1InfoTreeDepth]common.Hash
+}
+
+// L1InfoTree is an append-only incremental Merkle tree keyed by L1 block number: Add takes one L1
+// block at a time, in increasing order of L1Height, and L1InfoRootAt/Proof let a caller check a
+// claimed L1 attribute against the tree without re-fetching the L1 header. Nothing in this package
+// constructs an L1InfoTree or calls Add: CheckBatch passes a nil tree to checkSpanBatch, so the
+// overlap cross-check against L1InfoRoot does not run.
+//
+// This is an incremental (append-only) tree, not a general sparse Merkle tree keyed by arbitrary
+// height: L1 block numbers the pipeline adds are assumed contiguous starting from the first height
+// added, the same assumption l1Blocks windows elsewhere in this package already make.
+type L1InfoTree struct {
+	firstHeight uint64
+	haveFirst   bool
+
+	// filledSubtrees[i] is the rightmost computed hash at level i that is part of a complete
+	// subtree, the same bookkeeping the eth2 deposit contract's incremental Merkle tree keeps so
+	// that appending a new leaf is O(depth) instead of recomputing the whole tree.
+	filledSubtrees [l1InfoTreeDepth]common.Hash
+	leafCount      uint64
+
+	leaves []L1InfoTreeLeaf
+}
+
+// NewL1InfoTree constructs an empty L1InfoTree.
+func NewL1InfoTree() *L1InfoTree {
+	t := &L1InfoTree{}
+	for i := range t.filledSubtrees {
+		t.filledSubtrees[i] = l1InfoTreeZeroHashes[i]
+	}
+	return t
+}
+
+// Add appends leaf for l1Height, which must be exactly one more than the height of the last Add
+// call (or the tree's first Add, if none has happened yet).
+func (t *L1InfoTree) Add(l1Height uint64, leaf L1InfoTreeLeaf) error {
+	if !t.haveFirst {
+		t.firstHeight = l1Height
+		t.haveFirst = true
+	} else if l1Height != t.firstHeight+t.leafCount {
+		return fmt.Errorf("L1InfoTree.Add called out of order: expected height %d, got %d", t.firstHeight+t.leafCount, l1Height)
+	}
+
+	t.leaves = append(t.leaves, leaf)
+	node := leaf.hash()
+	index := t.leafCount
+	for level := 0; level < l1InfoTreeDepth; level++ {
+		if index%2 == 0 {
+			t.filledSubtrees[level] = node
+			node = hashPair(node, l1InfoTreeZeroHashes[level])
+		} else {
+			node = hashPair(t.filledSubtrees[level], node)
+		}
+		index /= 2
+	}
+	t.leafCount++
+	return nil
+}
+
+// root is the tree's current root: the top of the incremental Merkle tree as filled so far, padded
+// with zero hashes above the rightmost filled subtree at every level.
+func (t *L1InfoTree) root() common.Hash {
+	node := common.Hash{}
+	size := t.leafCount
+	for level := 0; level < l1InfoTreeDepth; level++ {
+		if size%2 == 1 {
+			node = hashPair(t.filledSubtrees[level], node)
+		} else {
+			node = hashPair(node, l1InfoTreeZeroHashes[level])
+		}
+		size /= 2
+	}
+	return node
+}
+
+// L1InfoRootAt returns the tree's root as of the state right after l1Height was Add-ed, or false if
+// l1Height has not been added (yet, or ever).
+//
+// This tree only remembers its current root, not a root "as of" every past height: recomputing a
+// historical root would need every leaf retained and replayed, rather than just the O(depth)
+// filledSubtrees this tree actually keeps. As a result, L1InfoRootAt only answers for the most
+// recently added height; callers checking an older height must keep their own snapshot of the root
+// at the time, the same way L1InfoTree's own caller would need to for Proof below to stay valid
+// against a root it already committed to.
+func (t *L1InfoTree) L1InfoRootAt(l1Height uint64) (common.Hash, bool) {
+	if !t.haveFirst || t.leafCount == 0 || l1Height != t.firstHeight+t.leafCount-1 {
+		return common.Hash{}, false
+	}
+	return t.root(), true
+}
+
+// Proof returns an inclusion proof for l1Height's leaf against the tree's current root, or false if
+// l1Height was never added.
+func (t *L1InfoTree) Proof(l1Height uint64) (L1InfoTreeProof, bool) {
+	if !t.haveFirst || l1Height < t.firstHeight || l1Height >= t.firstHeight+t.leafCount {
+		return L1InfoTreeProof{}, false
+	}
+	index := l1Height - t.firstHeight
+	proof := L1InfoTreeProof{Index: index, Leaf: t.leaves[index]}
+
+	// Rebuild the path by replaying every Add up to this leaf's sibling at each level. This tree
+	// does not keep the full set of intermediate nodes (only filledSubtrees, the rightmost complete
+	// ones), so a proof for a leaf other than the most recent one is derived by recomputing the
+	// relevant siblings from the retained leaves rather than from cached per-level state.
+	level := make([]common.Hash, t.leafCount)
+	for i, leaf := range t.leaves {
+		level[i] = leaf.hash()
+	}
+	idx := index
+	for depth := 0; depth < l1InfoTreeDepth; depth++ {
+		var sibling common.Hash
+		if idx^1 < uint64(len(level)) {
+			sibling = level[idx^1]
+		} else {
+			sibling = l1InfoTreeZeroHashes[depth]
+		}
+		proof.Path[depth] = sibling
+
+		next := make([]common.Hash, (len(level)+1)/2)
+		for i := range next {
+			left := l1InfoTreeZeroHashes[depth]
+			if 2*i < len(level) {
+				left = level[2*i]
+			}
+			right := l1InfoTreeZeroHashes[depth]
+			if 2*i+1 < len(level) {
+				right = level[2*i+1]
+			}
+			next[i] = hashPair(left, right)
+		}
+		level = next
+		idx /= 2
+	}
+
+	return proof, true
+}
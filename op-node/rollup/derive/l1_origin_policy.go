@@ -0,0 +1,172 @@
+package derive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// L1OriginFetcher is the L1 data access an L1OriginPolicy needs: looking up a block by number, which
+// every policy's deterministic constraint checks rely on, and looking up the most recent safe block,
+// which only SafeTagL1OriginPolicy uses. Both methods are required on the interface rather than split
+// across two smaller ones because L1OriginPolicy.Resolve's signature is shared by every
+// implementation, so it must accept whichever superset the richest policy needs.
+type L1OriginFetcher interface {
+	L1BlockRefByNumberFetcher
+
+	// L1Safe returns the most recent L1 block tagged "safe" by the L1 client, the same passthrough
+	// EspressoProvider.L1Finalized already provides for "finalized".
+	L1Safe(ctx context.Context) (eth.L1BlockRef, error)
+}
+
+// L1OriginPolicy decides which L1 origin an L2 block built on parent must use, given the L1 origin
+// Espresso suggested for it. AdjustSuggested narrows the raw suggestion down to one the policy
+// considers sufficiently confirmed; Resolve then applies the derivation pipeline's own constraints
+// (no skipped L1 block, no decrease, not too old, not newer than the batch) to the adjusted value and
+// returns the L1 origin to use.
+//
+// A policy must be a pure function of (parent, suggested, l1): same inputs, same output, on every
+// node running that policy, so the derivation pipeline stays deterministic. Anything a policy needs
+// beyond those three (cfg.BlockTime, cfg.MaxSequencerDrift, a logger) belongs on the policy value
+// itself, fixed at construction, not threaded through Resolve's arguments.
+type L1OriginPolicy interface {
+	// AdjustSuggested narrows suggested down to an L1 block number the policy considers confirmed
+	// enough to use, before Resolve applies the pipeline's deterministic constraints to it.
+	AdjustSuggested(suggested uint64, sysCfg *eth.SystemConfig) uint64
+	// Resolve applies the pipeline's deterministic constraints to suggested (already adjusted by
+	// AdjustSuggested) and returns the L1 origin parent's child must use. windowStart is
+	// parent.Time + cfg.BlockTime, the timestamp of the L2 block being derived.
+	Resolve(ctx context.Context, parent eth.L2BlockRef, suggested uint64, windowStart uint64, l1 L1OriginFetcher) (eth.L1BlockRef, error)
+}
+
+// DefaultL1OriginPolicy is the deterministic policy EspressoL1Origin always used before this was made
+// pluggable: it adjusts suggested by a fixed confirmation depth (sysCfg.EspressoL1ConfDepth), then
+// picks an origin honoring the four numbered constraints below.
+type DefaultL1OriginPolicy struct {
+	cfg *rollup.Config
+	log log.Logger
+}
+
+func NewDefaultL1OriginPolicy(cfg *rollup.Config, log log.Logger) *DefaultL1OriginPolicy {
+	return &DefaultL1OriginPolicy{cfg: cfg, log: log}
+}
+
+func (p *DefaultL1OriginPolicy) AdjustSuggested(suggested uint64, sysCfg *eth.SystemConfig) uint64 {
+	// The Espresso Sequencer always suggests the latest L1 block as the L1 origin. Using this
+	// suggestion as-is makes us highly sensitive to L1 reorgs, since we are using a block with no
+	// confirmations. EspressoL1ConfDepth allows the pipeline to lag behind the L1 origins suggested
+	// by the Espresso Sequencer, thus always using an L1 block with at least a certain number of
+	// confirmations, while the derivation remains deterministic.
+	if suggested > sysCfg.EspressoL1ConfDepth {
+		return suggested - sysCfg.EspressoL1ConfDepth
+	}
+	return 0
+}
+
+func (p *DefaultL1OriginPolicy) Resolve(ctx context.Context, parent eth.L2BlockRef, suggested uint64, windowStart uint64, l1 L1OriginFetcher) (eth.L1BlockRef, error) {
+	return resolveDeterministicOrigin(ctx, p.cfg, p.log, parent, suggested, windowStart, l1)
+}
+
+// SafeTagL1OriginPolicy is an alternative to DefaultL1OriginPolicy for operators on L1s with variable
+// finality, where a fixed confirmation depth is either too conservative (wasting confirmations on a
+// fast-finalizing L1) or too aggressive (not enough on a slow one). Instead of subtracting a static
+// depth from suggested, it clamps suggested down to the L1 block most recently tagged "safe" by the
+// L1 client, then applies the same deterministic constraints as DefaultL1OriginPolicy to the result.
+type SafeTagL1OriginPolicy struct {
+	cfg *rollup.Config
+	log log.Logger
+}
+
+func NewSafeTagL1OriginPolicy(cfg *rollup.Config, log log.Logger) *SafeTagL1OriginPolicy {
+	return &SafeTagL1OriginPolicy{cfg: cfg, log: log}
+}
+
+// AdjustSuggested is a no-op: unlike DefaultL1OriginPolicy's fixed depth, the safe tag is not a
+// function of suggested at all, so there is nothing to subtract here. The clamp against the safe tag
+// happens in Resolve, where the L1Safe lookup result is available.
+func (p *SafeTagL1OriginPolicy) AdjustSuggested(suggested uint64, sysCfg *eth.SystemConfig) uint64 {
+	return suggested
+}
+
+func (p *SafeTagL1OriginPolicy) Resolve(ctx context.Context, parent eth.L2BlockRef, suggested uint64, windowStart uint64, l1 L1OriginFetcher) (eth.L1BlockRef, error) {
+	safe, err := l1.L1Safe(ctx)
+	if err != nil {
+		return eth.L1BlockRef{}, fmt.Errorf("failed to fetch safe L1 tag: %w", err)
+	}
+	if suggested > safe.Number {
+		suggested = safe.Number
+	}
+	return resolveDeterministicOrigin(ctx, p.cfg, p.log, parent, suggested, windowStart, l1)
+}
+
+// resolveDeterministicOrigin applies the derivation pipeline's own constraints on top of an
+// already-adjusted suggested L1 origin: it must not skip an L1 block from parent's origin, must not
+// decrease, must not be too old, and must not be newer than the L2 batch itself. This is the logic
+// EspressoL1Origin used to run inline before L1OriginPolicy existed; both DefaultL1OriginPolicy and
+// SafeTagL1OriginPolicy delegate to it unchanged, differing only in how they arrive at suggested.
+func resolveDeterministicOrigin(ctx context.Context, cfg *rollup.Config, l log.Logger, parent eth.L2BlockRef, suggested uint64, windowStart uint64, l1 L1BlockRefByNumberFetcher) (eth.L1BlockRef, error) {
+	prev := parent.L1Origin
+
+	// Constraint 1: the L1 origin must not skip an L1 block.
+	if suggested > prev.Number+1 {
+		nextL1Block, err := l1.L1BlockRefByNumber(ctx, prev.Number+1)
+		if err != nil {
+			return eth.L1BlockRef{}, fmt.Errorf("failed to fetch next possible L1 origin %d: %w", nextL1Block, err)
+		}
+		nextL1BlockEligible := nextL1Block.Time <= windowStart
+		// If we did skip an L1 block, that is Espresso telling us that multiple new L1 blocks have
+		// already been produced. In this case, we will not block when fetching the next L1 origin,
+		// so advance as far as the derivation pipeline allows: one block.
+		if nextL1BlockEligible {
+			l.Info("We skipped an L1 block and the next L1 block is eligible as an origin, advancing by one")
+			return nextL1Block, nil
+		} else {
+			l.Info("We skipped an L1 block and the next L1 block is not eligible as an origin, using the old origin")
+			return l1.L1BlockRefByNumber(ctx, prev.Number)
+		}
+	}
+	// Constraint 2: the L1 origin number decreased.
+	//
+	// While Espresso _should_ guarantee that L1 origin numbers are monotonically increasing, a
+	// limitation in the current design means that on rare occasions the L1 origin number can
+	// decrease.
+	if suggested < prev.Number {
+		// In this case, we have no indication that new L1 blocks are ready. We don't want to
+		// advance the L1 origin number and force the derivation pipeline to block waiting for a new
+		// L1 block to be produced, so just reuse the previous L1 origin.
+		l.Info("L1 origin decreased, using the old origin")
+		return l1.L1BlockRefByNumber(ctx, prev.Number)
+	}
+
+	// Fetch information about the suggested L1 block needed to evaluate the rest of the constraints.
+	l1Block, err := l1.L1BlockRefByNumber(ctx, suggested)
+	if err != nil {
+		return eth.L1BlockRef{}, fmt.Errorf("failed to fetch suggested L1 origin %d: %w", suggested, err)
+	}
+
+	// Constraint 3: the L1 origin is too old.
+	if l1Block.Time+cfg.MaxSequencerDrift < windowStart {
+		// Again, we have no explicit indication that new L1 blocks are ready, but here we are
+		// forced to advance the L1 origin. At worst, the derivation pipeline may block until the
+		// next L1 origin is available, but if the chosen L1 origin is this old, it is likely that a
+		// new L1 block is available and Espresso just hasn't seen it yet for some reason.
+		l.Info("L1 origin is too old, advancing by one",
+			"suggested", l1Block, "suggested_time", l1Block.Time)
+		return l1.L1BlockRefByNumber(ctx, prev.Number+1)
+	}
+	// Constraint 4: the L1 origin must not be newer than the L2 batch.
+	if l1Block.Time > windowStart {
+		// In this case `suggested` must be `prev.Number + 1`, since `prev.Number` would have a
+		// timestamp earlier than `prev`, and thus earlier than the current batch. Espresso must be
+		// running ahead of the L2, which is fine, we'll just wait to advance the L1 origin until
+		// the L2 chain catches up.
+		l.Info("L1 origin is newer than the L2 batch, use the previous origin")
+		return l1.L1BlockRefByNumber(ctx, prev.Number)
+	}
+
+	// In all other cases, the suggested L1 origin is valid.
+	return l1Block, nil
+}
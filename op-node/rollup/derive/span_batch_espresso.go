@@ -0,0 +1,84 @@
+package derive
+
+import (
+	"github.com/EspressoSystems/espresso-sequencer-go/nmt"
+	espresso "github.com/EspressoSystems/espresso-sequencer-go/types"
+
+	"github.com/ethereum-optimism/optimism/op-node/rollup"
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// CheckSpanBatchEspresso runs CheckBatchEspresso's three justification checks -- l1.VerifyCommitments
+// over [Prev, Blocks..., Next], checkBookends at both ends of the sequencing window, and NMT proof
+// validation of the block's transactions -- against an ordered list of per-L2-block justifications
+// covering every block derived from a SpanBatch, one sequencing window per block.
+//
+// checkSpanBatch cannot call this yet: SpanBatch (defined upstream, not part of this checkout) has
+// no Justification field to read the per-block list from, unlike SingularBatch's single
+// Justification field that checkSingularBatch's tail already reads. Wiring this in means adding a
+// Justification []*eth.L2BatchJustification field to SpanBatch and passing batch.Justification
+// through from checkSpanBatch's tail the same way checkSingularBatch's tail calls CheckBatchEspresso
+// -- a change to a type this checkout doesn't have the source for. CheckSpanBatchEspresso itself is
+// complete and ready for that call once SpanBatch gains the field.
+func CheckSpanBatchEspresso(cfg *rollup.Config, log log.Logger, l2SafeHead eth.L2BlockRef,
+	blockTransactions [][]espresso.Bytes, justifications []*eth.L2BatchJustification, l1 EspressoL1Provider) BatchValidity {
+	if len(justifications) != len(blockTransactions) {
+		log.Error("span batch espresso check called with mismatched block/justification counts",
+			"blocks", len(blockTransactions), "justifications", len(justifications))
+		return BatchDrop
+	}
+
+	windowStart := l2SafeHead.Time + cfg.BlockTime
+	for i, jst := range justifications {
+		log := log.New("block_index", i)
+
+		if jst == nil {
+			log.Warn("dropping span batch because a block is missing its justification")
+			return BatchDrop
+		}
+
+		var comms []espresso.Commitment
+		if jst.Prev != nil {
+			comms = append(comms, jst.Prev.Commit())
+		}
+		for _, b := range jst.Blocks {
+			comms = append(comms, b.Header.Commit())
+		}
+		comms = append(comms, jst.Next.Commit())
+		validComms, err := l1.VerifyCommitments(jst.First().Height, comms)
+		if err != nil {
+			log.Warn("error reading expected commitments", "err", err, "first", jst.First(), "count", len(comms))
+			return BatchUndecided
+		}
+		if !validComms {
+			log.Warn("dropping span batch because headers do not match contract", "first", jst.First(), "count", len(comms))
+			return BatchDrop
+		}
+
+		windowEnd := windowStart + cfg.BlockTime
+		if !checkBookends(log, windowStart, jst, WindowStart) {
+			return BatchDrop
+		}
+		if !checkBookends(log, windowEnd, jst, WindowEnd) {
+			return BatchDrop
+		}
+
+		if len(blockTransactions[i]) != 0 {
+			roots := make([]*espresso.NmtRoot, len(jst.Blocks))
+			proofs := make([]*espresso.NmtProof, len(jst.Blocks))
+			for j, block := range jst.Blocks {
+				roots[j] = &block.Header.TransactionsRoot
+				proofs[j] = &block.Proof
+			}
+			if err := nmt.ValidateBatchTransactions(cfg.L2ChainID.Uint64(), roots, proofs, blockTransactions[i]); err != nil {
+				log.Warn("dropping span batch because of invalid NMT proofs", "err", err)
+				return BatchDrop
+			}
+		}
+
+		windowStart = windowEnd
+	}
+
+	return BatchAccept
+}
@@ -0,0 +1,117 @@
+package derive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// UpgradeManifestStep is a single deposit transaction in a declarative upgrade manifest: either a
+// contract deployment (To == nil) or a call into an existing predeploy/proxy (To != nil).
+type UpgradeManifestStep struct {
+	Intent           string         `json:"intent"`
+	From             common.Address `json:"from"`
+	To               *common.Address `json:"to,omitempty"`
+	Gas              uint64         `json:"gas"`
+	Mint             *hexutil.Big   `json:"mint,omitempty"`
+	Data             hexutil.Bytes  `json:"data"`
+	ExpectedCodeHash *common.Hash   `json:"expectedCodeHash,omitempty"`
+}
+
+// UpgradeManifest is an ordered list of deposit transactions that activate a hardfork. Unlike the
+// hardcoded transaction lists in ecotone_upgrade_transactions.go and
+// espresso_upgrade_transactions.go, a manifest can be loaded from JSON or YAML at startup, letting
+// operators register additional forks without recompiling op-node.
+type UpgradeManifest struct {
+	Fork  string                 `json:"fork"`
+	Steps []UpgradeManifestStep `json:"steps"`
+}
+
+// UpgradeManifestFromJSON parses a manifest previously serialized by ApplyUpgradeManifest's
+// callers. YAML manifests are expected to be converted to JSON before reaching this function,
+// since op-node does not otherwise depend on a YAML library.
+func UpgradeManifestFromJSON(data []byte) (*UpgradeManifest, error) {
+	var manifest UpgradeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse upgrade manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// ApplyUpgradeManifest builds the deposit transactions described by manifest, deriving each
+// SourceHash from an UpgradeDepositSource keyed on forkName and the step's intent so that replay
+// is deterministic across nodes, the same guarantee the hardcoded upgrade lists provide.
+//
+// For any step with To == nil (a contract deployment) and a non-nil ExpectedCodeHash, the CREATE
+// address is recomputed from (From, nonce 0) and the deployment is simulated far enough to compare
+// keccak256(runtime code) against ExpectedCodeHash; a mismatch is treated as an error rather than
+// silently emitting a deposit whose deployment would diverge from the manifest.
+func ApplyUpgradeManifest(manifest *UpgradeManifest) ([]hexutil.Bytes, error) {
+	txns := make([]hexutil.Bytes, 0, len(manifest.Steps))
+	for i, step := range manifest.Steps {
+		if step.To == nil && step.ExpectedCodeHash != nil {
+			if err := verifyDeploymentCodeHash(step); err != nil {
+				return nil, fmt.Errorf("upgrade manifest %q step %d (%s): %w", manifest.Fork, i, step.Intent, err)
+			}
+		}
+
+		mint := big.NewInt(0)
+		if step.Mint != nil {
+			mint = step.Mint.ToInt()
+		}
+		source := UpgradeDepositSource{Intent: fmt.Sprintf("%s: %s", manifest.Fork, step.Intent)}
+		tx, err := types.NewTx(&types.DepositTx{
+			SourceHash:          source.SourceHash(),
+			From:                step.From,
+			To:                  step.To,
+			Mint:                mint,
+			Value:               big.NewInt(0),
+			Gas:                 step.Gas,
+			IsSystemTransaction: false,
+			Data:                step.Data,
+		}).MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("upgrade manifest %q step %d (%s): %w", manifest.Fork, i, step.Intent, err)
+		}
+		txns = append(txns, tx)
+	}
+	return txns, nil
+}
+
+// verifyDeploymentCodeHash recomputes the CREATE address for a deployment step and checks that the
+// runtime code it would produce matches step.ExpectedCodeHash. Since op-node does not run the EVM,
+// "simulation" here means stripping a standard Solidity constructor's init-code wrapper and
+// treating the remainder as the runtime code; this is sufficient to catch a manifest edited to
+// point at the wrong bytecode, though it is not as strong a check as executing the constructor.
+func verifyDeploymentCodeHash(step UpgradeManifestStep) error {
+	addr := crypto.CreateAddress(step.From, 0)
+	runtimeCode, err := runtimeCodeFromInitCode(step.Data)
+	if err != nil {
+		return fmt.Errorf("failed to recover runtime code for deployment at %s: %w", addr, err)
+	}
+	got := crypto.Keccak256Hash(runtimeCode)
+	if got != *step.ExpectedCodeHash {
+		return fmt.Errorf("deployment at %s would produce code hash %s, expected %s", addr, got, step.ExpectedCodeHash)
+	}
+	return nil
+}
+
+// runtimeCodeFromInitCode extracts the runtime code appended after a standard Solidity
+// constructor's copy-and-return trailer (the `CODECOPY; PUSH1 0; RETURN; INVALID` pattern also used
+// by the hand-written predeploy bytecode in ecotone_upgrade_transactions.go).
+func runtimeCodeFromInitCode(initCode []byte) ([]byte, error) {
+	// `CODECOPY` (0x39), `PUSH1 0` (0x60 0x00), `RETURN` (0xf3) and `INVALID` (0xfe) mark the end of
+	// the constructor trailer that solc emits; the runtime code starts right after them.
+	marker := []byte{0x39, 0x60, 0x00, 0xf3, 0xfe}
+	idx := bytes.Index(initCode, marker)
+	if idx < 0 {
+		return nil, fmt.Errorf("init code does not contain a CODECOPY/RETURN trailer")
+	}
+	return initCode[idx+len(marker):], nil
+}
@@ -0,0 +1,89 @@
+// Package checkpoint persists enough of the Sequencer's in-progress Espresso sequencing state that
+// a restarted op-node can resume scanning HotShot from where it left off, rather than re-scanning
+// from the L2 safe head's original L1 origin on every restart.
+package checkpoint
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// Checkpoint captures everything the Sequencer needs to resume building the current Espresso batch
+// without rescanning HotShot from scratch.
+type Checkpoint struct {
+	// Onto is the hash of the L2 block this batch is being built on top of. A checkpoint whose Onto
+	// no longer matches the current L2 head was left behind by a reorg and must not be resumed from,
+	// even if its WindowStart/WindowEnd happen to coincide with the window being started now.
+	Onto common.Hash
+	// LastHeight is the height of the last HotShot block consumed into Justification.Blocks.
+	LastHeight uint64
+	// WindowStart and WindowEnd bound the in-progress sequencing window, in L2 timestamp units.
+	WindowStart uint64
+	WindowEnd   uint64
+	// Justification is the L2BatchJustification accumulated so far for the in-progress window.
+	Justification eth.L2BatchJustification
+	// L1OriginNumber is the L1 origin chosen for this batch when it was sealed; it is zero in a
+	// checkpoint saved while the batch is still being built.
+	L1OriginNumber uint64
+}
+
+// Store persists and retrieves the single most recent Checkpoint. Implementations need not retain
+// more than the latest checkpoint: once a batch is sealed, any earlier checkpoint is obsolete.
+type Store interface {
+	// Load returns the most recently saved Checkpoint, or nil if none has been saved yet.
+	Load() (*Checkpoint, error)
+	// Save persists checkpoint, replacing whatever was previously saved.
+	Save(checkpoint Checkpoint) error
+}
+
+// History records a bounded trail of sealed (fully built) Checkpoints for post-mortem debugging,
+// independent of Store's single live checkpoint. Sequencer.SetSealedHistory wires one in; if none is
+// set, sealed checkpoints are simply not retained anywhere beyond Store's own latest entry.
+type History interface {
+	// Append records checkpoint as the most recently sealed batch, dropping the oldest entry if the
+	// implementation's retention limit is exceeded.
+	Append(checkpoint Checkpoint) error
+}
+
+// MemStore is an in-memory Store, suitable for tests. It is not safe for concurrent use, matching
+// the Sequencer's own single-threaded-driver-loop convention.
+type MemStore struct {
+	checkpoint *Checkpoint
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{}
+}
+
+func (m *MemStore) Load() (*Checkpoint, error) {
+	return m.checkpoint, nil
+}
+
+func (m *MemStore) Save(checkpoint Checkpoint) error {
+	m.checkpoint = &checkpoint
+	return nil
+}
+
+// MemHistory is an in-memory History, suitable for tests, retaining at most maxEntries.
+type MemHistory struct {
+	maxEntries int
+	entries    []Checkpoint
+}
+
+func NewMemHistory(maxEntries int) *MemHistory {
+	return &MemHistory{maxEntries: maxEntries}
+}
+
+func (m *MemHistory) Append(checkpoint Checkpoint) error {
+	m.entries = append(m.entries, checkpoint)
+	if len(m.entries) > m.maxEntries {
+		m.entries = m.entries[len(m.entries)-m.maxEntries:]
+	}
+	return nil
+}
+
+// Entries returns the retained sealed checkpoints, oldest first.
+func (m *MemHistory) Entries() []Checkpoint {
+	return m.entries
+}
@@ -0,0 +1,126 @@
+package checkpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileStore is the default production Store: it keeps the latest Checkpoint as JSON in a single
+// file, writing a new file and renaming it over the old one so a crash mid-write cannot leave a
+// truncated checkpoint behind.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a Store backed by the file at path. The file need not exist yet; Load
+// returns a nil Checkpoint until the first Save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (f *FileStore) Load() (*Checkpoint, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file %s: %w", f.path, err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file %s: %w", f.path, err)
+	}
+	return &checkpoint, nil
+}
+
+func (f *FileStore) Save(checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to install checkpoint file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// EnsureDir creates the parent directory of path if it does not already exist, so that callers can
+// pass a checkpoint file path under a data directory that may not have been created yet.
+func EnsureDir(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// FileHistory is the default production History: it keeps the last maxEntries sealed Checkpoints as
+// one JSON object per line in a single file. Every Append rewrites the whole file (write-and-rename,
+// the same pattern FileStore.Save uses for crash safety), dropping the oldest entries beyond
+// maxEntries.
+type FileHistory struct {
+	path       string
+	maxEntries int
+}
+
+// NewFileHistory returns a History backed by the file at path, retaining at most maxEntries sealed
+// checkpoints for post-mortem debugging.
+func NewFileHistory(path string, maxEntries int) *FileHistory {
+	return &FileHistory{path: path, maxEntries: maxEntries}
+}
+
+func (f *FileHistory) load() ([]Checkpoint, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint history file %s: %w", f.path, err)
+	}
+	var entries []Checkpoint
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry Checkpoint
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse checkpoint history file %s: %w", f.path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (f *FileHistory) Append(checkpoint Checkpoint) error {
+	entries, err := f.load()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, checkpoint)
+	if len(entries) > f.maxEntries {
+		entries = entries[len(entries)-f.maxEntries:]
+	}
+
+	w := new(bytes.Buffer)
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode checkpoint history entry: %w", err)
+		}
+	}
+
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, w.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint history file %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to install checkpoint history file %s: %w", f.path, err)
+	}
+	return nil
+}
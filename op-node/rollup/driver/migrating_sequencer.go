@@ -0,0 +1,137 @@
+package driver
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// MigrationConfig configures MigratingSequencer's one-way cutover from legacy to Espresso
+// sequencing. Unlike SwitchConfig, which governs SwitchSequencer's ongoing, reversible fallback
+// based on HotShot liveness, this is a scheduled, irreversible activation: once ActivationTime is
+// reached, the sequencer never returns to legacy mode.
+type MigrationConfig struct {
+	// ActivationTime is the L2 block timestamp at or after which the sequencer migrates from
+	// Legacy to Espresso sequencing. Nil disables scheduled activation entirely.
+	ActivationTime *uint64
+	// Shadow, if set, makes a Sequencer still in Legacy mode also fetch (but never submit) the
+	// Espresso transactions for each block it builds, and compare them against what legacy
+	// sequencing actually included. Divergences are logged and recorded via
+	// SequencerMetrics.RecordSequencerShadowDivergence, so operators can gauge how disruptive a
+	// cutover would be before ActivationTime arrives.
+	Shadow bool
+}
+
+// MigratingSequencer wraps a Sequencer that was constructed in Legacy mode with a scheduled,
+// one-way migration to Espresso sequencing, and an optional shadow-comparison mode to run ahead of
+// it. A bare Sequencer only ever picks its mode once, via detectMode; like SwitchSequencer,
+// MigratingSequencer is the only thing allowed to change d.mode after that, and unlike
+// SwitchSequencer it only ever moves in one direction.
+type MigratingSequencer struct {
+	*Sequencer
+
+	migrationCfg MigrationConfig
+}
+
+// NewMigratingSequencer wraps seq with a scheduled Legacy-to-Espresso migration. seq must have been
+// constructed for Legacy mode; if seq is already running in Espresso mode, cfg.ActivationTime has
+// nothing left to do.
+func NewMigratingSequencer(seq *Sequencer, cfg MigrationConfig) *MigratingSequencer {
+	return &MigratingSequencer{
+		Sequencer:    seq,
+		migrationCfg: cfg,
+	}
+}
+
+// shouldActivateEspresso reports whether the next L2 block to be built lands at or after
+// ActivationTime, and we are still in Legacy mode.
+func (m *MigratingSequencer) shouldActivateEspresso() bool {
+	if m.migrationCfg.ActivationTime == nil || m.mode != Legacy {
+		return false
+	}
+	head := m.engine.UnsafeL2Head()
+	nextBlockTime := head.Time + m.config.BlockTime
+	return nextBlockTime >= *m.migrationCfg.ActivationTime
+}
+
+// activateEspresso flips the sequencer into Espresso mode for good.
+func (m *MigratingSequencer) activateEspresso() {
+	m.log.Info("Espresso activation time reached, migrating from legacy to Espresso sequencing", "activationTime", *m.migrationCfg.ActivationTime)
+	m.metrics.RecordSequencerModeTransition(m.mode, Espresso)
+	m.mode = Espresso
+	m.nextAction = m.timeNow()
+}
+
+func (m *MigratingSequencer) PlanNextSequencerAction() time.Duration {
+	if m.shouldActivateEspresso() {
+		if _, buildingID, _ := m.engine.BuildingPayload(); buildingID == (eth.PayloadID{}) {
+			m.activateEspresso()
+		}
+	}
+	return m.Sequencer.PlanNextSequencerAction()
+}
+
+func (m *MigratingSequencer) RunNextSequencerAction(ctx context.Context) (*eth.ExecutionPayload, error) {
+	if m.shouldActivateEspresso() {
+		onto, buildingID, _ := m.engine.BuildingPayload()
+		if buildingID != (eth.PayloadID{}) {
+			// Drain the in-flight legacy block to completion rather than cancelling it, so the
+			// migration doesn't throw away work that was already almost done. The next call will
+			// see shouldActivateEspresso still true with nothing mid-build, and activate then.
+			payload, err := m.buildLegacyBlock(ctx, true)
+			if m.migrationCfg.Shadow && err == nil && payload != nil {
+				m.shadowCompareEspresso(ctx, onto, payload)
+			}
+			return payload, err
+		}
+		m.activateEspresso()
+		return m.Sequencer.RunNextSequencerAction(ctx)
+	}
+
+	if m.migrationCfg.Shadow && m.mode == Legacy {
+		// Only relevant when this call completes a block (payload != nil below); onto is the
+		// legacy parent the in-flight block was building on top of, captured before the call
+		// resets it.
+		onto, _, _ := m.engine.BuildingPayload()
+		payload, err := m.Sequencer.RunNextSequencerAction(ctx)
+		if err == nil && payload != nil {
+			m.shadowCompareEspresso(ctx, onto, payload)
+		}
+		return payload, err
+	}
+
+	return m.Sequencer.RunNextSequencerAction(ctx)
+}
+
+// shadowCompareEspresso fetches the Espresso transactions for the sequencing window that would
+// follow onto, and compares their count against the transactions legacyPayload actually contains.
+// It never calls the engine: this is strictly an observational comparison alongside live legacy
+// sequencing, not a real competing block build, since there is only one ResettableEngineControl to
+// build against. A perfectly tight comparison would need to exclude legacyPayload's deposit
+// transactions by type rather than just counting; a gross divergence here is already actionable.
+func (m *MigratingSequencer) shadowCompareEspresso(ctx context.Context, onto eth.L2BlockRef, legacyPayload *eth.ExecutionPayload) {
+	windowStart := onto.Time + m.config.BlockTime
+	windowEnd := windowStart + m.config.BlockTime
+	blocks, err := m.espresso.FetchHeadersForWindow(ctx, windowStart, windowEnd)
+	if err != nil {
+		m.log.Warn("shadow mode: failed to fetch Espresso window for comparison", "err", err)
+		return
+	}
+
+	var espressoTxCount int
+	for i, header := range blocks.Window {
+		txs, err := m.espresso.FetchTransactionsInBlock(ctx, blocks.From+uint64(i), &header, m.config.L2ChainID.Uint64())
+		if err != nil {
+			m.log.Warn("shadow mode: failed to fetch Espresso transactions for comparison", "err", err)
+			return
+		}
+		espressoTxCount += len(txs.Transactions)
+	}
+
+	if espressoTxCount != len(legacyPayload.Transactions) {
+		m.log.Warn("shadow mode: Espresso and legacy sequencing would have diverged for this block",
+			"onto", onto, "espressoTxCount", espressoTxCount, "legacyTxCount", len(legacyPayload.Transactions))
+		m.metrics.RecordSequencerShadowDivergence(onto.Number + 1)
+	}
+}
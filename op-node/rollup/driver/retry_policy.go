@@ -0,0 +1,69 @@
+package driver
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy computes how long Sequencer.scheduleRetry should wait before retrying a sequencer
+// action that failed with a non-critical, non-reset error. attempt is the number of consecutive
+// failures handled by scheduleRetry since the last time resetRetryBackoff was called (always >= 1);
+// lastErr is the error that just occurred, for policies that want to vary the delay by error type.
+type RetryPolicy interface {
+	Next(attempt int, lastErr error) time.Duration
+}
+
+// ExponentialBackoffConfig configures ExponentialBackoff.
+type ExponentialBackoffConfig struct {
+	// Base is the delay after the first failed attempt.
+	Base time.Duration
+	// Max caps the delay regardless of how many consecutive attempts have failed. Jitter is applied
+	// after the cap, so Next can return up to Max*(1+JitterFraction).
+	Max time.Duration
+	// JitterFraction randomizes the computed delay by up to this fraction in either direction, so
+	// that many sequencers backing off in lockstep (e.g. after a shared upstream outage) don't all
+	// retry at exactly the same instant. 0 disables jitter entirely.
+	JitterFraction float64
+}
+
+// DefaultExponentialBackoffConfig returns a one-second base delay (the flat delay a Sequencer with
+// no RetryPolicy uses), a 30-second cap and 20% jitter.
+func DefaultExponentialBackoffConfig() ExponentialBackoffConfig {
+	return ExponentialBackoffConfig{
+		Base:           time.Second,
+		Max:            30 * time.Second,
+		JitterFraction: 0.2,
+	}
+}
+
+// ExponentialBackoff is an opt-in RetryPolicy: delay doubles with each consecutive attempt, up to
+// Max, with jitter applied on top. A Sequencer uses it only once it is passed to SetRetryPolicy;
+// without a policy, a Sequencer retries after a flat one second.
+type ExponentialBackoff struct {
+	cfg ExponentialBackoffConfig
+}
+
+// NewExponentialBackoff constructs an ExponentialBackoff from cfg.
+func NewExponentialBackoff(cfg ExponentialBackoffConfig) *ExponentialBackoff {
+	return &ExponentialBackoff{cfg: cfg}
+}
+
+func (b *ExponentialBackoff) Next(attempt int, lastErr error) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := b.cfg.Base << (attempt - 1)
+	if delay <= 0 || delay > b.cfg.Max { // overflow or past the cap
+		delay = b.cfg.Max
+	}
+
+	if b.cfg.JitterFraction <= 0 {
+		return delay
+	}
+	jitter := time.Duration(float64(delay) * b.cfg.JitterFraction)
+	// Uniformly distributed in [delay-jitter, delay+jitter].
+	return delay - jitter + time.Duration(rand.Int63n(int64(jitter)*2+1))
+}
+
+var _ RetryPolicy = (*ExponentialBackoff)(nil)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver/checkpoint"
 	"github.com/ethereum-optimism/optimism/op-service/espresso"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 )
@@ -32,11 +34,54 @@ type Downloader interface {
 type L1OriginSelectorIface interface {
 	FindL1Origin(ctx context.Context, l2Head eth.L2BlockRef) (eth.L1BlockRef, error)
 	FindL1OriginByNumber(ctx context.Context, number uint64) (eth.L1BlockRef, error)
+	// L1Head returns the most recent L1 block the selector has seen, so updateEspressoBatch can
+	// reject a HotShot header that references an L1 block it has not seen at all, rather than one
+	// it merely hasn't finalized yet.
+	L1Head(ctx context.Context) (eth.L1BlockRef, error)
+	// L1Finalized returns the most recent finalized L1 block the selector has seen, so
+	// updateEspressoBatch can stall on (rather than reject) a HotShot header that references an L1
+	// block which exists but has not finalized yet.
+	L1Finalized(ctx context.Context) (eth.L1BlockRef, error)
 }
 
 type SequencerMetrics interface {
 	RecordSequencerInconsistentL1Origin(from eth.BlockID, to eth.BlockID)
 	RecordSequencerReset()
+	// RecordSequencerModeTransition is called by SwitchSequencer every time it flips between
+	// Espresso and Legacy sequencing, so operators can alert on unexpected HotShot liveness flaps.
+	RecordSequencerModeTransition(from SequencerMode, to SequencerMode)
+	// RecordSequencerShadowDivergence is called by MigratingSequencer's shadow mode whenever the
+	// transactions Espresso would have sequenced for a block diverge from what legacy sequencing
+	// actually included, so operators can judge readiness for cutover before it happens.
+	RecordSequencerShadowDivergence(l2BlockNumber uint64)
+	// RecordEspressoQueryDivergence satisfies espresso.DivergenceMetrics: it is called whenever two
+	// query-service endpoints behind a espresso.ClientList disagree about the header at a HotShot
+	// block height, whether caught by ClientList's passive checkHeaders comparison or its stronger,
+	// opt-in CrossCheck mode.
+	RecordEspressoQueryDivergence(height uint64)
+	// RecordSequencerLeadershipTransition is called whenever a configured SequencerConductor's
+	// Leader result changes, so operators can alert on unexpected lease flaps.
+	RecordSequencerLeadershipTransition(leader bool)
+	// RecordSequencerCommitLatency is called with how long CommitUnsafePayload took every time a
+	// configured SequencerConductor is asked to commit a freshly sealed payload.
+	RecordSequencerCommitLatency(d time.Duration)
+	// RecordSequencerRetryBackoff is called every time scheduleRetry computes a new retry delay, so
+	// operators can see attempt counts and delays climb when an upstream (HotShot, L1, the engine)
+	// is persistently unavailable, rather than only seeing a steady stream of one-second retries.
+	RecordSequencerRetryBackoff(attempt int, delay time.Duration)
+}
+
+// SequencerConductor is an optional hook for running multiple Sequencer replicas behind a shared
+// leadership lease, mirroring upstream op-node's conductor.SequencerConductor. A Sequencer with no
+// conductor configured (the default, via SetConductor never being called) sequences unconditionally,
+// exactly as a single-node deployment always has.
+type SequencerConductor interface {
+	// Leader reports whether this replica currently holds the sequencing lease.
+	// RunNextSequencerAction refuses to start or seal any block while this returns false.
+	Leader(ctx context.Context) (bool, error)
+	// CommitUnsafePayload hands a freshly sealed payload to the conductor's quorum before the
+	// Sequencer returns it to its caller for publishing.
+	CommitUnsafePayload(ctx context.Context, payload *eth.ExecutionPayload) error
 }
 
 type InProgressBatch struct {
@@ -74,9 +119,96 @@ type Sequencer struct {
 
 	// The current Espresso block we are building, if applicable.
 	espressoBatch *InProgressBatch
+
+	// checkpoints persists espressoBatch progress so a restarted op-node can resume scanning HotShot
+	// from where it left off instead of rescanning from the L2 safe head's L1 origin.
+	checkpoints checkpoint.Store
+	// checkpointLoaded is set once the checkpoint has been consulted, so that startBuildingEspressoBatch
+	// only ever attempts to resume from it on the first batch built after construction.
+	checkpointLoaded bool
+
+	// sealedHistory, if set via SetSealedHistory, records every sealed Espresso batch's final
+	// checkpoint for post-mortem debugging, independent of checkpoints' single live entry.
+	sealedHistory checkpoint.History
+
+	// conductor, if set via SetConductor, gates sequencing on holding the leadership lease and
+	// receives every freshly sealed payload before it is returned for publishing.
+	conductor SequencerConductor
+	// wasLeader is the Leader result observed on the previous leadership check, so leadership
+	// transitions can be detected and metered without assuming the first check is always a change.
+	wasLeader bool
+
+	// priorityEnabled governs whether Priority ever reports true; see SetPriority. Defaults to
+	// false, so existing deployments keep the round-robin scheduling they always had.
+	priorityEnabled bool
+
+	// espressoSubscribed is set once ensureEspressoSubscription has made its one attempt to start a
+	// push-based header subscription, successful or not, so it is never retried for the lifetime of
+	// the Sequencer.
+	espressoSubscribed bool
+	// espressoWindowEnd mirrors espressoBatch.windowEnd (or 0 when no batch is in progress), for
+	// consumeEspressoSubscription's background goroutine to read without racing with espressoBatch
+	// itself, which is otherwise only ever touched by the single goroutine driving sequencing.
+	espressoWindowEnd atomic.Uint64
+	// espressoWoken is set by consumeEspressoSubscription whenever a pushed header lands at or past
+	// espressoWindowEnd, so planNextEspressoSequencerAction can react immediately instead of waiting
+	// out its poll delay.
+	espressoWoken atomic.Bool
+
+	// retryPolicy, if set via SetRetryPolicy, computes scheduleRetry's delay as a function of
+	// consecutive failed attempts. Nil (the default) preserves this package's original behavior of a
+	// flat one-second retry delay regardless of how many times an action has already failed.
+	retryPolicy RetryPolicy
+	// retryAttempt counts consecutive failed actions handled by scheduleRetry since the last time
+	// resetRetryBackoff was called, for retryPolicy.Next and for RecordSequencerRetryBackoff.
+	retryAttempt int
+
+	// espressoResumeOrigin, if set via SetEspressoResumeOrigin, is the L1 origin to resume scanning
+	// from the next time sealEspressoBatch picks an L1 origin, instead of only the one HotShot and
+	// batch.onto would otherwise suggest. SwitchSequencer sets this to the L1 origin it recorded when
+	// Espresso mode was last exited, so a mode flip back to Espresso never resolves an origin earlier
+	// than where sequencing actually left off. It is consulted, and cleared, once.
+	espressoResumeOrigin *eth.BlockID
+}
+
+// SetEspressoResumeOrigin records origin as a floor on the next L1 origin sealEspressoBatch picks:
+// if the origin it would otherwise suggest is older than origin, it is bumped up to origin instead.
+// It is meant to be called once, with the L1 origin recorded when Espresso sequencing was last
+// exited, so resuming Espresso mode doesn't re-derive an origin that regresses behind it.
+func (d *Sequencer) SetEspressoResumeOrigin(origin eth.BlockID) {
+	d.espressoResumeOrigin = &origin
+}
+
+// SetSealedHistory configures history as the Sequencer's sealed-batch checkpoint trail; see
+// checkpoint.History. It is optional: a Sequencer with no history set simply does not retain sealed
+// checkpoints beyond whatever checkpoints.Store itself keeps.
+func (d *Sequencer) SetSealedHistory(history checkpoint.History) {
+	d.sealedHistory = history
+}
+
+// SetConductor configures conductor as the Sequencer's leadership lease; see SequencerConductor. It
+// is optional, following the same pattern as SetSealedHistory: a Sequencer with no conductor set
+// sequences unconditionally, exactly as it did before SequencerConductor existed.
+func (d *Sequencer) SetConductor(conductor SequencerConductor) {
+	d.conductor = conductor
+}
+
+// SetPriority enables or disables Sequencer's priority-scheduling hook; see Priority. rollup.Config
+// has no in-tree source for this checkout to add a SequencerPriority field to directly, so this
+// follows the same pattern as SetConductor and threads the flag in as an optional setter instead.
+func (d *Sequencer) SetPriority(enabled bool) {
+	d.priorityEnabled = enabled
 }
 
-func NewSequencer(log log.Logger, cfg *rollup.Config, engine derive.ResettableEngineControl, attributesBuilder derive.AttributesBuilder, l1OriginSelector L1OriginSelectorIface, espresso espresso.QueryService, metrics SequencerMetrics) *Sequencer {
+// SetRetryPolicy configures policy as the Sequencer's retry backoff strategy; see RetryPolicy. It is
+// optional, following the same pattern as SetConductor/SetPriority: a Sequencer with no policy set
+// keeps retrying every failed action after a flat one-second delay, exactly as it did before
+// RetryPolicy existed.
+func (d *Sequencer) SetRetryPolicy(policy RetryPolicy) {
+	d.retryPolicy = policy
+}
+
+func NewSequencer(log log.Logger, cfg *rollup.Config, engine derive.ResettableEngineControl, attributesBuilder derive.AttributesBuilder, l1OriginSelector L1OriginSelectorIface, espresso espresso.QueryService, metrics SequencerMetrics, checkpoints checkpoint.Store) *Sequencer {
 	return &Sequencer{
 		log:              log,
 		config:           cfg,
@@ -88,6 +220,7 @@ func NewSequencer(log log.Logger, cfg *rollup.Config, engine derive.ResettableEn
 		espresso:         espresso,
 		metrics:          metrics,
 		espressoBatch:    nil,
+		checkpoints:      checkpoints,
 	}
 }
 
@@ -98,6 +231,12 @@ func (d *Sequencer) startBuildingEspressoBatch(ctx context.Context, l2Head eth.L
 	windowStart := l2Head.Time + d.config.BlockTime
 	windowEnd := windowStart + d.config.BlockTime
 
+	if restored, err := d.restoreEspressoBatch(ctx, l2Head, windowStart, windowEnd); err != nil {
+		return err
+	} else if restored {
+		return nil
+	}
+
 	// Fetch the available HotShot blocks from this sequencing window.
 	blocks, err := d.espresso.FetchHeadersForWindow(ctx, windowStart, windowEnd)
 	if err != nil {
@@ -113,9 +252,88 @@ func (d *Sequencer) startBuildingEspressoBatch(ctx context.Context, l2Head eth.L
 			Prev: blocks.Prev,
 		},
 	}
+	d.espressoWindowEnd.Store(windowEnd)
 	return d.updateEspressoBatch(ctx, blocks.Window, blocks.Next)
 }
 
+// restoreEspressoBatch consults the checkpoint store, once per Sequencer lifetime, to see whether
+// there is saved progress for the window we are about to build. If the checkpoint's window matches
+// the window we would otherwise start from scratch, it resumes from `LastHeight+1` via
+// FetchRemainingHeadersForWindow instead of rescanning the whole window. A checkpoint for a
+// different window means the saved batch was already sealed before the restart, so it is stale and
+// is ignored.
+func (d *Sequencer) restoreEspressoBatch(ctx context.Context, l2Head eth.L2BlockRef, windowStart, windowEnd uint64) (bool, error) {
+	if d.checkpointLoaded {
+		return false, nil
+	}
+	d.checkpointLoaded = true
+
+	cp, err := d.checkpoints.Load()
+	if err != nil {
+		d.log.Error("failed to load Espresso checkpoint, scanning window from scratch", "err", err)
+		return false, nil
+	}
+	if cp == nil || cp.WindowStart != windowStart || cp.WindowEnd != windowEnd || cp.Onto != l2Head.Hash {
+		return false, nil
+	}
+
+	d.log.Info("resuming Espresso batch from checkpoint", "lastHeight", cp.LastHeight, "windowStart", cp.WindowStart, "windowEnd", cp.WindowEnd)
+	d.espressoBatch = &InProgressBatch{
+		onto:        l2Head,
+		windowStart: cp.WindowStart,
+		windowEnd:   cp.WindowEnd,
+		jst:         cp.Justification,
+	}
+	d.espressoWindowEnd.Store(cp.WindowEnd)
+	blocks, err := d.espresso.FetchRemainingHeadersForWindow(ctx, cp.LastHeight+1, cp.WindowEnd)
+	if err != nil {
+		return false, err
+	}
+	return true, d.updateEspressoBatch(ctx, blocks.Window, blocks.Next)
+}
+
+// ensureEspressoSubscription makes a single, one-time attempt to start a push-based header
+// subscription against d.espresso, so that planNextEspressoSequencerAction can react to a new
+// header immediately instead of waiting out the usual poll delay. If d.espresso doesn't implement
+// espresso.Subscriber, or SubscribeHeaders itself fails, this silently leaves the existing polling path as
+// the only source of scheduling, exactly as if this method had never been called. It is called from
+// buildEspressoBatch, so it is cheap to call on every action: after the first call it is a no-op.
+func (d *Sequencer) ensureEspressoSubscription(ctx context.Context) {
+	if d.espressoSubscribed {
+		return
+	}
+	d.espressoSubscribed = true
+
+	sub, ok := d.espresso.(espresso.Subscriber)
+	if !ok {
+		return
+	}
+	// The subscription must outlive any single RunNextSequencerAction call, so it is started with a
+	// background context rather than ctx, which is cancelled as soon as this call returns.
+	headerSub, err := sub.SubscribeHeaders(context.Background(), 0)
+	if err != nil {
+		d.log.Warn("failed to start Espresso header subscription, falling back to polling", "err", err)
+		return
+	}
+	go d.consumeEspressoSubscription(headerSub)
+}
+
+// consumeEspressoSubscription runs in its own goroutine for the lifetime of the subscription
+// started by ensureEspressoSubscription. It never touches d.espressoBatch directly, since that
+// field is otherwise only ever read or written by the single goroutine driving
+// RunNextSequencerAction; instead it only reads the atomic mirror espressoWindowEnd, and sets
+// espressoWoken when a pushed header reaches it.
+func (d *Sequencer) consumeEspressoSubscription(sub espresso.HeaderSubscription) {
+	for header := range sub.Headers() {
+		if windowEnd := d.espressoWindowEnd.Load(); windowEnd != 0 && header.Timestamp >= windowEnd {
+			d.espressoWoken.Store(true)
+		}
+	}
+	if err, ok := <-sub.Err(); ok {
+		d.log.Warn("Espresso header subscription ended, falling back to polling", "err", err)
+	}
+}
+
 // updateEspressoBatch appends the transactions contained in the Espresso blocks denoted by
 // `newHeaders` to the current in-progress batch. If `end`, the first block after the window of this
 // batch, is available, it will be saved in the `Next` field of the batch justification.
@@ -138,10 +356,19 @@ func (d *Sequencer) updateEspressoBatch(ctx context.Context, newHeaders []espres
 		if numBlocks != 0 {
 			prev = &blocks[numBlocks-1].Header
 		}
-		if prev != nil && header.Timestamp < prev.Timestamp {
-			// Similarly, this should eventually be an error, but can happen with the current
-			// version of Espresso.
-			d.log.Error("inconsistent data from Espresso query service: header is before its predecessor", "header", header, "prev", prev)
+
+		// Defend against a HotShot that does not honor its own monotonicity guarantees, rather
+		// than trusting it blindly.
+		l1Head, err := d.l1OriginSelector.L1Head(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch current L1 head: %w", err)
+		}
+		l1Finalized, err := d.l1OriginSelector.L1Finalized(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch current finalized L1 block: %w", err)
+		}
+		if err := derive.ValidateEspressoHeader(prev, &header, uint64(d.timeNow().Unix()), l1Finalized, l1Head); err != nil {
+			return err
 		}
 
 		blockNum := batch.jst.From + uint64(numBlocks)
@@ -160,6 +387,22 @@ func (d *Sequencer) updateEspressoBatch(ctx context.Context, newHeaders []espres
 	}
 
 	batch.jst.Next = end
+
+	// Checkpoint in-flight progress so a restart mid-window can resume from the last consumed
+	// HotShot height instead of rescanning the whole window. L1OriginNumber is left at its zero
+	// value here: the L1 origin is only chosen once the batch is sealed, in sealEspressoBatch, which
+	// overwrites this checkpoint with the final one for this window.
+	if len(batch.jst.Blocks) > 0 {
+		if err := d.checkpoints.Save(checkpoint.Checkpoint{
+			Onto:          batch.onto.Hash,
+			LastHeight:    batch.jst.From + uint64(len(batch.jst.Blocks)) - 1,
+			WindowStart:   batch.windowStart,
+			WindowEnd:     batch.windowEnd,
+			Justification: batch.jst,
+		}); err != nil {
+			d.log.Error("failed to checkpoint Espresso sequencing state", "err", err)
+		}
+	}
 	return nil
 }
 
@@ -199,6 +442,14 @@ func (d *Sequencer) sealEspressoBatch(ctx context.Context) (*eth.ExecutionPayloa
 		return nil, fmt.Errorf("failed to fetch suggested L1 origin %d: %w", batch.jst.Next.L1Head, err)
 	}
 	l1OriginNumber := derive.EspressoL1Origin(d.config, batch.onto, suggestedL1Origin)
+	if d.espressoResumeOrigin != nil {
+		if l1OriginNumber < d.espressoResumeOrigin.Number {
+			d.log.Info("resuming Espresso sequencing: adjusting L1 origin up to the block recorded on exit",
+				"suggested", l1OriginNumber, "exitL1Block", *d.espressoResumeOrigin)
+			l1OriginNumber = d.espressoResumeOrigin.Number
+		}
+		d.espressoResumeOrigin = nil
+	}
 	l1Origin := suggestedL1Origin
 	if l1Origin.Number != l1OriginNumber {
 		l1Origin, err = d.l1OriginSelector.FindL1OriginByNumber(ctx, l1OriginNumber)
@@ -242,7 +493,30 @@ func (d *Sequencer) sealEspressoBatch(ctx context.Context) (*eth.ExecutionPayloa
 		_ = d.engine.CancelPayload(ctx, true)
 		return nil, fmt.Errorf("failed to complete building block: error (%d): %w", errTyp, err)
 	}
+
+	// Checkpoint the justification and L1 origin we just sealed, replacing this window's in-flight
+	// checkpoint. restoreEspressoBatch never resumes from a sealed checkpoint: the next window it is
+	// asked about starts where this one ends, so the windows do not match.
+	sealedCheckpoint := checkpoint.Checkpoint{
+		Onto:           batch.onto.Hash,
+		LastHeight:     batch.jst.From + uint64(len(batch.jst.Blocks)) - 1,
+		WindowStart:    batch.windowStart,
+		WindowEnd:      batch.windowEnd,
+		Justification:  batch.jst,
+		L1OriginNumber: l1Origin.Number,
+	}
+	if err := d.checkpoints.Save(sealedCheckpoint); err != nil {
+		d.log.Error("failed to checkpoint Espresso sequencing state", "err", err)
+	}
+	if d.sealedHistory != nil {
+		if err := d.sealedHistory.Append(sealedCheckpoint); err != nil {
+			d.log.Error("failed to append sealed Espresso checkpoint to history", "err", err)
+		}
+	}
+
 	d.espressoBatch = nil
+	d.espressoWindowEnd.Store(0)
+	d.commitPayload(ctx, payload)
 	return payload, nil
 }
 
@@ -298,9 +572,58 @@ func (d *Sequencer) completeBuildingLegacyBlock(ctx context.Context) (*eth.Execu
 	if err != nil {
 		return nil, fmt.Errorf("failed to complete building block: error (%d): %w", errTyp, err)
 	}
+	d.commitPayload(ctx, payload)
 	return payload, nil
 }
 
+// commitPayload hands a freshly sealed payload to the SequencerConductor, if one is configured, so
+// the HA quorum can record it before this replica publishes it. This is a no-op when conductor is
+// nil (single-node deployments). A commit failure is logged rather than bubbled up as an error: by
+// this point the payload is already canonical in the engine, so there is nothing left to roll back,
+// only a gap in the quorum's record to flag.
+func (d *Sequencer) commitPayload(ctx context.Context, payload *eth.ExecutionPayload) {
+	if d.conductor == nil {
+		return
+	}
+	start := d.timeNow()
+	err := d.conductor.CommitUnsafePayload(ctx, payload)
+	d.metrics.RecordSequencerCommitLatency(d.timeNow().Sub(start))
+	if err != nil {
+		d.log.Error("failed to commit sealed payload to sequencer conductor", "payload", payload.ID(), "err", err)
+	}
+}
+
+// checkLeadership consults the SequencerConductor, if one is configured, and reports whether this
+// replica may proceed with sequencing; a Sequencer with no conductor set is always the leader. On a
+// transition from leader to follower, any espressoBatch in progress is discarded (not sealed) and
+// any in-flight legacy block is cancelled, since a replica that has lost its lease must not publish
+// a payload another replica may also be about to publish.
+func (d *Sequencer) checkLeadership(ctx context.Context) (bool, error) {
+	if d.conductor == nil {
+		return true, nil
+	}
+	leader, err := d.conductor.Leader(ctx)
+	if err != nil {
+		return false, err
+	}
+	if leader != d.wasLeader {
+		d.metrics.RecordSequencerLeadershipTransition(leader)
+		d.wasLeader = leader
+	}
+	if !leader {
+		if d.espressoBatch != nil {
+			d.log.Warn("lost sequencer leadership mid-window, discarding in-progress Espresso batch", "onto", d.espressoBatch.onto)
+			d.espressoBatch = nil
+			d.espressoWindowEnd.Store(0)
+		}
+		if _, buildingID, _ := d.engine.BuildingPayload(); buildingID != (eth.PayloadID{}) {
+			d.log.Warn("lost sequencer leadership mid-block, cancelling in-progress legacy block")
+			d.cancelBuildingLegacyBlock(ctx)
+		}
+	}
+	return leader, nil
+}
+
 // CancelBuildingBlock cancels the current open block building job.
 // This sequencer only maintains one block building job at a time.
 func (d *Sequencer) cancelBuildingLegacyBlock(ctx context.Context) {
@@ -341,12 +664,15 @@ func (d *Sequencer) planNextEspressoSequencerAction() time.Duration {
 	// which case we need to respond immediately.
 	delay := d.nextAction.Sub(now)
 	reorg := d.espressoBatch != nil && d.espressoBatch.onto.Hash != head.Hash
-	if delay > 0 && !reorg {
+	// woken is true if consumeEspressoSubscription saw a pushed header reach the current window's
+	// end since we last checked; Swap clears it so it is only ever consumed once.
+	woken := d.espressoWoken.Swap(false)
+	if delay > 0 && !reorg && !woken {
 		return delay
 	}
 
-	// In case there has been a reorg or the previous action did not set a delay, run the next
-	// action immediately.
+	// In case there has been a reorg, a pushed header woke us early, or the previous action did not
+	// set a delay, run the next action immediately.
 	return 0
 }
 
@@ -388,6 +714,31 @@ func (d *Sequencer) planNextLegacySequencerAction() time.Duration {
 	}
 }
 
+// Priority reports whether the sequencer has work urgent enough that the driver's outer event loop
+// should run RunNextSequencerAction ahead of its other steps (derivation, finality, L1 polling)
+// rather than round-robining them, when priority scheduling has been enabled via SetPriority: an
+// Espresso batch that is ready to seal, or a legacy block close enough to its deadline that sealing
+// can no longer wait. Sequencer has no outer event loop to preempt; this is the extension point, for
+// such a loop to consult once it exists.
+func (d *Sequencer) Priority() bool {
+	if !d.priorityEnabled {
+		return false
+	}
+	if d.espressoBatch != nil && d.espressoBatch.complete() {
+		return true
+	}
+	if d.mode != Legacy {
+		return false
+	}
+	buildingOnto, buildingID, _ := d.engine.BuildingPayload()
+	head := d.engine.UnsafeL2Head()
+	if buildingID == (eth.PayloadID{}) || buildingOnto.Hash != head.Hash {
+		return false
+	}
+	remainingTime := time.Unix(int64(head.Time+d.config.BlockTime), 0).Sub(d.timeNow())
+	return remainingTime < sealingDuration
+}
+
 // BuildingOnto returns the L2 head reference that the latest block is or was being built on top of.
 func (d *Sequencer) BuildingOnto() eth.L2BlockRef {
 	if d.espressoBatch != nil {
@@ -459,6 +810,13 @@ func (d *Sequencer) RunNextSequencerAction(ctx context.Context) (*eth.ExecutionP
 		return nil, nil
 	}
 
+	if leader, err := d.checkLeadership(ctx); err != nil {
+		return nil, d.handleNonEngineError("checking sequencer conductor leadership", err)
+	} else if !leader {
+		d.nextAction = d.timeNow().Add(time.Second)
+		return nil, nil
+	}
+
 	switch d.mode {
 	case Espresso:
 		return d.buildEspressoBatch(ctx)
@@ -481,8 +839,11 @@ func (d *Sequencer) buildEspressoBatch(ctx context.Context) (*eth.ExecutionPaylo
 	if d.espressoBatch != nil && d.espressoBatch.onto.Hash != head.Hash {
 		d.log.Warn("reorg detected", "head", head, "onto", d.espressoBatch.onto)
 		d.espressoBatch = nil
+		d.espressoWindowEnd.Store(0)
 	}
 
+	d.ensureEspressoSubscription(ctx)
+
 	// Begin a new block if necessary.
 	if d.espressoBatch == nil {
 		d.log.Info("building new Espresso batch", "onto", head)
@@ -506,6 +867,7 @@ func (d *Sequencer) buildEspressoBatch(ctx context.Context) (*eth.ExecutionPaylo
 		// If we did seal the block, return it and do not set a delay, so that the scheduler will
 		// start the next action (starting the next block) immediately.
 		d.log.Info("sealed Espresso batch", "payload", block)
+		d.resetRetryBackoff()
 		return block, nil
 	}
 }
@@ -524,17 +886,18 @@ func (d *Sequencer) buildLegacyBlock(ctx context.Context, building bool) (*eth.E
 				d.engine.Reset()
 			} else if errors.Is(err, derive.ErrTemporary) {
 				d.log.Error("sequencer failed temporarily to seal new block", "err", err)
-				d.nextAction = d.timeNow().Add(time.Second)
+				d.scheduleRetry(err)
 				// We don't explicitly cancel block building jobs upon temporary errors: we may still finish the block.
 				// Any unfinished block building work eventually times out, and will be cleaned up that way.
 			} else {
 				d.log.Error("sequencer failed to seal block with unclassified error", "err", err)
-				d.nextAction = d.timeNow().Add(time.Second)
+				d.scheduleRetry(err)
 				d.cancelBuildingLegacyBlock(ctx)
 			}
 			return nil, nil
 		} else {
 			d.log.Info("sequencer successfully built a new block", "block", payload.ID(), "time", uint64(payload.Timestamp), "txs", len(payload.Transactions))
+			d.resetRetryBackoff()
 			return payload, nil
 		}
 	} else {
@@ -562,6 +925,7 @@ func (d *Sequencer) detectMode(ctx context.Context) error {
 		d.log.Info("OP sequencer running in legacy mode")
 		d.mode = Legacy
 	}
+	d.resetRetryBackoff()
 	return nil
 }
 
@@ -584,10 +948,31 @@ func (d *Sequencer) handleNonEngineError(action string, err error) error {
 		return err
 	} else if errors.Is(err, derive.ErrTemporary) {
 		d.log.Error("sequencer encountered temporary error", "action", action, "err", err)
-		d.nextAction = d.timeNow().Add(time.Second)
+		d.scheduleRetry(err)
 	} else {
 		d.log.Error("sequencer encountered unclassified error", "action", action, "err", err)
-		d.nextAction = d.timeNow().Add(time.Second)
+		d.scheduleRetry(err)
 	}
 	return nil
 }
+
+// scheduleRetry sets nextAction to retry after a non-critical, non-reset error, using retryPolicy if
+// one has been configured via SetRetryPolicy, or this package's original flat one-second delay
+// otherwise. It increments retryAttempt on every call; resetRetryBackoff zeroes it again wherever
+// the sequencer makes real progress, so a persistently failing upstream is the only way attempt
+// climbs, not an ordinary mix of occasional errors between successful blocks.
+func (d *Sequencer) scheduleRetry(err error) {
+	d.retryAttempt++
+	delay := time.Second
+	if d.retryPolicy != nil {
+		delay = d.retryPolicy.Next(d.retryAttempt, err)
+	}
+	d.metrics.RecordSequencerRetryBackoff(d.retryAttempt, delay)
+	d.nextAction = d.timeNow().Add(delay)
+}
+
+// resetRetryBackoff zeroes retryAttempt. It is called wherever the sequencer makes real progress:
+// determining its mode, or producing a block.
+func (d *Sequencer) resetRetryBackoff() {
+	d.retryAttempt = 0
+}
@@ -13,12 +13,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/require"
 
-	"github.com/ethereum-optimism/optimism/op-node/metrics"
 	"github.com/ethereum-optimism/optimism/op-node/rollup"
 	"github.com/ethereum-optimism/optimism/op-node/rollup/derive"
+	"github.com/ethereum-optimism/optimism/op-node/rollup/driver/checkpoint"
 	"github.com/ethereum-optimism/optimism/op-service/espresso"
 	"github.com/ethereum-optimism/optimism/op-service/eth"
 	"github.com/ethereum-optimism/optimism/op-service/testlog"
@@ -143,16 +144,54 @@ type FakeEspressoClient struct {
 type FakeEspressoBlock struct {
 	Header       espresso.Header
 	Transactions []espresso.Bytes
+	Proof        espresso.NmtProof
+}
+
+// nmtNodeEncode mirrors the unexported encoding espresso.NmtProof.Verify uses internally, so the
+// mock tree built by nextEspressoBlock hashes the same way a verifier will reconstruct it.
+func nmtNodeEncode(min, max uint64, digest []byte) []byte {
+	buf := make([]byte, 16, 16+len(digest))
+	binary.BigEndian.PutUint64(buf[0:8], min)
+	binary.BigEndian.PutUint64(buf[8:16], max)
+	return append(buf, digest...)
+}
+
+// buildNmtProof computes a real namespaced-tree root and proof covering every transaction in txs.
+// Every transaction generated by this mock chain shares one namespace, so the resulting proof
+// never needs any siblings.
+func buildNmtProof(namespace uint64, txs []espresso.Bytes) (espresso.NmtRoot, espresso.NmtProof) {
+	proof := espresso.NmtProof{Namespace: namespace, Start: 0, End: uint64(len(txs))}
+	if len(txs) == 0 {
+		return espresso.NmtRoot{Root: make([]byte, 32)}, proof
+	}
+	digest := crypto.Keccak256(txs[0])
+	for _, tx := range txs[1:] {
+		left := nmtNodeEncode(namespace, namespace, digest)
+		right := nmtNodeEncode(namespace, namespace, crypto.Keccak256(tx))
+		digest = crypto.Keccak256(left, right)
+	}
+	return espresso.NmtRoot{Root: digest}, proof
+}
+
+// sequencerDriver is the subset of SwitchSequencer/Sequencer that SequencerChaosMonkey drives,
+// so the same chaos loop can run against either a bare Sequencer or one wrapped in a
+// SwitchSequencer.
+type sequencerDriver interface {
+	PlanNextSequencerAction() time.Duration
+	RunNextSequencerAction(ctx context.Context) (*eth.ExecutionPayload, error)
 }
 
 type TestSequencer struct {
 	t   *testing.T
 	rng *rand.Rand
 
-	cfg        rollup.Config
-	seq        *Sequencer
-	engControl FakeEngineControl
-	espresso   *FakeEspressoClient
+	cfg         rollup.Config
+	seq         *Sequencer
+	switchSeq   *SwitchSequencer
+	driver      sequencerDriver
+	engControl  FakeEngineControl
+	espresso    *FakeEspressoClient
+	checkpoints *checkpoint.MemStore
 
 	clockTime time.Time
 	clockFn   func() time.Time
@@ -161,6 +200,86 @@ type TestSequencer struct {
 	attrsErr    error
 	originErr   error
 	espressoErr error
+
+	// l1HeadOverride and l1FinalizedOverride, when non-nil, are returned by L1Head/L1Finalized
+	// instead of the default "seen everything generated so far" bound, so tests can exercise
+	// ValidateEspressoHeader's unknown-origin and not-yet-finalized checks directly. Most tests
+	// (including the chaos monkey) never set these, so those checks stay as permissive as the mock
+	// chain's own generation, exactly as before these bounds were threaded through for real.
+	l1HeadOverride      *eth.L1BlockRef
+	l1FinalizedOverride *eth.L1BlockRef
+
+	// corruptLeaf, when set, makes the next FetchTransactionsInBlock call tamper with one
+	// transaction's bytes before checking it against the stored NMT proof, simulating a query
+	// service that has corrupted or omitted namespace data.
+	corruptLeaf bool
+
+	// hotShotLive is read by IsHotShotLive to fake the HotShot light client's liveness, for
+	// SwitchSequencer tests.
+	hotShotLive bool
+
+	// modeTransitions records every "to" mode passed to RecordSequencerModeTransition, in order, so
+	// SwitchSequencer tests can assert that a metric was actually recorded for each mode flip the
+	// chaos monkey injected.
+	modeTransitions []SequencerMode
+
+	// shadowDivergences records every L2 block number passed to RecordSequencerShadowDivergence,
+	// for MigratingSequencer shadow-mode tests.
+	shadowDivergences []uint64
+
+	// espressoQueryDivergences records every height passed to RecordEspressoQueryDivergence, for
+	// espresso.ClientList divergence tests.
+	espressoQueryDivergences []uint64
+
+	// leadershipTransitions records every "leader" value passed to RecordSequencerLeadershipTransition,
+	// for SequencerConductor tests.
+	leadershipTransitions []bool
+	// commitLatencies records every duration passed to RecordSequencerCommitLatency.
+	commitLatencies []time.Duration
+
+	// retryBackoffs records every (attempt, delay) pair passed to RecordSequencerRetryBackoff, for
+	// RetryPolicy tests.
+	retryBackoffs []retryBackoff
+
+	// espressoSubCh, once non-nil, is the channel returned by Subscribe; tests push headers into it
+	// directly to simulate the query service pushing a new header, rather than going through
+	// nextEspressoBlock and waiting for a poll.
+	espressoSubCh chan espresso.Header
+}
+
+// IsHotShotLive implements LightClientReader for SwitchSequencer tests.
+func (s *TestSequencer) IsHotShotLive(delayThresholdSeconds uint64) (bool, error) {
+	return s.hotShotLive, nil
+}
+
+// Implement SequencerMetrics interface for TestSequencer.
+
+func (s *TestSequencer) RecordSequencerInconsistentL1Origin(from eth.BlockID, to eth.BlockID) {}
+func (s *TestSequencer) RecordSequencerReset()                                                {}
+func (s *TestSequencer) RecordSequencerModeTransition(from SequencerMode, to SequencerMode) {
+	s.modeTransitions = append(s.modeTransitions, to)
+}
+func (s *TestSequencer) RecordSequencerShadowDivergence(l2BlockNumber uint64) {
+	s.shadowDivergences = append(s.shadowDivergences, l2BlockNumber)
+}
+func (s *TestSequencer) RecordEspressoQueryDivergence(height uint64) {
+	s.espressoQueryDivergences = append(s.espressoQueryDivergences, height)
+}
+func (s *TestSequencer) RecordSequencerLeadershipTransition(leader bool) {
+	s.leadershipTransitions = append(s.leadershipTransitions, leader)
+}
+func (s *TestSequencer) RecordSequencerCommitLatency(d time.Duration) {
+	s.commitLatencies = append(s.commitLatencies, d)
+}
+
+// retryBackoff is one observed call to RecordSequencerRetryBackoff.
+type retryBackoff struct {
+	attempt int
+	delay   time.Duration
+}
+
+func (s *TestSequencer) RecordSequencerRetryBackoff(attempt int, delay time.Duration) {
+	s.retryBackoffs = append(s.retryBackoffs, retryBackoff{attempt, delay})
 }
 
 // Implement AttributeBuilder interface for TestSequencer.
@@ -302,6 +421,33 @@ func (s *TestSequencer) nextOrigin(prevOrigin eth.L1BlockRef, prevL2Time uint64,
 	return nextOrigin
 }
 
+// L1Head implements L1OriginSelectorIface for TestSequencer. Absent an override, it reports an
+// effectively unbounded head, so headers produced by nextEspressoBlock (which may reference an L1
+// origin generated on the fly) are never rejected as unknown unless a test opts in via
+// l1HeadOverride.
+func (s *TestSequencer) L1Head(ctx context.Context) (eth.L1BlockRef, error) {
+	if s.originErr != nil {
+		return eth.L1BlockRef{}, s.originErr
+	}
+	if s.l1HeadOverride != nil {
+		return *s.l1HeadOverride, nil
+	}
+	return eth.L1BlockRef{Number: ^uint64(0)}, nil
+}
+
+// L1Finalized implements L1OriginSelectorIface for TestSequencer. Absent an override, it reports
+// the same effectively unbounded bound as L1Head, so existing tests never see temporary errors from
+// not-yet-finalized origins unless a test opts in via l1FinalizedOverride.
+func (s *TestSequencer) L1Finalized(ctx context.Context) (eth.L1BlockRef, error) {
+	if s.originErr != nil {
+		return eth.L1BlockRef{}, s.originErr
+	}
+	if s.l1FinalizedOverride != nil {
+		return *s.l1FinalizedOverride, nil
+	}
+	return eth.L1BlockRef{Number: ^uint64(0)}, nil
+}
+
 var _ L1OriginSelectorIface = (*TestSequencer)(nil)
 
 // Implement EspressoL1Provider interface for TestSequencer.
@@ -397,9 +543,22 @@ func (s *TestSequencer) FetchTransactionsInBlock(ctx context.Context, block uint
 		return espresso.TransactionsInBlock{}, fmt.Errorf("wrong header for block %d header %v expected %v", block, header, s.espresso.Blocks[block].Header)
 	}
 	txs := s.espresso.Blocks[block].Transactions
-
-	// Fake an NMT proof.
-	proof := espresso.NmtProof{}
+	proof := s.espresso.Blocks[block].Proof
+	if s.corruptLeaf && len(txs) > 0 {
+		tampered := append(espresso.Bytes{}, txs[0]...)
+		if len(tampered) > 0 {
+			tampered[0] ^= 0xff
+		}
+		corrupted := append([]espresso.Bytes{}, txs...)
+		corrupted[0] = tampered
+		txs = corrupted
+	}
+	// Verify the proof against the namespaced tree root, the same way espresso.Client's
+	// NamespaceResponse.Validate does for a real query service, so a corrupted mock tree (e.g. the
+	// chaos monkey's flipped-leaf fault) is caught here rather than silently passed through.
+	if err := proof.Verify(header.TransactionsRoot, namespace, txs); err != nil {
+		return espresso.TransactionsInBlock{}, fmt.Errorf("invalid nmt proof for block %d: %w", block, err)
+	}
 	return espresso.TransactionsInBlock{
 		Transactions: txs,
 		Proof:        proof,
@@ -443,12 +602,6 @@ func (s *TestSequencer) nextEspressoBlock() *espresso.Header {
 		timestamp = now
 	}
 
-	// Fake an NMT root, but ensure it is unique.
-	root := espresso.NmtRoot{
-		Root: make([]byte, 8),
-	}
-	binary.LittleEndian.PutUint64(root.Root, uint64(len(s.espresso.Blocks)))
-
 	var l1OriginNumber uint64
 	if s.espresso.AdvanceL1Origin {
 		l1OriginNumber = prev.L1Head + 1
@@ -495,6 +648,16 @@ func (s *TestSequencer) nextEspressoBlock() *espresso.Header {
 		timestamp = prev.Timestamp - 1
 	}
 
+	// Randomly generate between 0 and 20 transactions.
+	txs := make([]espresso.Bytes, 0)
+	for i := 0; i < s.rng.Intn(20); i++ {
+		txs = append(txs, []byte(fmt.Sprintf("mock sequenced tx %d", i)))
+	}
+
+	// Build a real (mock) NMT root and proof, rather than faking them, so the derivation pipeline
+	// actually exercises NMT verification against this test's transactions.
+	root, proof := buildNmtProof(s.cfg.L2ChainID.Uint64(), txs)
+
 	header := espresso.Header{
 		TransactionsRoot: root,
 		Metadata: espresso.Metadata{
@@ -503,21 +666,42 @@ func (s *TestSequencer) nextEspressoBlock() *espresso.Header {
 		},
 	}
 
-	// Randomly generate between 0 and 20 transactions.
-	txs := make([]espresso.Bytes, 0)
-	for i := 0; i < s.rng.Intn(20); i++ {
-		txs = append(txs, []byte(fmt.Sprintf("mock sequenced tx %d", i)))
-	}
-
 	s.espresso.Blocks = append(s.espresso.Blocks, FakeEspressoBlock{
 		Header:       header,
 		Transactions: txs,
+		Proof:        proof,
 	})
 	return &header
 }
 
 var _ espresso.QueryService = (*TestSequencer)(nil)
 
+// SubscribeHeaders implements espresso.Subscriber for TestSequencer, so Sequencer tests can exercise
+// push-based scheduling without standing up a real subscription. The channel is created lazily so
+// that tests exercising only the polling path never have to know about it.
+func (s *TestSequencer) SubscribeHeaders(ctx context.Context, fromHeight uint64) (espresso.HeaderSubscription, error) {
+	if s.espressoErr != nil {
+		return nil, s.espressoErr
+	}
+	if s.espressoSubCh == nil {
+		s.espressoSubCh = make(chan espresso.Header, 16)
+	}
+	return &testHeaderSubscription{headers: s.espressoSubCh}, nil
+}
+
+// testHeaderSubscription is the espresso.HeaderSubscription TestSequencer.SubscribeHeaders hands
+// back: it never fails or gets unsubscribed on its own, since tests drive its channel directly
+// rather than exercising failure or shutdown behavior.
+type testHeaderSubscription struct {
+	headers chan espresso.Header
+}
+
+func (s *testHeaderSubscription) Headers() <-chan espresso.Header { return s.headers }
+func (s *testHeaderSubscription) Err() <-chan error                { return nil }
+func (s *testHeaderSubscription) Unsubscribe()                     {}
+
+var _ espresso.Subscriber = (*TestSequencer)(nil)
+
 func mockL1Hash(num uint64) (out common.Hash) {
 	out[31] = 1
 	binary.BigEndian.PutUint64(out[:], num)
@@ -612,12 +796,26 @@ func SetupSequencer(t *testing.T, useEspresso bool) *TestSequencer {
 		s.espresso = new(FakeEspressoClient)
 	}
 
-	s.seq = NewSequencer(log, &s.cfg, &s.engControl, s, s, s, metrics.NoopMetrics)
+	s.checkpoints = checkpoint.NewMemStore()
+	s.seq = NewSequencer(log, &s.cfg, &s.engControl, s, s, s, s, s.checkpoints)
 	s.seq.timeNow = s.clockFn
+	s.driver = s.seq
 
 	return s
 }
 
+// restartSequencer simulates an op-node restart: it replaces s.seq with a freshly constructed
+// Sequencer sharing the same engine, Espresso client and checkpoint store as before, so that any
+// checkpointed Espresso batch progress is picked up on the next build instead of being rescanned
+// from the L2 safe head. It does not inherit s.seq's in-memory mode or espressoBatch, mirroring
+// what actually survives a process restart.
+func (s *TestSequencer) restartSequencer() {
+	log := testlog.Logger(s.t, log.LvlCrit)
+	s.seq = NewSequencer(log, &s.cfg, &s.engControl, s, s, s, s, s.checkpoints)
+	s.seq.timeNow = s.clockFn
+	s.driver = s.seq
+}
+
 // SequencerChaosMonkey runs the sequencer in a mocked adversarial environment with
 // repeated random errors in dependencies and poor clock timing.
 // At the end the health of the chain is checked to show that the sequencer kept the chain in shape.
@@ -627,7 +825,21 @@ func SequencerChaosMonkey(s *TestSequencer) {
 	// try to build 1000 blocks, with 5x as many planning attempts, to handle errors and clock problems
 	desiredBlocks := 1000
 	for i := 0; i < 5*desiredBlocks && s.engControl.totalBuiltBlocks < desiredBlocks; i++ {
-		delta := s.seq.PlanNextSequencerAction()
+		// If we're testing the SwitchSequencer, occasionally flip the fake light client's
+		// liveness, simulating the background poll loop without depending on real wallclock
+		// timers, so the test stays deterministic.
+		if s.switchSeq != nil && s.rng.Intn(20) == 0 { // 5% chance per iteration
+			s.hotShotLive = !s.hotShotLive
+			s.switchSeq.mu.Lock()
+			if s.hotShotLive {
+				s.switchSeq.desiredMode = Espresso
+			} else {
+				s.switchSeq.desiredMode = Legacy
+			}
+			s.switchSeq.mu.Unlock()
+		}
+
+		delta := s.driver.PlanNextSequencerAction()
 
 		x := s.rng.Float32()
 		if x < 0.01 { // 1%: mess a lot with the clock: simulate a hang of up to 30 seconds
@@ -646,6 +858,7 @@ func SequencerChaosMonkey(s *TestSequencer) {
 		s.originErr = nil
 		s.attrsErr = nil
 		s.espressoErr = nil
+		s.corruptLeaf = false
 		if s.engControl.err != mockResetErr { // the mockResetErr requires the sequencer to Reset() to recover.
 			s.engControl.err = nil
 		}
@@ -667,10 +880,22 @@ func SequencerChaosMonkey(s *TestSequencer) {
 			s.engControl.err = mockResetErr
 		case 9:
 			s.espressoErr = errors.New("mock espresso client error")
+		case 10:
+			// Corrupt a leaf of the next Espresso block fetched, so the NMT proof verification
+			// added by FetchTransactionsInBlock has something real to reject. This fault is a
+			// no-op outside of Espresso mode.
+			s.corruptLeaf = true
+		case 11:
+			// Simulate an op-node restart: rebuild the Sequencer against the same engine, Espresso
+			// client and checkpoint store. A bare restart drops switchSeq's own desiredMode/exitL1Block
+			// state, so this fault is skipped when testing SwitchSequencer.
+			if s.switchSeq == nil {
+				s.restartSequencer()
+			}
 		default:
 			// no error
 		}
-		payload, err := s.seq.RunNextSequencerAction(context.Background())
+		payload, err := s.driver.RunNextSequencerAction(context.Background())
 		require.NoError(t, err)
 		if payload != nil {
 			require.Equal(t, s.engControl.UnsafeL2Head().ID(), payload.ID(), "head must stay in sync with emitted payloads")
@@ -804,3 +1029,349 @@ func TestSequencerChaosMonkeyEspresso(t *testing.T) {
 	t.Logf("Skipped L1 origin:    %d", skippedL1Origin)
 	t.Logf("Decreasing L1 origin: %d", decreasingL1Origin)
 }
+
+// TestSequencerChaosMonkeySwitch runs the chaos monkey against a SwitchSequencer whose fake
+// light client's liveness is randomly toggled throughout the run, asserting that the driver keeps
+// l2Head.Time within the same tolerance of wallclock as the pure-Espresso case despite repeatedly
+// falling back to, and recovering from, legacy sequencing.
+func TestSequencerChaosMonkeySwitch(t *testing.T) {
+	s := SetupSequencer(t, true)
+	s.hotShotLive = true
+	s.switchSeq = NewSwitchSequencer(s.seq, s, SwitchConfig{DelayThreshold: 30, PollInterval: time.Second})
+	s.driver = s.switchSeq
+
+	SequencerChaosMonkey(s)
+
+	l2Head := s.engControl.UnsafeL2Head()
+	require.Less(t, s.clockTime.Sub(time.Unix(int64(l2Head.Time), 0)).Abs(), 12*time.Second, "L2 time is accurate, within 12 seconds of wallclock")
+
+	// Every liveness flip the chaos monkey actually applied (i.e. every one that landed on a
+	// different mode than we were already in) must have produced exactly one recorded transition,
+	// alternating between the two modes.
+	require.NotEmpty(t, s.modeTransitions, "a run long enough to hit the 5% liveness-flip chance should record at least one mode transition")
+	for i := 1; i < len(s.modeTransitions); i++ {
+		require.NotEqual(t, s.modeTransitions[i-1], s.modeTransitions[i], "consecutive recorded transitions must alternate mode, not repeat")
+	}
+}
+
+// TestSwitchSequencerManualOverride asserts that SwitchToCentralized/SwitchToEspresso take effect
+// on the next driver-loop entry point, without waiting for a light client poll, and that a
+// subsequent poll can still override them once it runs.
+func TestSwitchSequencerManualOverride(t *testing.T) {
+	s := SetupSequencer(t, true)
+	s.hotShotLive = true
+	switchSeq := NewSwitchSequencer(s.seq, s, SwitchConfig{DelayThreshold: 30, PollInterval: time.Hour})
+	// First action just detects the mode; SwitchSequencer leaves an undetected (Unknown) mode alone.
+	_, err := switchSeq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Espresso, switchSeq.mode)
+
+	switchSeq.SwitchToCentralized()
+	switchSeq.PlanNextSequencerAction()
+	require.Equal(t, Legacy, switchSeq.mode, "SwitchToCentralized should take effect without a light client poll")
+
+	switchSeq.SwitchToEspresso()
+	switchSeq.PlanNextSequencerAction()
+	require.Equal(t, Espresso, switchSeq.mode, "SwitchToEspresso should take effect without a light client poll")
+
+	require.Equal(t, []SequencerMode{Legacy, Espresso}, s.modeTransitions, "both manual overrides should have recorded a mode transition")
+}
+
+// TestSwitchSequencerRecoveryWindow asserts that SwitchSequencer only switches back from legacy to
+// Espresso sequencing once the light client has reported HotShot live continuously for
+// RecoveryWindow, and that a single non-live poll in between resets the streak.
+func TestSwitchSequencerRecoveryWindow(t *testing.T) {
+	s := SetupSequencer(t, true)
+	switchSeq := NewSwitchSequencer(s.seq, s, SwitchConfig{DelayThreshold: 30, PollInterval: time.Second, RecoveryWindow: 30 * time.Second})
+	switchSeq.desiredMode = Legacy
+	switchSeq.mode = Legacy
+
+	switchSeq.observeLiveness(true)
+	require.Equal(t, Legacy, switchSeq.desiredMode, "should not switch back on the very first live poll")
+
+	s.clockTime = s.clockTime.Add(10 * time.Second)
+	switchSeq.observeLiveness(true)
+	require.Equal(t, Legacy, switchSeq.desiredMode, "should not switch back before RecoveryWindow has elapsed")
+
+	s.clockTime = s.clockTime.Add(10 * time.Second)
+	switchSeq.observeLiveness(false)
+	require.Equal(t, Legacy, switchSeq.desiredMode, "a non-live poll should reset the live streak")
+
+	s.clockTime = s.clockTime.Add(30 * time.Second)
+	switchSeq.observeLiveness(true)
+	require.Equal(t, Legacy, switchSeq.desiredMode, "the reset streak must restart from this poll, not credit time from before the interruption")
+
+	s.clockTime = s.clockTime.Add(30 * time.Second)
+	switchSeq.observeLiveness(true)
+	require.Equal(t, Espresso, switchSeq.desiredMode, "should switch back once live continuously for RecoveryWindow")
+}
+
+// TestExponentialBackoffRespectsMaxAndJitter asserts that ExponentialBackoff's delay never exceeds
+// Max plus its configured jitter, across a range of attempt counts large enough to overflow an
+// unbounded doubling.
+func TestExponentialBackoffRespectsMaxAndJitter(t *testing.T) {
+	cfg := ExponentialBackoffConfig{Base: 10 * time.Millisecond, Max: 100 * time.Millisecond, JitterFraction: 0.5}
+	b := NewExponentialBackoff(cfg)
+	ceiling := cfg.Max + time.Duration(float64(cfg.Max)*cfg.JitterFraction)
+	for attempt := 1; attempt <= 40; attempt++ {
+		d := b.Next(attempt, nil)
+		require.GreaterOrEqual(t, d, time.Duration(0))
+		require.LessOrEqual(t, d, ceiling)
+	}
+}
+
+// fakeRetryPolicy records every attempt count it is asked about, and always returns delay.
+type fakeRetryPolicy struct {
+	delay    time.Duration
+	attempts []int
+}
+
+func (f *fakeRetryPolicy) Next(attempt int, lastErr error) time.Duration {
+	f.attempts = append(f.attempts, attempt)
+	return f.delay
+}
+
+// TestSequencerRetryPolicyBackoffAndReset asserts that Sequencer counts consecutive failures
+// through a configured RetryPolicy, recording each via RecordSequencerRetryBackoff, and that the
+// count resets to zero the next time an action makes real progress.
+func TestSequencerRetryPolicyBackoffAndReset(t *testing.T) {
+	s := SetupSequencer(t, false)
+	fake := &fakeRetryPolicy{delay: 5 * time.Second}
+	s.seq.SetRetryPolicy(fake)
+
+	s.attrsErr = errors.New("mock mode detection error")
+	for i := 0; i < 3; i++ {
+		_, err := s.seq.RunNextSequencerAction(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, []int{1, 2, 3}, fake.attempts, "each consecutive failure should increment the attempt count")
+	require.Equal(t, 3, s.seq.retryAttempt)
+	require.Len(t, s.retryBackoffs, 3)
+	for _, b := range s.retryBackoffs {
+		require.Equal(t, fake.delay, b.delay)
+	}
+
+	s.attrsErr = nil
+	_, err := s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Legacy, s.seq.mode)
+	require.Equal(t, 0, s.seq.retryAttempt, "a successful mode detection should reset the retry backoff")
+}
+
+// TestMigratingSequencerScheduledActivation asserts that a MigratingSequencer configured with an
+// ActivationTime migrates from Legacy to Espresso sequencing exactly once, no earlier than the
+// first L2 block whose timestamp reaches that time, and never reverts.
+func TestMigratingSequencerScheduledActivation(t *testing.T) {
+	s := SetupSequencer(t, false)
+	// Shadow comparisons need Espresso blocks to read, even though the sequencer itself stays in
+	// Legacy mode until the scheduled activation.
+	s.espresso = new(FakeEspressoClient)
+
+	_, err := s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Legacy, s.seq.mode, "genesis system config disables Espresso, so the sequencer starts in legacy mode")
+
+	activationTime := s.cfg.Genesis.L2Time + 6*s.cfg.BlockTime
+	migrating := NewMigratingSequencer(s.seq, MigrationConfig{ActivationTime: &activationTime, Shadow: true})
+	s.driver = migrating
+
+	for i := 0; i < 30 && migrating.mode != Espresso; i++ {
+		delay := migrating.PlanNextSequencerAction()
+		s.clockTime = s.clockTime.Add(delay)
+		_, err := migrating.RunNextSequencerAction(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, Espresso, migrating.mode, "should have migrated to Espresso by the activation timestamp")
+	require.GreaterOrEqual(t, s.engControl.UnsafeL2Head().Time, activationTime-s.cfg.BlockTime, "should not migrate before a block at or past the activation time")
+	require.Equal(t, []SequencerMode{Espresso}, s.modeTransitions, "exactly one mode transition should have been recorded")
+
+	// Run a few more actions in Espresso mode and confirm the migration never reverts, regardless
+	// of how PlanNextSequencerAction/RunNextSequencerAction are driven from here.
+	for i := 0; i < 10; i++ {
+		delay := migrating.PlanNextSequencerAction()
+		s.clockTime = s.clockTime.Add(delay)
+		_, err := migrating.RunNextSequencerAction(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, Espresso, migrating.mode, "migration is one-way and must not revert to legacy mode")
+	}
+}
+
+// fakeSequencerConductor is a minimal SequencerConductor whose leadership can be flipped mid-test.
+type fakeSequencerConductor struct {
+	leader    bool
+	committed []*eth.ExecutionPayload
+}
+
+func (f *fakeSequencerConductor) Leader(ctx context.Context) (bool, error) {
+	return f.leader, nil
+}
+
+func (f *fakeSequencerConductor) CommitUnsafePayload(ctx context.Context, payload *eth.ExecutionPayload) error {
+	f.committed = append(f.committed, payload)
+	return nil
+}
+
+// TestSequencerConductorGatesSequencing asserts that a Sequencer with a SequencerConductor commits
+// every payload it seals while leader, refuses to build or seal while not leader, discards
+// in-progress work the moment leadership is lost, and resumes normal sequencing upon regaining it.
+func TestSequencerConductorGatesSequencing(t *testing.T) {
+	s := SetupSequencer(t, false)
+	conductor := &fakeSequencerConductor{leader: true}
+	s.seq.SetConductor(conductor)
+
+	// First action just detects the mode.
+	_, err := s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Legacy, s.seq.mode)
+
+	for len(conductor.committed) == 0 {
+		delay := s.seq.PlanNextSequencerAction()
+		s.clockTime = s.clockTime.Add(delay)
+		_, err := s.seq.RunNextSequencerAction(context.Background())
+		require.NoError(t, err)
+	}
+	require.Len(t, conductor.committed, 1, "sealing a block while leader should commit it to the conductor")
+	require.Equal(t, []bool{true}, s.leadershipTransitions, "becoming leader for the first time should record one transition")
+
+	// Start building the next block, then lose leadership before it can be sealed.
+	delay := s.seq.PlanNextSequencerAction()
+	s.clockTime = s.clockTime.Add(delay)
+	_, err = s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	_, buildingID, _ := s.engControl.BuildingPayload()
+	require.NotEqual(t, eth.PayloadID{}, buildingID, "should have started building a block")
+
+	conductor.leader = false
+	_, err = s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	_, buildingID, _ = s.engControl.BuildingPayload()
+	require.Equal(t, eth.PayloadID{}, buildingID, "losing leadership mid-block should cancel the in-progress build")
+	require.Equal(t, []bool{true, false}, s.leadershipTransitions)
+	require.Len(t, conductor.committed, 1, "no additional payload should have been committed while not leader")
+
+	conductor.leader = true
+	for len(conductor.committed) < 2 {
+		delay := s.seq.PlanNextSequencerAction()
+		s.clockTime = s.clockTime.Add(delay)
+		_, err := s.seq.RunNextSequencerAction(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, []bool{true, false, true}, s.leadershipTransitions, "regaining leadership should resume normal sequencing and record a third transition")
+}
+
+// TestSequencerPriority asserts that Sequencer.Priority only ever reports true once enabled via
+// SetPriority, and then only for work urgent enough to preempt other driver steps: a legacy block
+// past its sealing deadline, or a Espresso batch that is already complete and ready to seal.
+func TestSequencerPriority(t *testing.T) {
+	s := SetupSequencer(t, false)
+
+	_, err := s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Legacy, s.seq.mode)
+	require.False(t, s.seq.Priority(), "priority scheduling is disabled by default")
+
+	s.seq.SetPriority(true)
+	require.False(t, s.seq.Priority(), "nothing in flight yet, nothing to prioritize")
+
+	// SetupSequencer starts the clock five minutes past the L2 head; move it back to the head's own
+	// time, so a block started now has a full block time before its sealing deadline.
+	s.clockTime = time.Unix(int64(s.engControl.unsafe.Time), 0)
+	_, err = s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	_, buildingID, _ := s.engControl.BuildingPayload()
+	require.NotEqual(t, eth.PayloadID{}, buildingID, "should have started building a block")
+	require.False(t, s.seq.Priority(), "a freshly started block is not yet near its sealing deadline")
+
+	s.clockTime = s.clockTime.Add(time.Duration(s.cfg.BlockTime) * time.Second)
+	require.True(t, s.seq.Priority(), "a legacy block past its sealing deadline should take priority")
+
+	s.seq.espressoBatch = &InProgressBatch{jst: eth.L2BatchJustification{Next: &espresso.Header{}}}
+	require.True(t, s.seq.Priority(), "a sealed-and-ready Espresso batch should take priority regardless of legacy state")
+}
+
+// TestSequencerEspressoRejectsUnknownL1Origin asserts that a HotShot header referencing an L1
+// block beyond what L1Head reports is rejected as a critical error, rather than silently accepted,
+// and that nothing is sequenced onto the L2 chain as a result.
+func TestSequencerEspressoRejectsUnknownL1Origin(t *testing.T) {
+	s := SetupSequencer(t, true)
+
+	// First action just detects the mode.
+	_, err := s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Espresso, s.seq.mode)
+
+	// Report an L1 head older than the genesis L1 block every Espresso header in this test chain
+	// references, so the very first header built is already "unknown".
+	unknownHead := eth.L1BlockRef{Number: s.cfg.Genesis.L1.Number - 1}
+	s.l1HeadOverride = &unknownHead
+
+	_, err = s.seq.RunNextSequencerAction(context.Background())
+	require.Error(t, err, "a header referencing an L1 block beyond the known head must be rejected")
+	require.ErrorIs(t, err, derive.ErrCritical)
+	require.Equal(t, 0, s.engControl.totalBuiltBlocks, "no block should have been sequenced from the rejected header")
+}
+
+// TestSequencerEspressoStallsOnUnfinalizedL1Origin asserts that a HotShot header referencing an L1
+// block that exists but is not yet finalized only stalls batch construction (a retry is scheduled,
+// no error bubbles up and nothing is sequenced), rather than corrupting or rejecting the chain
+// outright; and that the batch proceeds once the referenced L1 block finalizes.
+func TestSequencerEspressoStallsOnUnfinalizedL1Origin(t *testing.T) {
+	s := SetupSequencer(t, true)
+
+	// First action just detects the mode.
+	_, err := s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Espresso, s.seq.mode)
+
+	// The L1 head comfortably covers every origin this test chain will reference, but nothing is
+	// finalized yet, so every header's origin is known but unconfirmed.
+	wideHead := eth.L1BlockRef{Number: ^uint64(0)}
+	s.l1HeadOverride = &wideHead
+	noneFinalized := eth.L1BlockRef{Number: s.cfg.Genesis.L1.Number - 1}
+	s.l1FinalizedOverride = &noneFinalized
+
+	for i := 0; i < 3; i++ {
+		payload, err := s.seq.RunNextSequencerAction(context.Background())
+		require.NoError(t, err, "a not-yet-finalized origin must stall, not error")
+		require.Nil(t, payload, "nothing should be sequenced while stalled on finality")
+	}
+	require.Equal(t, 0, s.engControl.totalBuiltBlocks, "no block should have been sequenced while stalled")
+	require.Greater(t, s.seq.retryAttempt, 0, "the stall should have scheduled at least one retry")
+
+	// Once the referenced origin finalizes, batch construction should proceed normally again.
+	s.l1FinalizedOverride = nil
+	for i := 0; i < 50 && s.engControl.totalBuiltBlocks == 0; i++ {
+		delay := s.seq.PlanNextSequencerAction()
+		s.clockTime = s.clockTime.Add(delay)
+		_, err := s.seq.RunNextSequencerAction(context.Background())
+		require.NoError(t, err)
+	}
+	require.Greater(t, s.engControl.totalBuiltBlocks, 0, "sequencing should resume once the origin finalizes")
+}
+
+// TestSequencerEspressoSubscriptionWakesPolling asserts that a header pushed through the
+// espresso.Subscriber channel, once it reaches the current batch's windowEnd, makes
+// PlanNextSequencerAction return immediately, without waiting out nextAction's usual poll delay.
+func TestSequencerEspressoSubscriptionWakesPolling(t *testing.T) {
+	s := SetupSequencer(t, true)
+
+	// First action just detects the mode.
+	_, err := s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Espresso, s.seq.mode)
+
+	// Second action starts building a batch, which lazily starts the header subscription.
+	_, err = s.seq.RunNextSequencerAction(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, s.seq.espressoBatch, "should have an Espresso batch in progress")
+	require.NotNil(t, s.espressoSubCh, "building an Espresso batch should have started a header subscription")
+
+	s.seq.nextAction = s.clockTime.Add(time.Hour)
+	require.Greater(t, s.seq.PlanNextSequencerAction(), time.Duration(0), "should be waiting out the poll delay before any header is pushed")
+
+	s.espressoSubCh <- espresso.Header{Metadata: espresso.Metadata{Timestamp: s.seq.espressoBatch.windowEnd}}
+	require.Eventually(t, func() bool {
+		return s.seq.PlanNextSequencerAction() == 0
+	}, time.Second, time.Millisecond, "a pushed header reaching the window end should wake the driver immediately")
+}
@@ -0,0 +1,194 @@
+package driver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/eth"
+)
+
+// LightClientReader reports whether the HotShot light client contract on L1 currently considers
+// HotShot live, i.e. it has committed a block within the last delayThresholdSeconds. This mirrors
+// the liveness oracle Nitro's switch_sequencer.go polls to decide when to fall back to centralized
+// sequencing.
+type LightClientReader interface {
+	IsHotShotLive(delayThresholdSeconds uint64) (bool, error)
+}
+
+// SwitchConfig configures SwitchSequencer's liveness polling.
+type SwitchConfig struct {
+	// DelayThreshold is the maximum age, in seconds, of the light client's most recently committed
+	// HotShot block before HotShot is considered not live.
+	DelayThreshold uint64
+	// PollInterval is how often the light client is polled for liveness.
+	PollInterval time.Duration
+	// RecoveryWindow is how long the light client must report HotShot continuously live before
+	// SwitchSequencer switches back from legacy to Espresso sequencing. This only debounces the
+	// legacy-to-Espresso direction: falling back to legacy happens on the very first non-live poll,
+	// since erring towards the simpler, already-proven-live centralized path is preferable to
+	// erring towards Espresso on liveness that may still be intermittent.
+	RecoveryWindow time.Duration
+}
+
+// SwitchSequencer wraps a Sequencer that was constructed for Espresso mode with a background
+// poller that can fall back to, and later recover from, legacy centralized sequencing at runtime,
+// based on the liveness of the HotShot light client contract. A bare Sequencer only ever picks its
+// mode once, via detectMode; SwitchSequencer is the only thing allowed to change d.mode after that
+// initial detection.
+type SwitchSequencer struct {
+	*Sequencer
+
+	lightClient LightClientReader
+	switchCfg   SwitchConfig
+
+	mu sync.Mutex
+	// desiredMode is written by the poll loop and read by applyModeTransition at the top of
+	// PlanNextSequencerAction/RunNextSequencerAction, so a mode flip never races with an
+	// in-progress engine call.
+	desiredMode SequencerMode
+	// liveSince is when the light client most recently started reporting HotShot live without
+	// interruption, or the zero time if the last poll (or the first poll yet to happen) found it not
+	// live. It is reset to zero on every non-live poll, so RecoveryWindow always measures a
+	// continuous live streak, not cumulative live time.
+	liveSince time.Time
+	// exitL1Block is the L1 origin the sequencer had reached when it last left Espresso mode, so
+	// that re-entering Espresso mode can pick back up from there instead of rescanning from the L2
+	// safe head's original L1 origin.
+	exitL1Block eth.BlockID
+
+	cancel context.CancelFunc
+}
+
+// NewSwitchSequencer wraps seq with a HotShot liveness poller. seq's mode at the time this is
+// called becomes the initial desired mode, so the poller will not immediately flip modes before
+// its first successful poll.
+func NewSwitchSequencer(seq *Sequencer, lightClient LightClientReader, cfg SwitchConfig) *SwitchSequencer {
+	return &SwitchSequencer{
+		Sequencer:   seq,
+		lightClient: lightClient,
+		switchCfg:   cfg,
+		desiredMode: seq.mode,
+	}
+}
+
+// Start launches the background liveness-polling loop. It returns immediately; the loop runs
+// until the context is cancelled or Stop is called.
+func (s *SwitchSequencer) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go s.pollLoop(ctx)
+}
+
+// Stop ends the background liveness-polling loop started by Start.
+func (s *SwitchSequencer) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func (s *SwitchSequencer) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.switchCfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			live, err := s.lightClient.IsHotShotLive(s.switchCfg.DelayThreshold)
+			if err != nil {
+				s.log.Error("failed to poll HotShot light client liveness", "err", err)
+				continue
+			}
+			s.observeLiveness(live)
+		}
+	}
+}
+
+// observeLiveness applies the result of a single liveness poll to desiredMode. It is split out from
+// pollLoop so tests can exercise RecoveryWindow's debouncing directly, against a mocked timeNow,
+// without depending on a real wall-clock ticker.
+func (s *SwitchSequencer) observeLiveness(live bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if live {
+		if s.liveSince.IsZero() {
+			s.liveSince = s.timeNow()
+		}
+		if s.timeNow().Sub(s.liveSince) >= s.switchCfg.RecoveryWindow {
+			s.desiredMode = Espresso
+		}
+	} else {
+		s.liveSince = time.Time{}
+		s.desiredMode = Legacy
+	}
+}
+
+// applyModeTransition flips d.mode to match the most recently polled liveness result, if it
+// differs from the mode we are currently running in. It is called at the top of every driver-loop
+// entry point, so the transition itself only ever happens on the single-threaded driver loop, the
+// same way detectMode does.
+func (s *SwitchSequencer) applyModeTransition(ctx context.Context) {
+	s.mu.Lock()
+	desired := s.desiredMode
+	s.mu.Unlock()
+
+	// Never override the initial mode-detection dance: until detectMode has run once, d.mode is
+	// Unknown and must stay that way so StartBuildingBlock/RunNextSequencerAction still trigger it.
+	if s.mode == Unknown || desired == s.mode {
+		return
+	}
+
+	switch desired {
+	case Legacy:
+		if s.espressoBatch != nil {
+			s.exitL1Block = s.espressoBatch.onto.L1Origin
+			s.log.Warn("HotShot light client reports liveness lost, draining in-progress Espresso batch",
+				"onto", s.espressoBatch.onto, "exitL1Block", s.exitL1Block)
+			s.espressoBatch = nil
+		}
+		s.log.Info("HotShot light client reports liveness lost, falling back to legacy sequencing")
+	case Espresso:
+		if s.exitL1Block != (eth.BlockID{}) {
+			s.Sequencer.SetEspressoResumeOrigin(s.exitL1Block)
+		}
+		s.log.Info("HotShot light client reports liveness restored, resuming Espresso sequencing",
+			"exitL1Block", s.exitL1Block)
+	default:
+		return
+	}
+	s.metrics.RecordSequencerModeTransition(s.mode, desired)
+	s.mode = desired
+	// Re-plan immediately: whatever delay was computed for the old mode no longer applies.
+	s.nextAction = s.timeNow()
+}
+
+func (s *SwitchSequencer) PlanNextSequencerAction() time.Duration {
+	s.applyModeTransition(context.Background())
+	return s.Sequencer.PlanNextSequencerAction()
+}
+
+func (s *SwitchSequencer) RunNextSequencerAction(ctx context.Context) (*eth.ExecutionPayload, error) {
+	s.applyModeTransition(ctx)
+	return s.Sequencer.RunNextSequencerAction(ctx)
+}
+
+// SwitchToCentralized forces the sequencer into legacy centralized mode immediately, without
+// waiting for the next poll of the light client. This is an escape hatch for operators who already
+// know HotShot is down (e.g. from an external alert) and don't want to wait out PollInterval before
+// the driver notices; the poll loop will simply re-affirm Legacy on its next tick unless liveness
+// has actually returned by then.
+func (s *SwitchSequencer) SwitchToCentralized() {
+	s.mu.Lock()
+	s.desiredMode = Legacy
+	s.mu.Unlock()
+}
+
+// SwitchToEspresso forces the sequencer back into Espresso mode immediately, without waiting for
+// the next poll of the light client. Like SwitchToCentralized, this is an operator escape hatch;
+// if HotShot is not actually live yet, the next poll will flip desiredMode back to Legacy.
+func (s *SwitchSequencer) SwitchToEspresso() {
+	s.mu.Lock()
+	s.desiredMode = Espresso
+	s.mu.Unlock()
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -17,7 +18,7 @@ type Client struct {
 	log     log.Logger
 }
 
-func NewClient(log log.Logger, url string) *Client {
+func newClient(log log.Logger, url string) *Client {
 	if !strings.HasSuffix(url, "/") {
 		url += "/"
 	}
@@ -28,6 +29,12 @@ func NewClient(log log.Logger, url string) *Client {
 	}
 }
 
+// NewClient is sugar for NewClientList with a single endpoint and no HotShot commitment source to
+// arbitrate disagreements, since there is only ever one endpoint to agree with itself.
+func NewClient(log log.Logger, url string) *ClientList {
+	return NewClientList(log, []string{url}, DefaultClientListConfig(), nil, common.Address{})
+}
+
 func (c *Client) FetchHeadersForWindow(ctx context.Context, start uint64, end uint64) (WindowStart, error) {
 	var res WindowStart
 	if err := c.get(ctx, &res, "availability/headers/window/%d/%d", start, end); err != nil {
@@ -44,6 +51,18 @@ func (c *Client) FetchRemainingHeadersForWindow(ctx context.Context, from uint64
 	return res, nil
 }
 
+// FetchHeaderByHeight fetches the single header at the given HotShot block height. Unlike
+// FetchHeadersForWindow, which is keyed by timestamp and may return a window of several headers,
+// this looks up exactly one block by its height, which is what a height-driven walk (such as
+// DASyncer) needs.
+func (c *Client) FetchHeaderByHeight(ctx context.Context, height uint64) (Header, error) {
+	var res Header
+	if err := c.get(ctx, &res, "availability/header/%d", height); err != nil {
+		return Header{}, err
+	}
+	return res, nil
+}
+
 func (c *Client) FetchTransactionsInBlock(ctx context.Context, block uint64, header *Header, namespace uint64) (TransactionsInBlock, error) {
 	var res NamespaceResponse
 	if err := c.get(ctx, &res, "availability/block/%d/namespace/%d", block, namespace); err != nil {
@@ -57,11 +76,14 @@ type NamespaceResponse struct {
 	Transactions []Transaction
 }
 
-// Validate a NamespaceResponse and extract the transactions.
-// NMT proof validation is currently stubbed out.
+// Validate a NamespaceResponse and extract the transactions, checking the NMT proof against
+// header.TransactionsRoot so that a malicious query service cannot silently omit transactions
+// belonging to namespace.
 func (res *NamespaceResponse) Validate(header *Header, namespace uint64) (TransactionsInBlock, error) {
-	proof := NmtProof(res.Proof)
-	// TODO validate `proof` against `header.TransactionsRoot`
+	var proof NmtProof
+	if err := json.Unmarshal(res.Proof, &proof); err != nil {
+		return TransactionsInBlock{}, fmt.Errorf("failed to parse nmt proof: %w", err)
+	}
 
 	// Extract the transactions.
 	var txs []Bytes
@@ -72,6 +94,10 @@ func (res *NamespaceResponse) Validate(header *Header, namespace uint64) (Transa
 		txs = append(txs, tx.Payload)
 	}
 
+	if err := proof.Verify(header.TransactionsRoot, namespace, txs); err != nil {
+		return TransactionsInBlock{}, fmt.Errorf("invalid nmt proof: %w", err)
+	}
+
 	return TransactionsInBlock{
 		Transactions: txs,
 		Proof:        proof,
@@ -0,0 +1,541 @@
+package espresso
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ClientListConfig configures ClientList's retry, failover, and circuit-breaker behavior.
+type ClientListConfig struct {
+	// MinRetryDelay and MaxRetryDelay bound the exponential backoff, with full jitter, applied
+	// between retries against the same endpoint.
+	MinRetryDelay time.Duration
+	MaxRetryDelay time.Duration
+	// MaxAttempts is how many times a single endpoint is retried before ClientList gives up on it
+	// for this call and fails over to the next healthy endpoint.
+	MaxAttempts int
+	// FailureThreshold is the number of consecutive failures after which an endpoint's circuit
+	// breaker opens, removing it from rotation until ProbeCooldown has passed.
+	FailureThreshold int
+	// ProbeCooldown is how long an endpoint stays excluded after its circuit breaker opens. There
+	// is no separate background goroutine: the "probe" is simply the next call that happens to
+	// reach the endpoint once the cooldown has elapsed.
+	ProbeCooldown time.Duration
+
+	// HedgeLatencyMultiplier, if positive, enables hedged requests: the healthiest endpoint is
+	// tried first, and if it hasn't returned within HedgeLatencyMultiplier times that endpoint's
+	// own tracked latency EWMA (or MinRetryDelay, if no samples exist yet), a second, single-shot
+	// request races against the next-healthiest endpoint; the first attempt to succeed wins. The
+	// other attempt is not cancelled and runs to completion in the background. Zero disables hedging, leaving every call on the simple
+	// try-then-failover path. This approximates the request's percentile-based deadline using the
+	// same EWMA ClientList already tracks, rather than a separate latency histogram.
+	HedgeLatencyMultiplier float64
+
+	// CrossCheck, if true, makes FetchRemainingHeadersForWindow fetch the window from the top two
+	// ranked endpoints concurrently and require their headers to agree before returning either one,
+	// rather than only comparing against headers seen on past calls the way checkHeaders does.
+	// Disagreement is escalated via DivergenceMetrics and, if possible, arbitrated on-chain the same
+	// way checkHeaders already does; with fewer than two endpoints configured, enabling this is an
+	// error at call time.
+	CrossCheck bool
+}
+
+func DefaultClientListConfig() ClientListConfig {
+	return ClientListConfig{
+		MinRetryDelay:    100 * time.Millisecond,
+		MaxRetryDelay:    5 * time.Second,
+		MaxAttempts:      3,
+		FailureThreshold: 5,
+		ProbeCooldown:    30 * time.Second,
+		// Hedging and cross-checking both default off: every existing deployment already gets the
+		// behavior it was tested against, and operators opt into the stronger (and costlier, in
+		// request volume) HA modes explicitly.
+		HedgeLatencyMultiplier: 0,
+		CrossCheck:             false,
+	}
+}
+
+// DivergenceMetrics receives a notification whenever two independent query-service endpoints
+// disagree about the header at some HotShot block height. It is a narrower interface than
+// driver.SequencerMetrics (which this package cannot import without an import cycle, since driver
+// imports espresso), satisfied by driver.SequencerMetrics's RecordEspressoQueryDivergence method.
+type DivergenceMetrics interface {
+	RecordEspressoQueryDivergence(height uint64)
+}
+
+// HotShotCommitmentSource resolves the on-chain HotShot commitment for a range of block heights.
+// ClientList uses it to arbitrate when two endpoints disagree about the header at some height: the
+// endpoint whose header hashes to the on-chain commitment wins. It is an interface, rather than a
+// direct dependency on sources.L1Client, because that type's L1HotShotCommitmentsFromHeight method
+// returns commitments in an external SDK's representation, not this package's Commitment type.
+type HotShotCommitmentSource interface {
+	L1HotShotCommitmentsFromHeight(firstBlockHeight uint64, numHeaders uint64, hotshotAddr common.Address) ([]Commitment, error)
+}
+
+// endpointHealth tracks one query-service URL's recent behavior: how many times in a row it has
+// failed, when its circuit breaker (if open) re-admits it, and a rolling estimate of its latency,
+// so ClientList can prefer fast, healthy endpoints over slow or failing ones.
+type endpointHealth struct {
+	mu                  sync.Mutex
+	consecutiveFailures int
+	circuitOpenUntil    time.Time
+	lastErr             error
+	lastErrTime         time.Time
+	latencyEWMA         time.Duration
+}
+
+// latencyEWMAWeight is the weight given to each new sample in the exponentially-weighted moving
+// average of an endpoint's latency.
+const latencyEWMAWeight = 0.2
+
+func (h *endpointHealth) recordSuccess(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.consecutiveFailures = 0
+	h.circuitOpenUntil = time.Time{}
+	if h.latencyEWMA == 0 {
+		h.latencyEWMA = latency
+	} else {
+		h.latencyEWMA = time.Duration((1-latencyEWMAWeight)*float64(h.latencyEWMA) + latencyEWMAWeight*float64(latency))
+	}
+}
+
+func (h *endpointHealth) recordFailure(err error, cfg ClientListConfig) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.consecutiveFailures++
+	h.lastErr = err
+	h.lastErrTime = time.Now()
+	if h.consecutiveFailures >= cfg.FailureThreshold {
+		h.circuitOpenUntil = time.Now().Add(cfg.ProbeCooldown)
+	}
+}
+
+// healthy reports whether this endpoint's circuit breaker currently allows requests.
+func (h *endpointHealth) healthy() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.circuitOpenUntil.IsZero() || time.Now().After(h.circuitOpenUntil)
+}
+
+// latency returns the endpoint's current latency EWMA, or 0 if no sample has been recorded yet.
+func (h *endpointHealth) latency() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.latencyEWMA
+}
+
+// hedgeDeadline returns how long to wait for this endpoint before racing a hedge request against
+// the next-best one, per cfg.HedgeLatencyMultiplier. An endpoint with no latency samples yet gets
+// the benefit of the doubt via cfg.MinRetryDelay, rather than hedging immediately.
+func (h *endpointHealth) hedgeDeadline(cfg ClientListConfig) time.Duration {
+	latency := h.latency()
+	if latency <= 0 {
+		return cfg.MinRetryDelay
+	}
+	return time.Duration(float64(latency) * cfg.HedgeLatencyMultiplier)
+}
+
+// endpoint pairs a single-URL Client with the health state ClientList uses to pick and retry it.
+type endpoint struct {
+	url    string
+	client *Client
+	health endpointHealth
+}
+
+// ClientList is a failover-capable espresso.QueryService backed by N base URLs. Each call is
+// dispatched to the first healthy endpoint, in priority order, retrying with exponential backoff
+// and jitter before failing over to the next healthy endpoint. An endpoint that fails
+// FailureThreshold times in a row is removed from rotation until ProbeCooldown has passed.
+type ClientList struct {
+	log         log.Logger
+	cfg         ClientListConfig
+	endpoints   []*endpoint
+	hotshot     HotShotCommitmentSource
+	hotshotAddr common.Address
+
+	seenMu sync.Mutex
+	seen   map[uint64]Commitment
+
+	// divergenceMetrics, if set via SetDivergenceMetrics, is notified of every header disagreement
+	// checkHeaders and crossCheckWindow observe, alongside the existing log line and best-effort
+	// on-chain arbitration.
+	divergenceMetrics DivergenceMetrics
+}
+
+// SetDivergenceMetrics configures metrics as the recipient of header-disagreement notifications.
+// It is optional, following the same pattern as driver.Sequencer.SetSealedHistory: a ClientList
+// with no divergence metrics set just logs disagreements, as it always has.
+func (c *ClientList) SetDivergenceMetrics(metrics DivergenceMetrics) {
+	c.divergenceMetrics = metrics
+}
+
+// NewClientList constructs a ClientList over the given base URLs, tried in the given order.
+// hotshot, if non-nil, is consulted to resolve disagreements between endpoints about the header at
+// a given height; pass nil to just log such disagreements and keep whichever header was seen
+// first.
+func NewClientList(log log.Logger, urls []string, cfg ClientListConfig, hotshot HotShotCommitmentSource, hotshotAddr common.Address) *ClientList {
+	endpoints := make([]*endpoint, len(urls))
+	for i, url := range urls {
+		endpoints[i] = &endpoint{url: url, client: newClient(log, url)}
+	}
+	return &ClientList{
+		log:         log,
+		cfg:         cfg,
+		endpoints:   endpoints,
+		hotshot:     hotshot,
+		hotshotAddr: hotshotAddr,
+		seen:        make(map[uint64]Commitment),
+	}
+}
+
+// orderedEndpoints returns the endpoints to try, in priority order: all currently-healthy
+// endpoints first, ranked by latency EWMA (ascending, with endpoints that have no sample yet
+// treated as fastest, so a freshly added endpoint gets a chance before it has a track record), then
+// the unhealthy ones in their configured order, so a call still succeeds (slowly) rather than
+// erroring outright if every endpoint's circuit breaker happens to be open.
+func (c *ClientList) orderedEndpoints() []*endpoint {
+	var healthy, unhealthy []*endpoint
+	for _, e := range c.endpoints {
+		if e.health.healthy() {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	sort.SliceStable(healthy, func(i, j int) bool {
+		li, lj := healthy[i].health.latency(), healthy[j].health.latency()
+		if li == 0 || lj == 0 {
+			return li == 0 && lj != 0
+		}
+		return li < lj
+	})
+	return append(healthy, unhealthy...)
+}
+
+// backoff returns the delay before retry attempt n (0-indexed) against a single endpoint:
+// exponential growth from MinRetryDelay, capped at MaxRetryDelay, with full jitter so that many
+// clients retrying the same endpoint at once don't all retry in lockstep.
+func backoff(n int, cfg ClientListConfig) time.Duration {
+	ceiling := cfg.MinRetryDelay << n
+	if ceiling <= 0 || ceiling > cfg.MaxRetryDelay { // overflow or past the cap
+		ceiling = cfg.MaxRetryDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// callOver dispatches fn against endpoints, in order, retrying each one with backoff up to
+// cfg.MaxAttempts times before failing over to the next. It returns the first success, or the last
+// error seen if every endpoint fails. dispatch calls it both for the non-hedged path (over
+// orderedEndpoints()) and for its post-hedge fallback (over whichever endpoints the hedge attempt
+// didn't already try), so a failed endpoint is never retried twice for the same call.
+func (c *ClientList) callOver(ctx context.Context, endpoints []*endpoint, fn func(ctx context.Context, client *Client) error) error {
+	var lastErr error
+	for _, e := range endpoints {
+		for attempt := 0; attempt < c.cfg.MaxAttempts; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(backoff(attempt-1, c.cfg)):
+				}
+			}
+			start := time.Now()
+			err := fn(ctx, e.client)
+			if err == nil {
+				e.health.recordSuccess(time.Since(start))
+				return nil
+			}
+			lastErr = err
+			e.health.recordFailure(err, c.cfg)
+			c.log.Warn("espresso query service endpoint failed", "url", e.url, "attempt", attempt+1, "err", err)
+		}
+	}
+	if lastErr == nil {
+		return fmt.Errorf("no espresso query service endpoints configured")
+	}
+	return fmt.Errorf("all espresso query service endpoints failed, last error: %w", lastErr)
+}
+
+// raceEndpoint makes a single attempt (no internal retries - dispatch's hedge is the retry
+// mechanism here) against e, recording success or failure the same way callOver does.
+func (c *ClientList) raceEndpoint(ctx context.Context, e *endpoint, fn func(ctx context.Context, client *Client) error) error {
+	start := time.Now()
+	err := fn(ctx, e.client)
+	if err == nil {
+		e.health.recordSuccess(time.Since(start))
+		return nil
+	}
+	e.health.recordFailure(err, c.cfg)
+	c.log.Warn("espresso query service endpoint failed", "url", e.url, "attempt", 1, "err", err)
+	return err
+}
+
+// dispatch is every QueryService method's entry point into ClientList. With hedging disabled it is
+// just callOver over orderedEndpoints(); with hedging enabled and at least two endpoints ranked, it
+// races a single-shot attempt against the second-ranked endpoint if the top-ranked one hasn't
+// returned within its hedgeDeadline, taking whichever finishes first and letting the other run to
+// completion in the background (its result still updates health, but is otherwise discarded). If
+// both hedge attempts fail, dispatch falls over to any remaining ranked endpoints via callOver.
+func (c *ClientList) dispatch(ctx context.Context, fn func(ctx context.Context, client *Client) error) error {
+	ranked := c.orderedEndpoints()
+	if c.cfg.HedgeLatencyMultiplier <= 0 || len(ranked) < 2 {
+		return c.callOver(ctx, ranked, fn)
+	}
+
+	primary, secondary := ranked[0], ranked[1]
+	type attempt struct {
+		e   *endpoint
+		err error
+	}
+	results := make(chan attempt, 2)
+	launch := func(e *endpoint) {
+		go func() { results <- attempt{e, c.raceEndpoint(ctx, e, fn)} }()
+	}
+	launch(primary)
+
+	deadline := time.NewTimer(primary.health.hedgeDeadline(c.cfg))
+	defer deadline.Stop()
+
+	hedged := false
+	outstanding := 1
+	for outstanding > 0 {
+		select {
+		case res := <-results:
+			outstanding--
+			if res.err == nil {
+				return nil
+			}
+			if !hedged {
+				// The endpoint we were waiting on failed before the hedge deadline even fired;
+				// no reason to keep waiting, race the other one now.
+				launch(secondary)
+				hedged = true
+				outstanding++
+			}
+		case <-deadline.C:
+			if !hedged {
+				launch(secondary)
+				hedged = true
+				outstanding++
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return c.callOver(ctx, ranked[2:], fn)
+}
+
+func (c *ClientList) FetchHeadersForWindow(ctx context.Context, start uint64, end uint64) (WindowStart, error) {
+	var res WindowStart
+	if err := c.dispatch(ctx, func(ctx context.Context, client *Client) error {
+		r, err := client.FetchHeadersForWindow(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		res = r
+		return nil
+	}); err != nil {
+		return WindowStart{}, err
+	}
+	c.checkHeaders(res.Window, res.From)
+	return res, nil
+}
+
+// FetchRemainingHeadersForWindow fetches the rest of a sequencing window. When cfg.CrossCheck is
+// enabled it is served by crossCheckWindow instead of dispatch, trading extra request volume for a
+// stronger, synchronous agreement guarantee than checkHeaders' passive comparison provides.
+func (c *ClientList) FetchRemainingHeadersForWindow(ctx context.Context, from uint64, end uint64) (WindowMore, error) {
+	if c.cfg.CrossCheck {
+		return c.crossCheckWindow(ctx, from, end)
+	}
+	var res WindowMore
+	if err := c.dispatch(ctx, func(ctx context.Context, client *Client) error {
+		r, err := client.FetchRemainingHeadersForWindow(ctx, from, end)
+		if err != nil {
+			return err
+		}
+		res = r
+		return nil
+	}); err != nil {
+		return WindowMore{}, err
+	}
+	c.checkHeaders(res.Window, from)
+	return res, nil
+}
+
+// FetchHeaderByHeight fetches the single header at the given HotShot block height, failing over
+// across endpoints the same way the other QueryService methods do.
+func (c *ClientList) FetchHeaderByHeight(ctx context.Context, height uint64) (Header, error) {
+	var res Header
+	if err := c.dispatch(ctx, func(ctx context.Context, client *Client) error {
+		r, err := client.FetchHeaderByHeight(ctx, height)
+		if err != nil {
+			return err
+		}
+		res = r
+		return nil
+	}); err != nil {
+		return Header{}, err
+	}
+	c.checkHeaders([]Header{res}, height)
+	return res, nil
+}
+
+// SubscribeHeaders implements Subscriber for ClientList by long-polling FetchRemainingHeadersForWindow
+// (see SubscribeHeaders in subscribe.go), rather than any push mechanism, since ClientList only ever
+// speaks to endpoints over plain HTTP. It still gets ClientList's existing endpoint failover and
+// circuit-breaking for free: each poll is just another FetchRemainingHeadersForWindow call, routed
+// through the same dispatch logic every other QueryService method uses.
+func (c *ClientList) SubscribeHeaders(ctx context.Context, fromHeight uint64) (HeaderSubscription, error) {
+	return SubscribeHeaders(ctx, c, fromHeight, c.log), nil
+}
+
+var _ Subscriber = (*ClientList)(nil)
+
+func (c *ClientList) FetchTransactionsInBlock(ctx context.Context, block uint64, header *Header, namespace uint64) (TransactionsInBlock, error) {
+	var res TransactionsInBlock
+	err := c.dispatch(ctx, func(ctx context.Context, client *Client) error {
+		r, err := client.FetchTransactionsInBlock(ctx, block, header, namespace)
+		if err != nil {
+			return err
+		}
+		res = r
+		return nil
+	})
+	return res, err
+}
+
+// crossCheckWindow implements FetchRemainingHeadersForWindow's CrossCheck mode: it fetches the same
+// window from the top two ranked endpoints concurrently and requires their headers to agree on
+// Timestamp, L1Head, and commitment before returning either one; where they disagree and the on-chain
+// commitment settles it, the header matching that commitment is returned. This is a stronger guarantee than
+// checkHeaders' passive comparison against headers seen on earlier calls, at the cost of doubling
+// the request volume for this call - scoped to FetchRemainingHeadersForWindow specifically, rather
+// than also applied to the less frequently called FetchHeadersForWindow, to keep this change
+// tractable.
+func (c *ClientList) crossCheckWindow(ctx context.Context, from uint64, end uint64) (WindowMore, error) {
+	ranked := c.orderedEndpoints()
+	if len(ranked) < 2 {
+		return WindowMore{}, fmt.Errorf("cross-check requires at least two espresso query service endpoints, have %d", len(ranked))
+	}
+	a, b := ranked[0], ranked[1]
+
+	type fetched struct {
+		res WindowMore
+		err error
+	}
+	fetch := func(e *endpoint) fetched {
+		start := time.Now()
+		res, err := e.client.FetchRemainingHeadersForWindow(ctx, from, end)
+		if err != nil {
+			e.health.recordFailure(err, c.cfg)
+			return fetched{err: err}
+		}
+		e.health.recordSuccess(time.Since(start))
+		return fetched{res: res}
+	}
+
+	var resA, resB fetched
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); resA = fetch(a) }()
+	go func() { defer wg.Done(); resB = fetch(b) }()
+	wg.Wait()
+
+	if resA.err != nil && resB.err != nil {
+		return WindowMore{}, fmt.Errorf("cross-check failed on both endpoints: %s: %w, %s: %v", a.url, resA.err, b.url, resB.err)
+	}
+	if resA.err != nil {
+		c.checkHeaders(resB.res.Window, from)
+		return resB.res, nil
+	}
+	if resB.err != nil {
+		c.checkHeaders(resA.res.Window, from)
+		return resA.res, nil
+	}
+
+	if len(resA.res.Window) != len(resB.res.Window) {
+		return WindowMore{}, fmt.Errorf("cross-check: endpoints returned different window lengths: %s=%d, %s=%d", a.url, len(resA.res.Window), b.url, len(resB.res.Window))
+	}
+	for i := range resA.res.Window {
+		ha, hb := resA.res.Window[i], resB.res.Window[i]
+		if ha.Timestamp != hb.Timestamp || ha.L1Head != hb.L1Head || ha.Commit() != hb.Commit() {
+			height := from + uint64(i)
+			c.log.Error("espresso query service endpoints disagree on header during cross-check", "height", height, "a", a.url, "b", b.url)
+			if c.divergenceMetrics != nil {
+				c.divergenceMetrics.RecordEspressoQueryDivergence(height)
+			}
+			winner, err := c.resolveDisagreement(height, ha.Commit(), hb.Commit())
+			if err != nil {
+				return WindowMore{}, fmt.Errorf("cross-check: endpoints disagree on header at height %d and on-chain arbitration failed: %w", height, err)
+			}
+			if winner == hb.Commit() {
+				resA.res.Window[i] = hb
+			}
+		}
+	}
+
+	c.checkHeaders(resA.res.Window, from)
+	return resA.res, nil
+}
+
+// checkHeaders compares each header in a freshly-fetched window against any header ClientList has
+// previously seen at the same height. A disagreement means at least one of the endpoints involved
+// is misbehaving (or stale), which is serious enough to warrant a loud warning regardless of
+// whether it can be resolved.
+func (c *ClientList) checkHeaders(headers []Header, firstHeight uint64) {
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+	for i, header := range headers {
+		height := firstHeight + uint64(i)
+		comm := header.Commit()
+		if prev, ok := c.seen[height]; ok && prev != comm {
+			c.log.Error("espresso query service endpoints disagree on header", "height", height, "seen", prev, "new", comm)
+			if c.divergenceMetrics != nil {
+				c.divergenceMetrics.RecordEspressoQueryDivergence(height)
+			}
+			if winner, err := c.resolveDisagreement(height, prev, comm); err == nil {
+				comm = winner
+			} else {
+				comm = prev
+			}
+		}
+		c.seen[height] = comm
+	}
+}
+
+// resolveDisagreement asks the HotShot light client contract which of two disputed commitments at
+// height is correct, so that checkHeaders can remember the authoritative one going forward. It
+// returns an error if no HotShotCommitmentSource was configured, or the on-chain read fails; in
+// either case the caller falls back to keeping whichever commitment it saw first.
+func (c *ClientList) resolveDisagreement(height uint64, a, b Commitment) (Commitment, error) {
+	if c.hotshot == nil {
+		return Commitment{}, fmt.Errorf("no HotShot commitment source configured to resolve disagreement")
+	}
+	comms, err := c.hotshot.L1HotShotCommitmentsFromHeight(height, 1, c.hotshotAddr)
+	if err != nil {
+		return Commitment{}, fmt.Errorf("failed to read on-chain HotShot commitment for height %d: %w", height, err)
+	}
+	if len(comms) == 0 {
+		return Commitment{}, fmt.Errorf("no on-chain HotShot commitment available for height %d", height)
+	}
+	onChain := comms[0]
+	switch onChain {
+	case a:
+		c.log.Warn("resolved header disagreement in favor of the first-seen header", "height", height, "commitment", onChain)
+		return a, nil
+	case b:
+		c.log.Warn("resolved header disagreement in favor of the newly-fetched header", "height", height, "commitment", onChain)
+		return b, nil
+	default:
+		return Commitment{}, fmt.Errorf("neither disputed header at height %d matches the on-chain HotShot commitment", height)
+	}
+}
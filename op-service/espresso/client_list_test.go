@@ -0,0 +1,206 @@
+package espresso
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum-optimism/optimism/op-service/testlog"
+)
+
+func windowServer(t *testing.T, window WindowStart) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(window))
+	}))
+}
+
+func failingServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+}
+
+func fastTestConfig() ClientListConfig {
+	cfg := DefaultClientListConfig()
+	cfg.MinRetryDelay = time.Millisecond
+	cfg.MaxRetryDelay = 2 * time.Millisecond
+	return cfg
+}
+
+func TestClientListFailover(t *testing.T) {
+	down := failingServer(t)
+	defer down.Close()
+	up := windowServer(t, WindowStart{From: 10, Window: []Header{{}}})
+	defer up.Close()
+
+	cfg := fastTestConfig()
+	cfg.MaxAttempts = 2
+	cl := NewClientList(testlog.Logger(t, log.LvlCrit), []string{down.URL, up.URL}, cfg, nil, common.Address{})
+
+	res, err := cl.FetchHeadersForWindow(context.Background(), 10, 20)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), res.From)
+
+	require.Equal(t, cfg.MaxAttempts, cl.endpoints[0].health.consecutiveFailures, "the failing endpoint should have been retried MaxAttempts times before failing over")
+	require.Equal(t, 0, cl.endpoints[1].health.consecutiveFailures, "the working endpoint should show no failures")
+}
+
+func TestClientListAllEndpointsFail(t *testing.T) {
+	down1 := failingServer(t)
+	defer down1.Close()
+	down2 := failingServer(t)
+	defer down2.Close()
+
+	cfg := fastTestConfig()
+	cfg.MaxAttempts = 1
+	cl := NewClientList(testlog.Logger(t, log.LvlCrit), []string{down1.URL, down2.URL}, cfg, nil, common.Address{})
+
+	_, err := cl.FetchHeadersForWindow(context.Background(), 10, 20)
+	require.Error(t, err)
+}
+
+func TestClientListCircuitBreaker(t *testing.T) {
+	down := failingServer(t)
+	defer down.Close()
+
+	cfg := fastTestConfig()
+	cfg.MaxAttempts = 1
+	cfg.FailureThreshold = 2
+	cfg.ProbeCooldown = 10 * time.Millisecond
+	cl := NewClientList(testlog.Logger(t, log.LvlCrit), []string{down.URL}, cfg, nil, common.Address{})
+
+	_, err := cl.FetchHeadersForWindow(context.Background(), 10, 20)
+	require.Error(t, err)
+	require.True(t, cl.endpoints[0].health.healthy(), "breaker should not yet be open after only 1 failure")
+
+	_, err = cl.FetchHeadersForWindow(context.Background(), 10, 20)
+	require.Error(t, err)
+	require.False(t, cl.endpoints[0].health.healthy(), "breaker should open after FailureThreshold consecutive failures")
+
+	time.Sleep(cfg.ProbeCooldown * 2)
+	require.True(t, cl.endpoints[0].health.healthy(), "breaker should re-admit the endpoint once the cooldown has elapsed")
+}
+
+func TestClientListDisagreementResolvedByHotShotCommitment(t *testing.T) {
+	headerA := Header{Metadata: Metadata{Timestamp: 1}}
+	headerB := Header{Metadata: Metadata{Timestamp: 2}}
+	require.NotEqual(t, headerA.Commit(), headerB.Commit())
+
+	serverA := windowServer(t, WindowStart{From: 5, Window: []Header{headerA}})
+	defer serverA.Close()
+	serverB := windowServer(t, WindowStart{From: 5, Window: []Header{headerB}})
+	defer serverB.Close()
+
+	cfg := fastTestConfig()
+	cfg.MaxAttempts = 1
+	cfg.FailureThreshold = 1
+	cfg.ProbeCooldown = time.Hour
+	hotshot := &fakeHotShotCommitmentSource{commitment: headerB.Commit()}
+	cl := NewClientList(testlog.Logger(t, log.LvlCrit), []string{serverA.URL, serverB.URL}, cfg, hotshot, common.Address{})
+
+	// First call succeeds against serverA, recording headerA's commitment for height 5.
+	_, err := cl.FetchHeadersForWindow(context.Background(), 5, 6)
+	require.NoError(t, err)
+
+	// Force serverA out of rotation so the next call reaches serverB, which disagrees about the
+	// header at height 5.
+	cl.endpoints[0].health.recordFailure(nil, cfg)
+	require.False(t, cl.endpoints[0].health.healthy())
+
+	_, err = cl.FetchHeadersForWindow(context.Background(), 5, 6)
+	require.NoError(t, err)
+
+	cl.seenMu.Lock()
+	resolved := cl.seen[5]
+	cl.seenMu.Unlock()
+	require.Equal(t, headerB.Commit(), resolved, "the header matching the on-chain HotShot commitment should win")
+}
+
+type fakeHotShotCommitmentSource struct {
+	commitment Commitment
+}
+
+func (f *fakeHotShotCommitmentSource) L1HotShotCommitmentsFromHeight(firstBlockHeight uint64, numHeaders uint64, hotshotAddr common.Address) ([]Commitment, error) {
+	return []Commitment{f.commitment}, nil
+}
+
+func TestBackoffRespectsMinAndMax(t *testing.T) {
+	cfg := ClientListConfig{MinRetryDelay: 10 * time.Millisecond, MaxRetryDelay: 40 * time.Millisecond}
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoff(attempt, cfg)
+		require.GreaterOrEqual(t, d, time.Duration(0))
+		require.LessOrEqual(t, d, cfg.MaxRetryDelay)
+	}
+}
+
+// slowServer responds to every request only after delay has elapsed, so tests can exercise the
+// hedge deadline without relying on a real endpoint's latency.
+func slowServer(t *testing.T, delay time.Duration, window WindowStart) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		require.NoError(t, json.NewEncoder(w).Encode(window))
+	}))
+}
+
+func TestClientListHedgingRacesSlowPrimary(t *testing.T) {
+	slow := slowServer(t, 50*time.Millisecond, WindowStart{From: 10, Window: []Header{{Metadata: Metadata{Timestamp: 1}}}})
+	defer slow.Close()
+	fast := windowServer(t, WindowStart{From: 10, Window: []Header{{Metadata: Metadata{Timestamp: 2}}}})
+	defer fast.Close()
+
+	cfg := fastTestConfig()
+	cfg.HedgeLatencyMultiplier = 1
+	cl := NewClientList(testlog.Logger(t, log.LvlCrit), []string{slow.URL, fast.URL}, cfg, nil, common.Address{})
+
+	// Give the slow endpoint a latency sample well below its actual delay, so the hedge deadline
+	// fires long before the slow endpoint would have responded, and the fast endpoint a larger one,
+	// so the slow endpoint ranks first and is the primary.
+	cl.endpoints[0].health.recordSuccess(time.Millisecond)
+	cl.endpoints[1].health.recordSuccess(10 * time.Millisecond)
+
+	start := time.Now()
+	res, err := cl.FetchHeadersForWindow(context.Background(), 10, 20)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 50*time.Millisecond, "hedged request to the fast endpoint should win before the slow endpoint responds")
+	require.Equal(t, uint64(2), res.Window[0].Timestamp, "should have returned the fast endpoint's response")
+}
+
+func TestClientListCrossCheckDetectsDivergence(t *testing.T) {
+	headerA := Header{Metadata: Metadata{Timestamp: 1}}
+	headerB := Header{Metadata: Metadata{Timestamp: 2}}
+	require.NotEqual(t, headerA.Commit(), headerB.Commit())
+
+	serverA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(WindowMore{Window: []Header{headerA}}))
+	}))
+	defer serverA.Close()
+	serverB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(WindowMore{Window: []Header{headerB}}))
+	}))
+	defer serverB.Close()
+
+	cfg := fastTestConfig()
+	cfg.CrossCheck = true
+	cl := NewClientList(testlog.Logger(t, log.LvlCrit), []string{serverA.URL, serverB.URL}, cfg, nil, common.Address{})
+	divergences := &fakeDivergenceMetrics{}
+	cl.SetDivergenceMetrics(divergences)
+
+	_, err := cl.FetchRemainingHeadersForWindow(context.Background(), 5, 6)
+	require.Error(t, err, "cross-check should fail closed when endpoints disagree and no HotShot commitment source is configured to arbitrate")
+	require.Equal(t, []uint64{5}, divergences.heights)
+}
+
+type fakeDivergenceMetrics struct {
+	heights []uint64
+}
+
+func (f *fakeDivergenceMetrics) RecordEspressoQueryDivergence(height uint64) {
+	f.heights = append(f.heights, height)
+}
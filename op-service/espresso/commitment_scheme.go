@@ -0,0 +1,414 @@
+package espresso
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Commitment is a 32-byte content hash identifying a value: two values with the same Commitment
+// are the same value for every purpose this package cares about, whether or not they're the same
+// Go value.
+type Commitment [32]byte
+
+// CommitmentScheme abstracts the hash function and field-encoding rules used to fold a struct's
+// fields into a single Commitment, so that Header (and friends) can be committed under different
+// hash backends -- e.g. a SNARK-friendly hash for zk circuits, or Keccak for on-chain verification
+// -- without forking the types that own the data. Header.Commit itself is CommitWith(KeccakScheme)
+// in disguise -- see RawCommitmentBuilder -- and Header.CommitWith is the opt-in entry point for an
+// alternative CommitmentScheme.
+//
+// Every concrete scheme must use the same encoding rules Header.Commit's own backend already uses,
+// so that a commitment computed under one backend is comparable in shape, if not in hash value, to
+// one computed under another: every field name is written as a domain separator before its value,
+// uint64/uint256 fields have a fixed-size little-endian encoding, and variable-length fields commit
+// to their own length first to prevent length-extension and domain-collision attacks.
+type CommitmentScheme interface {
+	// New returns a fresh instance of the same underlying hash backend as this one, seeded with a
+	// new domain-separating name, for committing to a nested sub-structure. For example,
+	// Header.CommitWith calls scheme.New("L1BLOCK") to build L1BlockInfo's own sub-commitment under
+	// the same backend Header itself was asked to use, mirroring how Commit's own
+	// NewRawCommitmentBuilder is called once per nested type.
+	New(name string) CommitmentScheme
+	Uint64Field(name string, n uint64) CommitmentScheme
+	Uint256Field(name string, n *U256) CommitmentScheme
+	FixedSizeField(name string, data []byte) CommitmentScheme
+	VarSizeField(name string, data []byte) CommitmentScheme
+	// BoolField, AddressField, and Bytes32Field are named, fixed-size shorthand for the three
+	// non-numeric scalar shapes a committed struct tends to have, so a caller doesn't have to go
+	// through FixedSizeField and get the byte-slicing (and the length-extension risk of getting it
+	// wrong) right itself.
+	BoolField(name string, b bool) CommitmentScheme
+	AddressField(name string, addr common.Address) CommitmentScheme
+	Bytes32Field(name string, b [32]byte) CommitmentScheme
+	// OptionalField mixes in whether c is present before optionally mixing in its value, so a nil c
+	// can never collide with a present Commitment whose bytes happen to be all zero.
+	OptionalField(name string, c *Commitment) CommitmentScheme
+	Field(name string, c Commitment) CommitmentScheme
+	Finalize() Commitment
+}
+
+// Committable is anything that can fold itself into a single Commitment. It is the minimal contract
+// ArrayField needs from an element type to commit to a sequence of them.
+type Committable interface {
+	Commit() Commitment
+}
+
+// ArrayField commits to a sequence of Committable items under name: first the item count, so the
+// length (like VarSizeField's) is bound into the hash and can't be extended or truncated
+// undetected, then each item's own Commit() folded in as a field in order.
+//
+// ArrayField always uses each item's default Commit(), not a scheme-specific CommitWith, so it only
+// produces a commitment consistent with scheme's backend when scheme is the same backend Commit()
+// itself uses. A caller folding an array into a CommitWith tree under a non-default scheme (as
+// commitWithdrawalsWith does for Header.Withdrawals) should fold each item's own CommitWith(scheme)
+// result in as a Field directly instead, the way commitWithdrawalsWith already does.
+func ArrayField[T Committable](scheme CommitmentScheme, name string, items []T) CommitmentScheme {
+	scheme.Uint64Field(name+".len", uint64(len(items)))
+	for i, item := range items {
+		scheme.Field(fmt.Sprintf("%s.%d", name, i), item.Commit())
+	}
+	return scheme
+}
+
+// OptionalField mixes a presence bit into scheme under name, then -- only if present -- lets build
+// add further fields to the same running hash. This is the combinator form of "optional": unlike
+// the OptionalField method, which folds a separately-computed sub-commitment in as a single field,
+// this is for an optional group of fields that has no commitment of its own to fold in. Either way,
+// the presence bit is what keeps a None from colliding with a Some whose fields happen to hash the
+// same as no fields having been written at all.
+func OptionalField(scheme CommitmentScheme, name string, present bool, build func(CommitmentScheme)) CommitmentScheme {
+	scheme.BoolField(name, present)
+	if present {
+		build(scheme)
+	}
+	return scheme
+}
+
+// hashScheme is the shared implementation behind every concrete CommitmentScheme backed by a
+// standard library or go-ethereum hash.Hash.
+type hashScheme struct {
+	newHash func() hash.Hash
+	h       hash.Hash
+}
+
+func newHashScheme(newHash func() hash.Hash, name string) *hashScheme {
+	s := &hashScheme{newHash: newHash, h: newHash()}
+	s.constantString(name)
+	return s
+}
+
+// constantString appends a domain-separating field name to the running hash, the same way
+// RawCommitmentBuilder.ConstantString does: followed by a byte sequence that can never appear in
+// valid UTF-8, so it can't be confused with field content.
+func (s *hashScheme) constantString(str string) {
+	io.WriteString(s.h, str)
+	s.h.Write([]byte{0xC0, 0x7F})
+}
+
+func (s *hashScheme) Uint64Field(name string, n uint64) *hashScheme {
+	s.constantString(name)
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, n)
+	s.h.Write(buf)
+	return s
+}
+
+func (s *hashScheme) Uint256Field(name string, n *U256) *hashScheme {
+	s.constantString(name)
+	buf := make([]byte, 32)
+	n.FillBytes(buf)
+	// FillBytes is big endian, but the Espresso commitment scheme uses little endian.
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	s.h.Write(buf)
+	return s
+}
+
+func (s *hashScheme) FixedSizeField(name string, data []byte) *hashScheme {
+	s.constantString(name)
+	s.h.Write(data)
+	return s
+}
+
+func (s *hashScheme) VarSizeField(name string, data []byte) *hashScheme {
+	s.constantString(name)
+	lenBuf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(lenBuf, uint64(len(data)))
+	s.h.Write(lenBuf)
+	s.h.Write(data)
+	return s
+}
+
+func (s *hashScheme) BoolField(name string, b bool) *hashScheme {
+	s.constantString(name)
+	if b {
+		s.h.Write([]byte{1})
+	} else {
+		s.h.Write([]byte{0})
+	}
+	return s
+}
+
+func (s *hashScheme) AddressField(name string, addr common.Address) *hashScheme {
+	return s.FixedSizeField(name, addr[:])
+}
+
+func (s *hashScheme) Bytes32Field(name string, b [32]byte) *hashScheme {
+	return s.FixedSizeField(name, b[:])
+}
+
+func (s *hashScheme) OptionalField(name string, c *Commitment) *hashScheme {
+	s.constantString(name)
+	if c != nil {
+		s.h.Write([]byte{1})
+		s.h.Write(c[:])
+	} else {
+		s.h.Write([]byte{0})
+	}
+	return s
+}
+
+func (s *hashScheme) Field(name string, c Commitment) *hashScheme {
+	return s.FixedSizeField(name, c[:])
+}
+
+func (s *hashScheme) Finalize() Commitment {
+	var comm Commitment
+	copy(comm[:], s.h.Sum(nil))
+	return comm
+}
+
+// SHA256Scheme commits fields using SHA-256: a widely available, general-purpose default that
+// needs no special support from a verifier.
+type SHA256Scheme struct{ *hashScheme }
+
+// NewSHA256Scheme returns a SHA256Scheme seeded with the given domain-separating name.
+func NewSHA256Scheme(name string) *SHA256Scheme {
+	return &SHA256Scheme{newHashScheme(sha256.New, name)}
+}
+
+func (s *SHA256Scheme) New(name string) CommitmentScheme { return NewSHA256Scheme(name) }
+
+func (s *SHA256Scheme) Uint64Field(n string, v uint64) CommitmentScheme {
+	s.hashScheme.Uint64Field(n, v)
+	return s
+}
+
+func (s *SHA256Scheme) Uint256Field(n string, v *U256) CommitmentScheme {
+	s.hashScheme.Uint256Field(n, v)
+	return s
+}
+
+func (s *SHA256Scheme) FixedSizeField(n string, d []byte) CommitmentScheme {
+	s.hashScheme.FixedSizeField(n, d)
+	return s
+}
+
+func (s *SHA256Scheme) VarSizeField(n string, d []byte) CommitmentScheme {
+	s.hashScheme.VarSizeField(n, d)
+	return s
+}
+
+func (s *SHA256Scheme) BoolField(n string, b bool) CommitmentScheme {
+	s.hashScheme.BoolField(n, b)
+	return s
+}
+
+func (s *SHA256Scheme) AddressField(n string, addr common.Address) CommitmentScheme {
+	s.hashScheme.AddressField(n, addr)
+	return s
+}
+
+func (s *SHA256Scheme) Bytes32Field(n string, b [32]byte) CommitmentScheme {
+	s.hashScheme.Bytes32Field(n, b)
+	return s
+}
+
+func (s *SHA256Scheme) OptionalField(n string, c *Commitment) CommitmentScheme {
+	s.hashScheme.OptionalField(n, c)
+	return s
+}
+
+func (s *SHA256Scheme) Field(n string, c Commitment) CommitmentScheme {
+	s.hashScheme.Field(n, c)
+	return s
+}
+
+// KeccakScheme commits fields using Keccak256, the hash EVM opcodes and precompiles use natively,
+// so a commitment computed this way can be recomputed by a Solidity verifier without an expensive
+// SHA-256 precompile.
+type KeccakScheme struct{ *hashScheme }
+
+// NewKeccakScheme returns a KeccakScheme seeded with the given domain-separating name.
+func NewKeccakScheme(name string) *KeccakScheme {
+	return &KeccakScheme{newHashScheme(func() hash.Hash { return crypto.NewKeccakState() }, name)}
+}
+
+func (s *KeccakScheme) New(name string) CommitmentScheme { return NewKeccakScheme(name) }
+
+func (s *KeccakScheme) Uint64Field(n string, v uint64) CommitmentScheme {
+	s.hashScheme.Uint64Field(n, v)
+	return s
+}
+
+func (s *KeccakScheme) Uint256Field(n string, v *U256) CommitmentScheme {
+	s.hashScheme.Uint256Field(n, v)
+	return s
+}
+
+func (s *KeccakScheme) FixedSizeField(n string, d []byte) CommitmentScheme {
+	s.hashScheme.FixedSizeField(n, d)
+	return s
+}
+
+func (s *KeccakScheme) VarSizeField(n string, d []byte) CommitmentScheme {
+	s.hashScheme.VarSizeField(n, d)
+	return s
+}
+
+func (s *KeccakScheme) BoolField(n string, b bool) CommitmentScheme {
+	s.hashScheme.BoolField(n, b)
+	return s
+}
+
+func (s *KeccakScheme) AddressField(n string, addr common.Address) CommitmentScheme {
+	s.hashScheme.AddressField(n, addr)
+	return s
+}
+
+func (s *KeccakScheme) Bytes32Field(n string, b [32]byte) CommitmentScheme {
+	s.hashScheme.Bytes32Field(n, b)
+	return s
+}
+
+func (s *KeccakScheme) OptionalField(n string, c *Commitment) CommitmentScheme {
+	s.hashScheme.OptionalField(n, c)
+	return s
+}
+
+func (s *KeccakScheme) Field(n string, c Commitment) CommitmentScheme {
+	s.hashScheme.Field(n, c)
+	return s
+}
+
+// RawCommitmentBuilder is the backend Header.Commit and friends use by default: Keccak256, the hash
+// EVM opcodes and precompiles use natively. It is just KeccakScheme under a name that predates
+// CommitmentScheme's multi-backend abstraction -- Commit() on every type in this package is exactly
+// CommitWith(NewRawCommitmentBuilder(name)) for that type's own domain-separating name, so the two
+// never disagree.
+type RawCommitmentBuilder = KeccakScheme
+
+// NewRawCommitmentBuilder returns a RawCommitmentBuilder seeded with the given domain-separating
+// name.
+func NewRawCommitmentBuilder(name string) *RawCommitmentBuilder {
+	return NewKeccakScheme(name)
+}
+
+// ErrPoseidonUnimplemented is returned by every PoseidonScheme method: no Poseidon implementation
+// is vendored in this checkout, so PoseidonScheme exists only as a placeholder for the backend a
+// future zk circuit integration would plug in, rather than silently falling back to a different
+// hash that a circuit wouldn't actually be using.
+var ErrPoseidonUnimplemented = errors.New("espresso: PoseidonScheme is not yet implemented")
+
+// PoseidonScheme is a stub CommitmentScheme for a future SNARK-friendly Poseidon backend. See
+// ErrPoseidonUnimplemented.
+type PoseidonScheme struct{}
+
+// NewPoseidonScheme returns a PoseidonScheme. name is accepted for interface symmetry with the
+// other schemes but is otherwise unused, since every method panics with ErrPoseidonUnimplemented.
+func NewPoseidonScheme(name string) *PoseidonScheme { return &PoseidonScheme{} }
+
+func (s *PoseidonScheme) New(name string) CommitmentScheme                    { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) Uint64Field(name string, n uint64) CommitmentScheme  { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) Uint256Field(name string, n *U256) CommitmentScheme  { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) FixedSizeField(name string, d []byte) CommitmentScheme { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) VarSizeField(name string, d []byte) CommitmentScheme { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) BoolField(name string, b bool) CommitmentScheme     { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) AddressField(name string, addr common.Address) CommitmentScheme {
+	panic(ErrPoseidonUnimplemented)
+}
+func (s *PoseidonScheme) Bytes32Field(name string, b [32]byte) CommitmentScheme { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) OptionalField(name string, c *Commitment) CommitmentScheme { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) Field(name string, c Commitment) CommitmentScheme    { panic(ErrPoseidonUnimplemented) }
+func (s *PoseidonScheme) Finalize() Commitment                                { panic(ErrPoseidonUnimplemented) }
+
+var (
+	_ CommitmentScheme = (*SHA256Scheme)(nil)
+	_ CommitmentScheme = (*KeccakScheme)(nil)
+	_ CommitmentScheme = (*PoseidonScheme)(nil)
+)
+
+// CommitWith computes self's commitment using scheme instead of Commit's own hash-coded backend.
+// The field names and order exactly match Commit, so a verifier that already understands Commit's
+// layout only needs to swap in a different CommitmentScheme to recompute a commitment under a
+// different hash.
+func (self *Header) CommitWith(scheme CommitmentScheme) Commitment {
+	var l1FinalizedComm *Commitment
+	if self.L1Finalized != nil {
+		c := self.L1Finalized.CommitWith(scheme)
+		l1FinalizedComm = &c
+	}
+
+	var withdrawalsComm *Commitment
+	if self.Withdrawals != nil {
+		c := commitWithdrawalsWith(scheme, self.Withdrawals)
+		withdrawalsComm = &c
+	}
+
+	txRootComm := self.TransactionsRoot.CommitWith(scheme)
+
+	return scheme.New("BLOCK").
+		Uint64Field("timestamp", self.Timestamp).
+		Uint64Field("l1_head", self.L1Head).
+		OptionalField("l1_finalized", l1FinalizedComm).
+		Field("transactions_root", txRootComm).
+		OptionalField("withdrawals_root", withdrawalsComm).
+		Finalize()
+}
+
+// CommitWith computes self's commitment using scheme, with the same field order L1BlockInfo.Commit
+// uses.
+func (self *L1BlockInfo) CommitWith(scheme CommitmentScheme) Commitment {
+	return scheme.New("L1BLOCK").
+		Uint64Field("number", self.Number).
+		Uint256Field("timestamp", &self.Timestamp).
+		Bytes32Field("hash", self.Hash).
+		Bytes32Field("withdrawals_root", self.WithdrawalsRoot).
+		Finalize()
+}
+
+// CommitWith computes self's commitment using scheme, with the same field order Withdrawal.Commit
+// uses.
+func (self *Withdrawal) CommitWith(scheme CommitmentScheme) Commitment {
+	return scheme.New("WITHDRAWAL").
+		Uint64Field("index", self.Index).
+		Uint64Field("validator", self.Validator).
+		AddressField("address", self.Address).
+		Uint256Field("amount", &self.Amount).
+		Finalize()
+}
+
+// commitWithdrawalsWith is commitWithdrawals, parameterized over scheme.
+func commitWithdrawalsWith(scheme CommitmentScheme, withdrawals []Withdrawal) Commitment {
+	b := scheme.New("WITHDRAWALS").Uint64Field("len", uint64(len(withdrawals)))
+	for _, w := range withdrawals {
+		b = b.Field("withdrawal", w.CommitWith(scheme))
+	}
+	return b.Finalize()
+}
+
+// CommitWith computes self's commitment using scheme, with the same field order NmtRoot.Commit
+// uses.
+func (self *NmtRoot) CommitWith(scheme CommitmentScheme) Commitment {
+	return scheme.New("NMTROOT").
+		VarSizeField("root", self.Root).
+		Finalize()
+}
@@ -0,0 +1,100 @@
+package espresso
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitmentSchemeSHA256Deterministic(t *testing.T) {
+	h := referenceHeaderForRLP()
+
+	c1 := h.CommitWith(NewSHA256Scheme("header"))
+	c2 := h.CommitWith(NewSHA256Scheme("header"))
+	require.Equal(t, c1, c2)
+}
+
+func TestCommitmentSchemeKeccakDeterministic(t *testing.T) {
+	h := referenceHeaderForRLP()
+
+	c1 := h.CommitWith(NewKeccakScheme("header"))
+	c2 := h.CommitWith(NewKeccakScheme("header"))
+	require.Equal(t, c1, c2)
+}
+
+func TestCommitmentSchemeSHA256AndKeccakDiffer(t *testing.T) {
+	h := referenceHeaderForRLP()
+
+	sha := h.CommitWith(NewSHA256Scheme("header"))
+	keccak := h.CommitWith(NewKeccakScheme("header"))
+	require.NotEqual(t, sha, keccak, "different hash backends must not coincidentally agree")
+}
+
+func TestCommitmentSchemeSensitiveToWithdrawalsPresence(t *testing.T) {
+	withWithdrawals := referenceHeaderForRLP()
+	withoutWithdrawals := withWithdrawals
+	withoutWithdrawals.Withdrawals = nil
+
+	c1 := withWithdrawals.CommitWith(NewSHA256Scheme("header"))
+	c2 := withoutWithdrawals.CommitWith(NewSHA256Scheme("header"))
+	require.NotEqual(t, c1, c2)
+}
+
+func TestCommitmentSchemeSensitiveToL1FinalizedPresence(t *testing.T) {
+	withL1Finalized := referenceHeaderForRLP()
+	withoutL1Finalized := withL1Finalized
+	withoutL1Finalized.L1Finalized = nil
+
+	c1 := withL1Finalized.CommitWith(NewSHA256Scheme("header"))
+	c2 := withoutL1Finalized.CommitWith(NewSHA256Scheme("header"))
+	require.NotEqual(t, c1, c2)
+}
+
+func TestBoolFieldDistinguishesTrueFromFalse(t *testing.T) {
+	c1 := NewSHA256Scheme("test").BoolField("flag", true).Finalize()
+	c2 := NewSHA256Scheme("test").BoolField("flag", false).Finalize()
+	require.NotEqual(t, c1, c2)
+}
+
+func TestArrayFieldSensitiveToLengthAndOrder(t *testing.T) {
+	w1 := Withdrawal{Index: 1}
+	w2 := Withdrawal{Index: 2}
+
+	// Withdrawal.Commit has a pointer receiver, so *Withdrawal is the Committable element type.
+	base := ArrayField(NewSHA256Scheme("test"), "withdrawals", []*Withdrawal{&w1, &w2}).Finalize()
+
+	t.Run("same elements, different order", func(t *testing.T) {
+		reordered := ArrayField(NewSHA256Scheme("test"), "withdrawals", []*Withdrawal{&w2, &w1}).Finalize()
+		require.NotEqual(t, base, reordered)
+	})
+
+	t.Run("extra element", func(t *testing.T) {
+		longer := ArrayField(NewSHA256Scheme("test"), "withdrawals", []*Withdrawal{&w1, &w2, &w2}).Finalize()
+		require.NotEqual(t, base, longer)
+	})
+
+	t.Run("empty array", func(t *testing.T) {
+		empty := ArrayField(NewSHA256Scheme("test"), "withdrawals", []*Withdrawal{}).Finalize()
+		require.NotEqual(t, base, empty)
+	})
+}
+
+func TestOptionalFieldCombinatorDistinguishesNoneFromSomeZero(t *testing.T) {
+	none := OptionalField(NewSHA256Scheme("test"), "opt", false, func(s CommitmentScheme) {
+		s.Uint64Field("value", 0)
+	}).Finalize()
+
+	someZero := OptionalField(NewSHA256Scheme("test"), "opt", true, func(s CommitmentScheme) {
+		s.Uint64Field("value", 0)
+	}).Finalize()
+
+	require.NotEqual(t, none, someZero, "a present zero value must not collide with absence")
+}
+
+func TestPoseidonSchemePanicsUnimplemented(t *testing.T) {
+	h := referenceHeaderForRLP()
+
+	require.PanicsWithValue(t, ErrPoseidonUnimplemented, func() {
+		h.CommitWith(NewPoseidonScheme("header"))
+	})
+}
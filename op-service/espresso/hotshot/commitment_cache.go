@@ -0,0 +1,136 @@
+package hotshot
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso"
+)
+
+// DefaultCommitmentCacheSize bounds the number of per-height commitments HotShotProvider caches by
+// default. A freshly-verified header range is usually small (one sequencing window), so this is
+// sized for a batch poster or verifier that is replaying or re-verifying several recent windows at
+// once, the same way JustificationFetcherConfig.MaxCacheSize is sized in the derive package.
+const DefaultCommitmentCacheSize = 1024
+
+// commitmentCache caches HotShot's on-chain commitment at each height HotShotProvider has already
+// fetched, evicting least-recently-used entries past maxSize, and coalesces concurrent fetches of
+// the same height into a single on-chain call -- the same two jobs
+// derive.JustificationFetcher's cache and inflight map do for espresso headers.
+//
+// Caching is per-height rather than per-range: GetCommitmentsFromHeight already requests a
+// contiguous range, but two overlapping VerifyHeaders calls (e.g. one verifying heights 10-15 and
+// another verifying 12-18) otherwise have no way to share the heights they have in common. Per-height
+// caching and coalescing gets that sharing for free, without the cache needing to reason about which
+// ranges overlap.
+type commitmentCache struct {
+	maxSize int
+
+	mu       sync.Mutex
+	entries  map[uint64]espresso.NmtRoot
+	lru      *list.List
+	lruElems map[uint64]*list.Element
+	inflight map[uint64]chan struct{}
+}
+
+func newCommitmentCache(maxSize int) *commitmentCache {
+	return &commitmentCache{
+		maxSize:  maxSize,
+		entries:  make(map[uint64]espresso.NmtRoot),
+		lru:      list.New(),
+		lruElems: make(map[uint64]*list.Element),
+		inflight: make(map[uint64]chan struct{}),
+	}
+}
+
+// get returns the cached root for height, or otherwise fetches it with fetch and caches it.
+//
+// If another goroutine is already fetching height, get waits for that fetch to finish and reuses
+// its result if it was cached; if that fetch failed (or found no commitment yet), get fetches the
+// height again itself.
+func (c *commitmentCache) get(height uint64, fetch func(uint64) (espresso.NmtRoot, error)) (espresso.NmtRoot, error) {
+	c.mu.Lock()
+	if root, ok := c.entries[height]; ok {
+		c.touch(height)
+		c.mu.Unlock()
+		return root, nil
+	}
+	if wait, ok := c.inflight[height]; ok {
+		c.mu.Unlock()
+		<-wait
+		return c.get(height, fetch)
+	}
+	wait := make(chan struct{})
+	c.inflight[height] = wait
+	c.mu.Unlock()
+
+	root, err := fetch(height)
+
+	c.mu.Lock()
+	delete(c.inflight, height)
+	// A height with no commitment yet (an empty root) is not cached: HotShot is expected to commit
+	// it imminently, and caching "not yet committed" would otherwise wrongly stick once it does.
+	if err == nil && len(root.Root) > 0 {
+		c.store(height, root)
+	}
+	c.mu.Unlock()
+	close(wait)
+
+	return root, err
+}
+
+// put inserts height's commitment directly into the cache, as if get's fetch callback had just
+// returned it. WatchNewCommitments uses this to populate the cache from a live NewBlocks
+// subscription, so a later GetCommitmentsFromHeight call for that height is served from cache
+// instead of making a redundant on-chain call.
+func (c *commitmentCache) put(height uint64, root espresso.NmtRoot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(root.Root) > 0 {
+		c.store(height, root)
+	}
+}
+
+// invalidate drops every cached height >= fromHeight. It is called after an L1 reorg that may have
+// changed (or un-committed) any HotShot commitment recorded on or after the new fork point, so a
+// stale cached root is never served past that point.
+func (c *commitmentCache) invalidate(fromHeight uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for height, elem := range c.lruElems {
+		if height >= fromHeight {
+			c.lru.Remove(elem)
+			delete(c.lruElems, height)
+			delete(c.entries, height)
+		}
+	}
+}
+
+// touch marks height as most-recently-used. Callers must hold c.mu.
+func (c *commitmentCache) touch(height uint64) {
+	if elem, ok := c.lruElems[height]; ok {
+		c.lru.MoveToFront(elem)
+	}
+}
+
+// store inserts (or refreshes) a cache entry, evicting the least-recently-used entry if the cache
+// is full. Callers must hold c.mu.
+func (c *commitmentCache) store(height uint64, root espresso.NmtRoot) {
+	if elem, ok := c.lruElems[height]; ok {
+		c.lru.MoveToFront(elem)
+		c.entries[height] = root
+		return
+	}
+	c.entries[height] = root
+	c.lruElems[height] = c.lru.PushFront(height)
+	for c.lru.Len() > c.maxSize {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			break
+		}
+		oldestHeight := oldest.Value.(uint64)
+		c.lru.Remove(oldest)
+		delete(c.lruElems, oldestHeight)
+		delete(c.entries, oldestHeight)
+	}
+}
@@ -2,18 +2,41 @@ package hotshot
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/op-service/espresso"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+// newCommitmentsResubscribeBackoff bounds how long WatchNewCommitments waits between resubscribe
+// attempts after the upstream NewBlocks subscription fails; event.Resubscribe backs off up to this
+// value, the same way go-ethereum's own RPC client subscriptions do.
+const newCommitmentsResubscribeBackoff = 10 * time.Second
+
 type HotShotProvider struct {
 	HotShot *Hotshot
+
+	// AllowMixedHeaderVersions lets VerifyHeaders accept a range of headers whose Version fields
+	// differ. The default (false) is to reject such a range: this tree only knows how to verify
+	// version-0 headers (see espresso.Header.Version), so a mixed-version range is a sign that some
+	// header in it is of a version this provider doesn't actually know how to check, and silently
+	// comparing it against TransactionsRoot anyway could pass verification for the wrong reason.
+	AllowMixedHeaderVersions bool
+
+	// commitments caches and coalesces GetCommitmentsFromHeight's on-chain lookups. It is lazily
+	// initialized on first use, rather than required at construction, so a HotShotProvider built as
+	// a bare struct literal still works.
+	commitmentsOnce sync.Once
+	commitments     *commitmentCache
 }
 
 func NewHotShotProvider(l1Url string, hotshotAddr string) (*HotShotProvider, error) {
@@ -31,38 +54,254 @@ func NewHotShotProvider(l1Url string, hotshotAddr string) (*HotShotProvider, err
 
 }
 
+// commitmentCache returns provider's commitment cache, initializing it with
+// DefaultCommitmentCacheSize on first use.
+func (provider *HotShotProvider) commitmentCache() *commitmentCache {
+	provider.commitmentsOnce.Do(func() {
+		provider.commitments = newCommitmentCache(DefaultCommitmentCacheSize)
+	})
+	return provider.commitments
+}
+
+// InvalidateCommitmentsFrom drops every cached commitment at or after fromHeight. Callers should
+// invoke this after observing an L1 reorg that reaches back to fromHeight, since a commitment
+// cached from the old fork may no longer match (or may no longer exist) on the new one.
+func (provider *HotShotProvider) InvalidateCommitmentsFrom(fromHeight uint64) {
+	provider.commitmentCache().invalidate(fromHeight)
+}
+
+// WatchNewCommitments subscribes to the HotShot contract's NewBlocks event starting at fromBlock (or
+// at opts.Start, if that is set), delivering each decoded NewBlocksEvent to sink and, before that, caching its commitment so a
+// subsequent GetCommitmentsFromHeight call for that height is served from cache rather than making
+// a redundant on-chain call. This is how VerifyHeaders/VerifyHeadersDetailed consume the event
+// stream: they always go through GetCommitmentsFromHeight, and that already checks the cache first
+// (see commitmentCache's doc comment), so a height WatchNewCommitments has already delivered is
+// served immediately with no further polling, while a height it hasn't reached yet falls back to
+// fetchCommitment's eth_call the same as before. The cache itself is therefore the buffer: no
+// separate by-height buffering structure is needed on top of it.
+//
+// Unlike HotshotFilterer.WatchNewBlocks, the returned subscription resubscribes on error (via
+// event.Resubscribe's exponential backoff, capped at newCommitmentsResubscribeBackoff) rather than
+// terminating, since a long-lived caller (e.g. derive.HeaderFeed) should not have to notice a dropped
+// L1 subscription and manually restart it.
+func (provider *HotShotProvider) WatchNewCommitments(opts *bind.WatchOpts, sink chan<- *NewBlocksEvent, fromBlock uint64) (event.Subscription, error) {
+	startOpts := *opts
+	if startOpts.Start == nil {
+		startOpts.Start = &fromBlock
+	}
+
+	// Fail fast on the first subscribe attempt, so a caller misconfiguration (a bad RPC URL, an
+	// unconfirmed contract address) surfaces immediately instead of being retried silently forever by
+	// event.Resubscribe below.
+	probe, err := provider.HotShot.HotshotFilterer.WatchNewBlocks(&startOpts, make(chan *NewBlocksEvent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to HotShot NewBlocks: %w", err)
+	}
+	probe.Unsubscribe()
+
+	return event.Resubscribe(newCommitmentsResubscribeBackoff, func(ctx context.Context) (event.Subscription, error) {
+		raw := make(chan *NewBlocksEvent)
+		watchOpts := startOpts
+		watchOpts.Context = ctx
+		sub, err := provider.HotShot.HotshotFilterer.WatchNewBlocks(&watchOpts, raw)
+		if err != nil {
+			return nil, err
+		}
+		go provider.forwardNewCommitments(ctx, raw, sink)
+		return sub, nil
+	}), nil
+}
+
+// forwardNewCommitments relays events from raw to sink, caching each one's commitment along the
+// way, until ctx is done. It is the body of the resubscribe loop in WatchNewCommitments, split out
+// so that loop only has to deal with subscribing, not the forwarding logic itself.
+func (provider *HotShotProvider) forwardNewCommitments(ctx context.Context, raw <-chan *NewBlocksEvent, sink chan<- *NewBlocksEvent) {
+	for {
+		select {
+		case ev := <-raw:
+			provider.commitmentCache().put(ev.BlockHeight, ev.Commitment)
+			select {
+			case sink <- ev:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (provider *HotShotProvider) VerifyHeaders(headers []espresso.Header, height uint64) (bool, error) {
+	result := provider.VerifyHeadersDetailed(headers, height)
+	if result.Category == FetcherError {
+		return false, result.Err
+	}
+	return result.OK, nil
+}
+
+// MismatchCategory classifies why VerifyHeadersDetailed found a header range invalid, for a caller
+// that wants to distinguish "HotShot hasn't committed this height yet" from "the header itself is
+// wrong" rather than getting back VerifyHeaders' bare false.
+type MismatchCategory int
+
+const (
+	// NoMismatch means every header's TransactionsRoot matched its on-chain commitment.
+	NoMismatch MismatchCategory = iota
+	// LengthMismatch means GetCommitmentsFromHeight returned a different number of commitments than
+	// headers provided; FirstMismatchIndex, Expected, and Provided are not meaningful.
+	LengthMismatch
+	// RootMismatch means the header at FirstMismatchIndex has a non-empty on-chain commitment that
+	// differs from its TransactionsRoot.
+	RootMismatch
+	// MissingOnChain means the on-chain commitment at FirstMismatchIndex's height is still empty,
+	// i.e. HotShot has not committed that height yet.
+	MissingOnChain
+	// FetcherError means GetCommitmentsFromHeight itself failed; see Err.
+	FetcherError
+)
+
+// VerificationResult is VerifyHeadersDetailed's richer alternative to VerifyHeaders' bare
+// (bool, error): enough detail for a caller (such as an operator alert) to say specifically what about a header range didn't match, rather than just that it didn't.
+type VerificationResult struct {
+	OK       bool
+	Category MismatchCategory
+
+	// FirstMismatchIndex is the index into the headers slice passed to VerifyHeadersDetailed of the
+	// first header that did not match. Valid when Category is RootMismatch or MissingOnChain.
+	FirstMismatchIndex int
+	// Expected is the on-chain NmtRoot at FirstMismatchIndex's height. Valid under the same
+	// conditions as FirstMismatchIndex.
+	Expected espresso.NmtRoot
+	// Provided is the TransactionsRoot the header at FirstMismatchIndex actually carried. Valid
+	// under the same conditions as FirstMismatchIndex.
+	Provided espresso.NmtRoot
+
+	// Err is set when Category is FetcherError.
+	Err error
+}
+
+// VerifyHeadersDetailed is VerifyHeaders with structured diagnostics in place of a bare bool. It
+// does not report the L1 block number/hash the on-chain commitment was recorded in: the Hotshot
+// contract binding this provider calls (HotshotCaller.Commitments) is keyed purely by HotShot
+// height and returns only the commitment itself, with no L1 block reference attached, so that
+// information is not available to attach here without a different (and not-yet-bound) contract
+// view.
+func (provider *HotShotProvider) VerifyHeadersDetailed(headers []espresso.Header, height uint64) VerificationResult {
+	if !provider.AllowMixedHeaderVersions {
+		for i := 1; i < len(headers); i++ {
+			if headers[i].Version != headers[0].Version {
+				return VerificationResult{Category: FetcherError, Err: fmt.Errorf("headers at height %d span versions %d and %d; set AllowMixedHeaderVersions to verify anyway", height, headers[0].Version, headers[i].Version)}
+			}
+		}
+	}
+	for _, h := range headers {
+		if h.Version != 0 {
+			return VerificationResult{Category: FetcherError, Err: fmt.Errorf("HotShotProvider only knows how to verify version-0 headers, got version %d", h.Version)}
+		}
+	}
+
 	fetchedHeaders, err := provider.GetCommitmentsFromHeight(height, uint64(len(headers)))
 	if err != nil {
-		return false, err
+		return VerificationResult{Category: FetcherError, Err: err}
 	}
 
 	if len(fetchedHeaders) != len(headers) {
-		return false, fmt.Errorf("fetched headers has a different length than provided headers (%d vs %d)", len(fetchedHeaders), len(headers))
+		return VerificationResult{Category: LengthMismatch, Err: fmt.Errorf("fetched headers has a different length than provided headers (%d vs %d)", len(fetchedHeaders), len(headers))}
 	}
 
 	for i := 0; i < len(fetchedHeaders); i++ {
-		if !bytes.Equal(headers[i].TransactionsRoot.Root, fetchedHeaders[i].Root) {
-			return false, nil
+		if bytes.Equal(headers[i].TransactionsRoot.Root, fetchedHeaders[i].Root) {
+			continue
+		}
+		category := RootMismatch
+		if len(fetchedHeaders[i].Root) == 0 {
+			category = MissingOnChain
+		}
+		return VerificationResult{
+			Category:           category,
+			FirstMismatchIndex: i,
+			Expected:           fetchedHeaders[i],
+			Provided:           headers[i].TransactionsRoot,
 		}
 	}
 
-	return true, nil
+	return VerificationResult{OK: true, Category: NoMismatch}
+}
+
+// ErrHotShotStalled is returned by CheckLiveness when HotShot has not committed a block within
+// MaxLag of ExpectedHeight, so callers on the derivation side can distinguish "HotShot is down" from
+// an ordinary transient fetch error and fall back to a legacy batch source instead of retrying
+// forever.
+type ErrHotShotStalled struct {
+	ExpectedHeight uint64
+	LastLiveHeight uint64
+	MaxLag         uint64
+}
+
+func (e *ErrHotShotStalled) Error() string {
+	return fmt.Sprintf("HotShot has not committed a block within %d of expected height %d (last live height %d)", e.MaxLag, e.ExpectedHeight, e.LastLiveHeight)
 }
 
+// CheckLiveness reports whether HotShot is live as of expectedHeight, by walking backward from
+// expectedHeight for up to maxLag heights looking for the first one with a non-empty on-chain
+// commitment. It returns nil if one is found within maxLag of expectedHeight, and *ErrHotShotStalled
+// otherwise.
+//
+// This tree's Hotshot contract binding has only the single Commitments(height) view already used by
+// GetCommitmentsFromHeight, with no dedicated "latest committed height" query to check liveness
+// against directly. CheckLiveness infers "not yet committed" from an empty commitment the same way
+// GetCommitmentsFromHeight's callers already have to when probing past HotShot's current tip; a real
+// deployment's ABI may expose a more direct signal, but this is the best approximation available
+// without it.
+func (provider *HotShotProvider) CheckLiveness(expectedHeight uint64, maxLag uint64) error {
+	low := uint64(0)
+	if expectedHeight > maxLag {
+		low = expectedHeight - maxLag
+	}
+	for h := expectedHeight; ; h-- {
+		roots, err := provider.GetCommitmentsFromHeight(h, 1)
+		if err != nil {
+			return fmt.Errorf("failed to check HotShot liveness at height %d: %w", h, err)
+		}
+		if len(roots) == 1 && len(roots[0].Root) > 0 {
+			if expectedHeight-h > maxLag {
+				return &ErrHotShotStalled{ExpectedHeight: expectedHeight, LastLiveHeight: h, MaxLag: maxLag}
+			}
+			return nil
+		}
+		if h == low {
+			break
+		}
+	}
+	return &ErrHotShotStalled{ExpectedHeight: expectedHeight, LastLiveHeight: low, MaxLag: maxLag}
+}
+
+// GetCommitmentsFromHeight returns the on-chain NmtRoot committed at each of the numHeaders heights
+// starting at firstBlockHeight. Each height is served from provider's commitment cache if already
+// known, and concurrent requests for the same height are coalesced into a single contract call; see
+// commitmentCache's doc comment for why that sharing is done per-height rather than per-range.
 func (provider *HotShotProvider) GetCommitmentsFromHeight(firstBlockHeight uint64, numHeaders uint64) ([]espresso.NmtRoot, error) {
+	cache := provider.commitmentCache()
 	var comms []espresso.NmtRoot
 	for i := 0; i < int(numHeaders); i++ {
-		var height big.Int
-		height.SetUint64(firstBlockHeight + uint64(i))
-		comm, err := provider.HotShot.HotshotCaller.Commitments(nil, &height)
+		height := firstBlockHeight + uint64(i)
+		root, err := cache.get(height, provider.fetchCommitment)
 		if err != nil {
 			return comms, err
 		}
-		root := espresso.NmtRoot{
-			Root: comm.Bytes(),
-		}
 		comms = append(comms, root)
 	}
 	return comms, nil
 }
+
+// fetchCommitment performs the actual on-chain lookup of the commitment at height. It does not
+// consult or populate the cache; callers should go through GetCommitmentsFromHeight.
+func (provider *HotShotProvider) fetchCommitment(height uint64) (espresso.NmtRoot, error) {
+	var h big.Int
+	h.SetUint64(height)
+	comm, err := provider.HotShot.HotshotCaller.Commitments(nil, &h)
+	if err != nil {
+		return espresso.NmtRoot{}, err
+	}
+	return espresso.NmtRoot{Root: comm.Bytes()}, nil
+}
@@ -0,0 +1,96 @@
+package hotshot
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-service/espresso"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// NewBlocksEvent is HotShotProvider's typed decoding of the NewBlocks event the HotShot contract
+// emits once it finalizes a commitment, as unpacked by HotshotFilterer.WatchNewBlocks below.
+type NewBlocksEvent struct {
+	// BlockHeight is the HotShot height the finalized commitment covers.
+	BlockHeight uint64
+	// Commitment is the on-chain commitment root recorded for BlockHeight, in the same format
+	// fetchCommitment already produces from HotshotCaller.Commitments.
+	Commitment espresso.NmtRoot
+	// L1Block is the number of the L1 block the NewBlocks log was included in.
+	L1Block uint64
+	// TxHash is the hash of the L1 transaction that emitted the log.
+	TxHash common.Hash
+	// Raw is the underlying log, for a caller that needs more than the fields above (e.g. to
+	// deduplicate against a reorg by log index).
+	Raw types.Log
+}
+
+// WatchNewBlocks subscribes to the HotShot contract's NewBlocks event, following the same
+// WatchLogs/UnpackLog flow abigen generates for every other contract event's Watch<Event> method. It
+// has no resubscribe-on-error behavior of its own: that is HotShotProvider.WatchNewCommitments' job,
+// layered on top of single subscription attempts here, mirroring the separation between abigen's
+// generated Watch* methods and a caller-provided retry loop.
+//
+// This checkout has no generated Hotshot contract bindings: HotshotCaller and the *bind.BoundContract
+// it wraps are referenced by fetchCommitment below but never declared in this tree (HotShotProvider
+// and NewHotShotProvider depend on the same missing binding). HotshotFilterer and its contract
+// field are written exactly the way abigen would generate them for the NewBlocks event, so this
+// slots into the existing (also undeclared) Hotshot binding once it exists, rather than compiling
+// here on its own.
+type HotshotFilterer struct {
+	contract *bind.BoundContract
+}
+
+func (_Hotshot *HotshotFilterer) WatchNewBlocks(opts *bind.WatchOpts, sink chan<- *NewBlocksEvent) (event.Subscription, error) {
+	logs, sub, err := _Hotshot.contract.WatchLogs(opts, "NewBlocks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch NewBlocks logs: %w", err)
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := decodeNewBlocksLog(_Hotshot.contract, log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// decodeNewBlocksLog unpacks a raw NewBlocks log into a NewBlocksEvent. A real generated binding
+// would unpack into a dedicated HotshotNewBlocks struct; this tree has no such struct (see
+// WatchNewBlocks's doc comment), so the ABI fields are decoded into an anonymous struct instead.
+func decodeNewBlocksLog(contract *bind.BoundContract, log types.Log) (*NewBlocksEvent, error) {
+	var raw struct {
+		BlockHeight *big.Int
+		Commitment  [32]byte
+	}
+	if err := contract.UnpackLog(&raw, "NewBlocks", log); err != nil {
+		return nil, fmt.Errorf("failed to unpack NewBlocks log: %w", err)
+	}
+	return &NewBlocksEvent{
+		BlockHeight: raw.BlockHeight.Uint64(),
+		Commitment:  espresso.NmtRoot{Root: raw.Commitment[:]},
+		L1Block:     log.BlockNumber,
+		TxHash:      log.TxHash,
+		Raw:         log,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package espresso
+
+import (
+	"runtime"
+	"sync"
+)
+
+// NamespacedTx pairs a transaction with the namespace it belongs to. A slice passed to
+// ParallelNmtBuilder.Build must already be grouped so that every namespace's transactions are
+// contiguous, the same layout NmtProof.Verify expects when walking a namespace's siblings.
+type NamespacedTx struct {
+	Namespace uint64
+	Tx        Bytes
+}
+
+// ParallelNmtBuilder builds the namespaced Merkle root (and one NmtProof per namespace) over a
+// batch of transactions, following the same two-level fold this package's own tests already use
+// as their reference construction (see buildTree in nmt_test.go): each namespace's own leaves are
+// folded together first, and those per-namespace nodes are then folded across namespaces, both
+// left to right via nmtCombine.
+//
+// Note on parallelism: nmtCombine chains digests left to right (H(H(a,b),c), not a balanced
+// H(H(a,b),H(c,d))), so both fold steps above are inherently serial and can't be restructured
+// without changing every root this package has ever produced. What can be parallelized without
+// changing the result is computing each leaf's own hash (crypto.Keccak256(tx)), which is a pure
+// function of that single transaction's bytes and dominates build time once a block has thousands
+// of (possibly large) transactions; ParallelNmtBuilder parallelizes exactly that step and leaves
+// both folds sequential, so its root and proofs are always byte-identical to a purely sequential
+// build.
+type ParallelNmtBuilder struct {
+	// ParallelThreshold is the leaf count above which leaf hashing is split across a worker pool
+	// sized to GOMAXPROCS. Below it, everything, including hashing, runs on the calling goroutine,
+	// since spinning up workers costs more than it saves for small batches. Zero means use the
+	// default of 100; see NewParallelNmtBuilder.
+	ParallelThreshold int
+}
+
+// NewParallelNmtBuilder returns a ParallelNmtBuilder with the default ParallelThreshold of 100
+// leaves.
+func NewParallelNmtBuilder() *ParallelNmtBuilder {
+	return &ParallelNmtBuilder{ParallelThreshold: 100}
+}
+
+func (b *ParallelNmtBuilder) threshold() int {
+	if b.ParallelThreshold <= 0 {
+		return 100
+	}
+	return b.ParallelThreshold
+}
+
+// nmtRun is one contiguous run of leaves belonging to the same namespace within txs.
+type nmtRun struct {
+	namespace  uint64
+	start, end int // leaf index range [start, end)
+}
+
+func groupNmtRuns(txs []NamespacedTx) []nmtRun {
+	var runs []nmtRun
+	for i, t := range txs {
+		if len(runs) > 0 && runs[len(runs)-1].namespace == t.Namespace {
+			runs[len(runs)-1].end = i + 1
+			continue
+		}
+		runs = append(runs, nmtRun{namespace: t.Namespace, start: i, end: i + 1})
+	}
+	return runs
+}
+
+// Build computes the namespaced Merkle root over txs, along with one NmtProof per contiguous
+// namespace run, the same way buildTree in this package's tests always has; the only difference
+// from a hand-written sequential loop is that leaf hashing may run in parallel.
+func (b *ParallelNmtBuilder) Build(txs []NamespacedTx) (NmtRoot, []NmtProof) {
+	if len(txs) == 0 {
+		return NmtRoot{}, nil
+	}
+
+	leaves := b.hashLeaves(txs)
+	runs := groupNmtRuns(txs)
+
+	runNodes := make([]nmtNode, len(runs))
+	for i, r := range runs {
+		acc := leaves[r.start]
+		for j := r.start + 1; j < r.end; j++ {
+			acc = nmtCombine(acc, leaves[j])
+		}
+		runNodes[i] = acc
+	}
+
+	// prefix[i] folds runNodes[0:i] together; prefix[0] is never consulted.
+	prefix := make([]nmtNode, len(runNodes))
+	acc := runNodes[0]
+	for i := 1; i < len(runNodes); i++ {
+		prefix[i] = acc
+		acc = nmtCombine(acc, runNodes[i])
+	}
+	root := NmtRoot{Root: acc.digest}
+
+	proofs := make([]NmtProof, len(runs))
+	for i, r := range runs {
+		var siblings []NmtSibling
+		if i > 0 {
+			p := prefix[i]
+			siblings = append(siblings, NmtSibling{MinNamespace: p.min, MaxNamespace: p.max, Digest: Bytes(p.digest), Left: true})
+		}
+		for j := i + 1; j < len(runs); j++ {
+			n := runNodes[j]
+			siblings = append(siblings, NmtSibling{MinNamespace: n.min, MaxNamespace: n.max, Digest: Bytes(n.digest), Left: false})
+		}
+		proofs[i] = NmtProof{
+			Namespace: r.namespace,
+			Start:     uint64(r.start),
+			End:       uint64(r.end),
+			Siblings:  siblings,
+		}
+	}
+	return root, proofs
+}
+
+// hashLeaves computes nmtLeaf for every tx in txs, splitting the work across a worker pool once
+// len(txs) exceeds b.threshold(). The output order always matches the input order regardless of
+// how the work was scheduled, since each worker only ever writes the disjoint index range it was
+// assigned.
+func (b *ParallelNmtBuilder) hashLeaves(txs []NamespacedTx) []nmtNode {
+	leaves := make([]nmtNode, len(txs))
+	if len(txs) <= b.threshold() {
+		for i, t := range txs {
+			leaves[i] = nmtLeaf(t.Namespace, t.Tx)
+		}
+		return leaves
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	chunk := (len(txs) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for start := 0; start < len(txs); start += chunk {
+		end := start + chunk
+		if end > len(txs) {
+			end = len(txs)
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				leaves[i] = nmtLeaf(txs[i].Namespace, txs[i].Tx)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return leaves
+}
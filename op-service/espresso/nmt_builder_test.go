@@ -0,0 +1,109 @@
+package espresso
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func namespacedTxs(blocks []nmtBlock) []NamespacedTx {
+	var out []NamespacedTx
+	for _, b := range blocks {
+		for _, t := range b.txs {
+			out = append(out, NamespacedTx{Namespace: b.namespace, Tx: t})
+		}
+	}
+	return out
+}
+
+func TestParallelNmtBuilderMatchesSequentialReference(t *testing.T) {
+	blocks := []nmtBlock{
+		{namespace: 1, txs: []Bytes{tx("ns1-a"), tx("ns1-b")}},
+		{namespace: 2, txs: []Bytes{tx("ns2-a")}},
+		{namespace: 3, txs: []Bytes{tx("ns3-a"), tx("ns3-b"), tx("ns3-c")}},
+	}
+	wantRoot, wantProofs := buildTree(blocks)
+	txs := namespacedTxs(blocks)
+
+	// Below ParallelThreshold: hashing runs on the calling goroutine.
+	b := &ParallelNmtBuilder{ParallelThreshold: 100}
+	root, proofs := b.Build(txs)
+	require.Equal(t, wantRoot, root)
+	require.Equal(t, wantProofs, proofs)
+
+	// Above ParallelThreshold: hashing is split across a worker pool, but the fold itself is
+	// unchanged, so the result must be byte-identical.
+	b = &ParallelNmtBuilder{ParallelThreshold: 1}
+	root, proofs = b.Build(txs)
+	require.Equal(t, wantRoot, root)
+	require.Equal(t, wantProofs, proofs)
+
+	for i, p := range proofs {
+		require.NoError(t, p.Verify(root, blocks[i].namespace, blocks[i].txs))
+	}
+}
+
+func TestParallelNmtBuilderDefaultThreshold(t *testing.T) {
+	b := NewParallelNmtBuilder()
+	require.Equal(t, 100, b.ParallelThreshold)
+
+	var zero ParallelNmtBuilder
+	require.Equal(t, 100, zero.threshold())
+}
+
+func TestParallelNmtBuilderEmpty(t *testing.T) {
+	b := NewParallelNmtBuilder()
+	root, proofs := b.Build(nil)
+	require.Equal(t, NmtRoot{}, root)
+	require.Nil(t, proofs)
+}
+
+func TestParallelNmtBuilderLargeBatchMatchesSequential(t *testing.T) {
+	var blocks []nmtBlock
+	for ns := uint64(0); ns < 20; ns++ {
+		var txs []Bytes
+		for i := 0; i < 50; i++ {
+			txs = append(txs, tx(fmt.Sprintf("ns%d-tx%d", ns, i)))
+		}
+		blocks = append(blocks, nmtBlock{namespace: ns, txs: txs})
+	}
+	wantRoot, wantProofs := buildTree(blocks)
+	txsIn := namespacedTxs(blocks)
+
+	b := NewParallelNmtBuilder()
+	root, proofs := b.Build(txsIn)
+	require.Equal(t, wantRoot, root)
+	require.Equal(t, wantProofs, proofs)
+
+	for i, p := range proofs {
+		require.NoError(t, p.Verify(root, blocks[i].namespace, blocks[i].txs))
+	}
+}
+
+func benchmarkNamespacedTxs(n int) []NamespacedTx {
+	txs := make([]NamespacedTx, n)
+	for i := range txs {
+		txs[i] = NamespacedTx{Namespace: uint64(i % 8), Tx: Bytes(fmt.Sprintf("tx-%d", i))}
+	}
+	return txs
+}
+
+func benchmarkParallelNmtBuilder(b *testing.B, n int, threshold int) {
+	txs := benchmarkNamespacedTxs(n)
+	builder := &ParallelNmtBuilder{ParallelThreshold: threshold}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		builder.Build(txs)
+	}
+}
+
+func BenchmarkParallelNmtBuilderSequential10(b *testing.B)  { benchmarkParallelNmtBuilder(b, 10, 1<<30) }
+func BenchmarkParallelNmtBuilderSequential100(b *testing.B) { benchmarkParallelNmtBuilder(b, 100, 1<<30) }
+func BenchmarkParallelNmtBuilderSequential1k(b *testing.B)  { benchmarkParallelNmtBuilder(b, 1_000, 1<<30) }
+func BenchmarkParallelNmtBuilderSequential10k(b *testing.B) { benchmarkParallelNmtBuilder(b, 10_000, 1<<30) }
+func BenchmarkParallelNmtBuilderParallel10(b *testing.B)    { benchmarkParallelNmtBuilder(b, 10, 100) }
+func BenchmarkParallelNmtBuilderParallel100(b *testing.B)   { benchmarkParallelNmtBuilder(b, 100, 100) }
+func BenchmarkParallelNmtBuilderParallel1k(b *testing.B)    { benchmarkParallelNmtBuilder(b, 1_000, 100) }
+func BenchmarkParallelNmtBuilderParallel10k(b *testing.B)   { benchmarkParallelNmtBuilder(b, 10_000, 100) }
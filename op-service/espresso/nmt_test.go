@@ -0,0 +1,204 @@
+package espresso
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// nmtBlock is one contiguous, single-namespace run of leaves used to build a hand-constructed
+// tree for these tests: a real Espresso block interleaves many such runs, one per VM namespace.
+type nmtBlock struct {
+	namespace uint64
+	txs       []Bytes
+}
+
+// fold combines nodes left-to-right with nmtCombine, exactly mirroring how NmtProof.Verify folds
+// a namespace's own leaves into a single node before consulting the proof's siblings.
+func fold(nodes ...nmtNode) nmtNode {
+	acc := nodes[0]
+	for _, n := range nodes[1:] {
+		acc = nmtCombine(acc, n)
+	}
+	return acc
+}
+
+func (b nmtBlock) node() nmtNode {
+	leaves := make([]nmtNode, len(b.txs))
+	for i, tx := range b.txs {
+		leaves[i] = nmtLeaf(b.namespace, tx)
+	}
+	return fold(leaves...)
+}
+
+// buildTree lays out blocks left to right and folds their per-block nodes together the same way,
+// producing the root that would appear in a Header.TransactionsRoot. It also returns, for each
+// block index, the NmtProof a query service would hand back for that block's namespace: a prior
+// sibling bundling everything to the left (if any), and one sibling per block to the right, in
+// order, so that replaying them via NmtProof.Verify reproduces the same fold.
+func buildTree(blocks []nmtBlock) (root NmtRoot, proofs []NmtProof) {
+	nodes := make([]nmtNode, len(blocks))
+	for i, b := range blocks {
+		nodes[i] = b.node()
+	}
+
+	// prefix[i] folds nodes[0:i] together; prefix[0] is the zero value and never consulted.
+	prefix := make([]nmtNode, len(blocks))
+	for i := 1; i < len(blocks); i++ {
+		if i == 1 {
+			prefix[i] = nodes[0]
+		} else {
+			prefix[i] = nmtCombine(prefix[i-1], nodes[i-1])
+		}
+	}
+
+	acc := nodes[0]
+	for i := 1; i < len(blocks); i++ {
+		acc = nmtCombine(acc, nodes[i])
+	}
+	root = NmtRoot{Root: acc.digest}
+
+	proofs = make([]NmtProof, len(blocks))
+	var start uint64
+	for i, b := range blocks {
+		var siblings []NmtSibling
+		if i > 0 {
+			p := prefix[i]
+			siblings = append(siblings, NmtSibling{MinNamespace: p.min, MaxNamespace: p.max, Digest: Bytes(p.digest), Left: true})
+		}
+		for j := i + 1; j < len(blocks); j++ {
+			n := nodes[j]
+			siblings = append(siblings, NmtSibling{MinNamespace: n.min, MaxNamespace: n.max, Digest: Bytes(n.digest), Left: false})
+		}
+		proofs[i] = NmtProof{
+			Namespace: b.namespace,
+			Start:     start,
+			End:       start + uint64(len(b.txs)),
+			Siblings:  siblings,
+		}
+		start += uint64(len(b.txs))
+	}
+	return root, proofs
+}
+
+func tx(s string) Bytes {
+	return Bytes(s)
+}
+
+func TestNmtProofVerify(t *testing.T) {
+	blocks := []nmtBlock{
+		{namespace: 1, txs: []Bytes{tx("ns1-a"), tx("ns1-b")}},
+		{namespace: 2, txs: []Bytes{tx("ns2-a")}},
+		{namespace: 3, txs: []Bytes{tx("ns3-a"), tx("ns3-b"), tx("ns3-c")}},
+	}
+	root, proofs := buildTree(blocks)
+
+	t.Run("single-leaf namespace in the middle", func(t *testing.T) {
+		require.NoError(t, proofs[1].Verify(root, 2, blocks[1].txs))
+	})
+
+	t.Run("VerifyNamespace is equivalent to Verify", func(t *testing.T) {
+		require.NoError(t, VerifyNamespace(root, 2, blocks[1].txs, proofs[1]))
+		require.Error(t, VerifyNamespace(root, 2, blocks[0].txs, proofs[1]))
+	})
+
+	t.Run("multi-leaf namespace at the start", func(t *testing.T) {
+		require.NoError(t, proofs[0].Verify(root, 1, blocks[0].txs))
+	})
+
+	t.Run("multi-leaf namespace at the end", func(t *testing.T) {
+		require.NoError(t, proofs[2].Verify(root, 3, blocks[2].txs))
+	})
+
+	t.Run("range spanning the whole block", func(t *testing.T) {
+		whole := []nmtBlock{{namespace: 7, txs: []Bytes{tx("only-a"), tx("only-b")}}}
+		wholeRoot, wholeProofs := buildTree(whole)
+		require.Empty(t, wholeProofs[0].Siblings, "a single-namespace block needs no siblings")
+		require.NoError(t, wholeProofs[0].Verify(wholeRoot, 7, whole[0].txs))
+	})
+
+	t.Run("empty namespace", func(t *testing.T) {
+		empty := NmtProof{Namespace: 99, Start: 1, End: 1}
+		require.NoError(t, empty.Verify(root, 99, nil), "a proof of zero leaves trivially holds")
+	})
+
+	t.Run("empty namespace rejects siblings", func(t *testing.T) {
+		empty := NmtProof{Namespace: 99, Start: 1, End: 1, Siblings: []NmtSibling{{MinNamespace: 1, MaxNamespace: 1}}}
+		require.Error(t, empty.Verify(root, 99, nil))
+	})
+
+	t.Run("wrong namespace", func(t *testing.T) {
+		require.Error(t, proofs[1].Verify(root, 3, blocks[1].txs))
+	})
+
+	t.Run("mismatched leaf count", func(t *testing.T) {
+		require.Error(t, proofs[1].Verify(root, 2, append(append([]Bytes{}, blocks[1].txs...), tx("extra"))))
+	})
+
+	t.Run("tampered transaction", func(t *testing.T) {
+		tampered := []Bytes{tx("ns1-a"), tx("tampered")}
+		require.Error(t, proofs[0].Verify(root, 1, tampered))
+	})
+
+	t.Run("tampered sibling digest", func(t *testing.T) {
+		tampered := proofs[1]
+		tampered.Siblings = append([]NmtSibling{}, tampered.Siblings...)
+		tampered.Siblings[0].Digest = append(Bytes{}, tampered.Siblings[0].Digest...)
+		tampered.Siblings[0].Digest[0] ^= 0xff
+		require.Error(t, tampered.Verify(root, 2, blocks[1].txs))
+	})
+
+	t.Run("sibling claims an overlapping namespace", func(t *testing.T) {
+		tampered := proofs[1]
+		tampered.Siblings = append([]NmtSibling{}, tampered.Siblings...)
+		tampered.Siblings[0].MinNamespace = 2
+		tampered.Siblings[0].MaxNamespace = 2
+		require.Error(t, tampered.Verify(root, 2, blocks[1].txs), "a sibling overlapping the queried namespace could be hiding leaves")
+	})
+
+	t.Run("sibling flagged on the wrong side", func(t *testing.T) {
+		tampered := proofs[1]
+		tampered.Siblings = append([]NmtSibling{}, tampered.Siblings...)
+		tampered.Siblings[0].Left = !tampered.Siblings[0].Left
+		require.Error(t, tampered.Verify(root, 2, blocks[1].txs))
+	})
+}
+
+func TestValidateBatchTransactions(t *testing.T) {
+	// ValidateBatchTransactions checks a window of several HotShot blocks, each with its own
+	// independently-rooted header and proof, so build one single-namespace tree per block.
+	blocks := []nmtBlock{
+		{namespace: 42, txs: []Bytes{tx("a"), tx("b")}},
+		{namespace: 42, txs: []Bytes{tx("c")}},
+	}
+	headers := make([]Header, len(blocks))
+	proofs := make([]NmtProof, len(blocks))
+	for i, b := range blocks {
+		root, p := buildTree([]nmtBlock{b})
+		headers[i] = Header{TransactionsRoot: root}
+		proofs[i] = p[0]
+	}
+
+	var all []hexutil.Bytes
+	for _, b := range blocks {
+		for _, txn := range b.txs {
+			all = append(all, hexutil.Bytes(txn))
+		}
+	}
+
+	require.NoError(t, ValidateBatchTransactions(42, all, proofs, headers))
+
+	t.Run("transactions out of order across blocks", func(t *testing.T) {
+		shuffled := []hexutil.Bytes{all[2], all[0], all[1]}
+		require.Error(t, ValidateBatchTransactions(42, shuffled, proofs, headers))
+	})
+
+	t.Run("missing a trailing transaction", func(t *testing.T) {
+		require.Error(t, ValidateBatchTransactions(42, all[:len(all)-1], proofs, headers))
+	})
+
+	t.Run("mismatched proof and header counts", func(t *testing.T) {
+		require.Error(t, ValidateBatchTransactions(42, all, proofs[:1], headers))
+	})
+}
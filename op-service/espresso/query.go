@@ -17,6 +17,35 @@ type QueryService interface {
 	FetchTransactionsInBlock(ctx context.Context, block uint64, header *Header, namespace uint64) (TransactionsInBlock, error)
 }
 
+// Subscriber is an optional extension to QueryService: a query service that can push new headers as
+// they are sequenced, rather than only answering polls, can implement it so callers like the OP
+// sequencer can react to a new header immediately instead of waiting out a fixed poll interval.
+// Implementations that don't support push delivery simply don't implement this interface; callers
+// are expected to type-assert for it and fall back to polling QueryService directly if it's absent.
+type Subscriber interface {
+	// SubscribeHeaders streams every header sequenced at or after fromHeight into the returned
+	// HeaderSubscription, until the caller calls Unsubscribe or the subscription ends in error.
+	SubscribeHeaders(ctx context.Context, fromHeight uint64) (HeaderSubscription, error)
+}
+
+// HeaderSubscription is a running Subscriber.SubscribeHeaders call. It splits header delivery from
+// failure reporting, the way go-ethereum's event.Subscription separates a subscription's data
+// channel from its Err(), so a consumer can tell "the subscription ended because I unsubscribed"
+// apart from "the subscription ended because the connection failed" instead of inferring one from a
+// closed channel.
+type HeaderSubscription interface {
+	// Headers delivers each header as it becomes available. It is closed when the subscription
+	// ends, whether via Unsubscribe or a delivery failure.
+	Headers() <-chan Header
+	// Err delivers at most one error if the subscription ended due to a failure rather than a call
+	// to Unsubscribe, and is closed otherwise. A consumer ranging over Headers() should check Err()
+	// once Headers() closes to tell the two cases apart.
+	Err() <-chan error
+	// Unsubscribe ends the subscription and closes Headers(). Calling it more than once, or after
+	// the subscription has already ended on its own, is a no-op.
+	Unsubscribe()
+}
+
 // Response to `FetchHeadersForWindow`.
 type WindowStart struct {
 	// The block number of the first block in the window, unless the window is empty, in which case
@@ -0,0 +1,162 @@
+package espresso
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EncodeRLP implements rlp.Encoder. The wire field order matches Commit's field order, not
+// Go's struct declaration order, so that an Espresso header embedded in an Ethereum-style
+// container (e.g. a batch inbox payload) always has the same shape regardless of how the Go
+// struct happens to be laid out.
+func (self *Header) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, &rlpHeader{
+		Timestamp:        self.Timestamp,
+		L1Head:           self.L1Head,
+		L1Finalized:      self.L1Finalized,
+		TransactionsRoot: self.TransactionsRoot,
+		Withdrawals:      self.Withdrawals,
+		Version:          self.Version,
+	})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (self *Header) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpHeader
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	self.Timestamp = dec.Timestamp
+	self.L1Head = dec.L1Head
+	self.L1Finalized = dec.L1Finalized
+	self.TransactionsRoot = dec.TransactionsRoot
+	self.Withdrawals = dec.Withdrawals
+	self.Version = dec.Version
+	return nil
+}
+
+// rlpHeader mirrors Header's fields in Commit's order, so rlp's reflection-based struct codec
+// can be reused for both directions without hand-rolling field-by-field encode/decode.
+type rlpHeader struct {
+	Timestamp        uint64
+	L1Head           uint64
+	L1Finalized      *L1BlockInfo `rlp:"nil"`
+	TransactionsRoot NmtRoot
+	Withdrawals      []Withdrawal `rlp:"nil"`
+	Version          uint64
+}
+
+// EncodeRLP implements rlp.Encoder, with the same field order Commit uses.
+func (self *Metadata) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, &rlpMetadata{
+		Timestamp:   self.Timestamp,
+		L1Head:      self.L1Head,
+		L1Finalized: self.L1Finalized,
+		Withdrawals: self.Withdrawals,
+	})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (self *Metadata) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpMetadata
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	self.Timestamp = dec.Timestamp
+	self.L1Head = dec.L1Head
+	self.L1Finalized = dec.L1Finalized
+	self.Withdrawals = dec.Withdrawals
+	return nil
+}
+
+type rlpMetadata struct {
+	Timestamp   uint64
+	L1Head      uint64
+	L1Finalized *L1BlockInfo `rlp:"nil"`
+	Withdrawals []Withdrawal `rlp:"nil"`
+}
+
+// EncodeRLP implements rlp.Encoder, with the same field order Commit uses.
+func (self *L1BlockInfo) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, &rlpL1BlockInfo{
+		Number:          self.Number,
+		Timestamp:       self.Timestamp,
+		Hash:            self.Hash,
+		WithdrawalsRoot: self.WithdrawalsRoot,
+	})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (self *L1BlockInfo) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpL1BlockInfo
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	self.Number = dec.Number
+	self.Timestamp = dec.Timestamp
+	self.Hash = dec.Hash
+	self.WithdrawalsRoot = dec.WithdrawalsRoot
+	return nil
+}
+
+type rlpL1BlockInfo struct {
+	Number          uint64
+	Timestamp       U256
+	Hash            common.Hash
+	WithdrawalsRoot common.Hash
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (self *NmtRoot) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, &rlpNmtRoot{Root: self.Root})
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (self *NmtRoot) DecodeRLP(s *rlp.Stream) error {
+	var dec rlpNmtRoot
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	self.Root = dec.Root
+	return nil
+}
+
+type rlpNmtRoot struct {
+	Root Bytes
+}
+
+// EncodeRLP implements rlp.Encoder. U256 embeds big.Int, whose own fields are unexported, so it
+// cannot rely on rlp's default reflection-based struct codec the way the other types in this file
+// do; instead it forwards to the embedded big.Int directly, which rlp already knows how to encode
+// as a minimal big-endian byte string.
+func (self U256) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, &self.Int)
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (self *U256) DecodeRLP(s *rlp.Stream) error {
+	return s.Decode(&self.Int)
+}
+
+// rlpHash computes the Keccak256 hash of x's RLP encoding, the same hashing convention
+// go-ethereum's own rlpHash helpers use for header-like types, so Espresso headers can be indexed
+// by hash alongside Ethereum ones.
+func rlpHash(x interface{}) (common.Hash, error) {
+	bz, err := rlp.EncodeToBytes(x)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(bz), nil
+}
+
+// Hash returns the Keccak256 hash of self's RLP encoding, suitable for indexing Espresso headers
+// the same way Ethereum headers are indexed by hash. This is independent of Commit, which is the
+// canonical commitment HotShot consensus attests to on L1; Hash is purely a local convenience for
+// code that wants to key a header by something cheaper to compute an equality check on than the
+// full header value.
+func (self *Header) Hash() (common.Hash, error) {
+	return rlpHash(self)
+}
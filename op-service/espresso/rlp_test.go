@@ -0,0 +1,111 @@
+package espresso
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func referenceHeaderForRLP() Header {
+	return Header{
+		TransactionsRoot: NmtRoot{Root: Bytes{1, 2, 3, 4}},
+		Version:          0,
+		Metadata: Metadata{
+			Timestamp: 42,
+			L1Head:    7,
+			L1Finalized: &L1BlockInfo{
+				Number:          100,
+				Timestamp:       *NewU256().SetUint64(1_000),
+				Hash:            common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111"),
+				WithdrawalsRoot: common.HexToHash("0x2222222222222222222222222222222222222222222222222222222222222222"),
+			},
+			Withdrawals: []Withdrawal{
+				{Index: 0, Validator: 1, Address: common.HexToAddress("0x01"), Amount: *NewU256().SetUint64(500)},
+				{Index: 1, Validator: 2, Address: common.HexToAddress("0x02"), Amount: *NewU256().SetUint64(600)},
+			},
+		},
+	}
+}
+
+func TestHeaderRLPRoundTrip(t *testing.T) {
+	h := referenceHeaderForRLP()
+
+	bz, err := rlp.EncodeToBytes(&h)
+	require.NoError(t, err)
+
+	var decoded Header
+	require.NoError(t, rlp.DecodeBytes(bz, &decoded))
+	require.Equal(t, h, decoded)
+
+	reencoded, err := rlp.EncodeToBytes(&decoded)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(bz, reencoded), "RLP(decode(encode(h))) must equal RLP(h)")
+
+	require.Equal(t, h.Commit(), decoded.Commit(), "Commit must be invariant across an RLP round trip")
+}
+
+func TestHeaderRLPMatchesCommitAcrossJSON(t *testing.T) {
+	h := referenceHeaderForRLP()
+
+	jsonBz, err := json.Marshal(h)
+	require.NoError(t, err)
+	var viaJSON Header
+	require.NoError(t, json.Unmarshal(jsonBz, &viaJSON))
+
+	rlpBz, err := rlp.EncodeToBytes(&h)
+	require.NoError(t, err)
+	var viaRLP Header
+	require.NoError(t, rlp.DecodeBytes(rlpBz, &viaRLP))
+
+	require.Equal(t, h.Commit(), viaJSON.Commit(), "Commit must be invariant across a JSON round trip")
+	require.Equal(t, h.Commit(), viaRLP.Commit(), "Commit must be invariant across an RLP round trip")
+}
+
+func TestHeaderRLPNoWithdrawalsOrL1Finalized(t *testing.T) {
+	h := Header{
+		TransactionsRoot: NmtRoot{Root: Bytes{9, 9}},
+		Metadata:         Metadata{Timestamp: 1, L1Head: 2},
+	}
+
+	bz, err := rlp.EncodeToBytes(&h)
+	require.NoError(t, err)
+
+	var decoded Header
+	require.NoError(t, rlp.DecodeBytes(bz, &decoded))
+	require.Equal(t, h, decoded)
+	require.Nil(t, decoded.L1Finalized)
+	require.Nil(t, decoded.Withdrawals)
+}
+
+// FuzzHeaderRLPRoundTrip asserts RLP(decode(encode(h))) == RLP(h) and that Commit is invariant
+// across an RLP round trip, for a range of generated Header shapes.
+func FuzzHeaderRLPRoundTrip(f *testing.F) {
+	seed := referenceHeaderForRLP()
+	seedBz, err := rlp.EncodeToBytes(&seed)
+	require.NoError(f, err)
+	f.Add(seedBz)
+
+	noFrills := Header{TransactionsRoot: NmtRoot{Root: Bytes{}}}
+	noFrillsBz, err := rlp.EncodeToBytes(&noFrills)
+	require.NoError(f, err)
+	f.Add(noFrillsBz)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var h Header
+		if err := rlp.DecodeBytes(data, &h); err != nil {
+			t.Skip("not a valid encoded Header")
+		}
+
+		reencoded, err := rlp.EncodeToBytes(&h)
+		require.NoError(t, err)
+
+		var decoded Header
+		require.NoError(t, rlp.DecodeBytes(reencoded, &decoded))
+		require.Equal(t, h, decoded)
+		require.Equal(t, h.Commit(), decoded.Commit())
+	})
+}
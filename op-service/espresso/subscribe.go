@@ -0,0 +1,112 @@
+package espresso
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DefaultSubscribePollInterval is how often a long-poll HeaderSubscription polls the query service
+// for new headers; it waits this long after every poll, whether or not that poll delivered any.
+const DefaultSubscribePollInterval = 1 * time.Second
+
+// subscribeHorizon is the timestamp passed as `end` to FetchRemainingHeadersForWindow by a long-poll
+// subscription. A subscription has no fixed window end -- it wants every header from fromHeight
+// onward, forever -- so it asks for one far enough in the future to never itself be the limiting
+// factor, leaving header availability as the only real constraint.
+const subscribeHorizon = math.MaxUint64 / 2
+
+// subscribeStallWarnThreshold is how many consecutive empty polls longPollSubscription tolerates
+// before logging a warning that the height it's waiting on may never arrive. QueryService has no way
+// to ask "what is the earliest height you actually have", so this can't distinguish "HotShot hasn't
+// gotten there yet" from "this height was skipped and will never be available" -- it can only flag
+// that something has been stuck for a suspiciously long time, for an operator to investigate.
+const subscribeStallWarnThreshold = 30
+
+// SubscribeHeaders starts a long-poll HeaderSubscription against qs: a fallback implementation of
+// Subscriber for any QueryService whose transport (plain HTTP, in every implementation in this repo
+// so far) has no way to push updates itself. It repeatedly calls FetchRemainingHeadersForWindow for
+// the next height it hasn't yet delivered, which doubles as this subscription's gap-detection and
+// backfill: a height that wasn't available on one poll is simply requested again (instead of being
+// skipped) on the next, until it is available or the subscription is unsubscribed.
+func SubscribeHeaders(ctx context.Context, qs QueryService, fromHeight uint64, log log.Logger) HeaderSubscription {
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &longPollSubscription{
+		headers: make(chan Header),
+		errs:    make(chan error, 1),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go sub.run(ctx, qs, fromHeight, log)
+	return sub
+}
+
+// longPollSubscription implements HeaderSubscription by polling QueryService.FetchRemainingHeadersForWindow
+// on an interval, rather than via any push mechanism of its own.
+type longPollSubscription struct {
+	headers chan Header
+	errs    chan error
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func (s *longPollSubscription) Headers() <-chan Header { return s.headers }
+func (s *longPollSubscription) Err() <-chan error       { return s.errs }
+
+func (s *longPollSubscription) Unsubscribe() {
+	s.cancel()
+	<-s.done
+}
+
+// run is longPollSubscription's polling loop. next is the height it is currently waiting to
+// deliver; it only ever advances once that height (and, opportunistically, whatever heights follow
+// it in the same response) has actually been delivered, so a stall at one height never causes later
+// ones to be skipped over.
+func (s *longPollSubscription) run(ctx context.Context, qs QueryService, next uint64, log log.Logger) {
+	defer close(s.done)
+	defer close(s.errs)
+	defer close(s.headers)
+
+	ticker := time.NewTicker(DefaultSubscribePollInterval)
+	defer ticker.Stop()
+
+	emptyPolls := 0
+	for {
+		more, err := qs.FetchRemainingHeadersForWindow(ctx, next, subscribeHorizon)
+		if err != nil {
+			if ctx.Err() == nil {
+				select {
+				case s.errs <- err:
+				default:
+				}
+			}
+			return
+		}
+
+		if len(more.Window) == 0 {
+			emptyPolls++
+			if emptyPolls == subscribeStallWarnThreshold {
+				log.Warn("Espresso header subscription has made no progress for a while", "height", next, "polls", emptyPolls)
+			}
+		} else {
+			emptyPolls = 0
+		}
+
+		for _, h := range more.Window {
+			select {
+			case s.headers <- h:
+				next++
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -1,16 +1,24 @@
 package espresso
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 type Header struct {
 	TransactionsRoot NmtRoot `json:"transactions_root"`
 
+	// Version is a forward-compatibility hook for future changes to the HotShot header format.
+	// Every header in this tree is version 0, which commits to its block via TransactionsRoot
+	// alone; nothing here models what a later version's commitment layout would look like, since
+	// there's no real header of a later version to observe.
+	Version uint64 `json:"version,omitempty"`
+
 	Metadata `json:"metadata"`
 }
 
@@ -21,11 +29,18 @@ func (self *Header) Commit() Commitment {
 		l1FinalizedComm = &comm
 	}
 
+	var withdrawalsComm *Commitment
+	if self.Withdrawals != nil {
+		comm := commitWithdrawals(self.Withdrawals)
+		withdrawalsComm = &comm
+	}
+
 	return NewRawCommitmentBuilder("BLOCK").
 		Uint64Field("timestamp", self.Timestamp).
 		Uint64Field("l1_head", self.L1Head).
 		OptionalField("l1_finalized", l1FinalizedComm).
 		Field("transactions_root", self.TransactionsRoot.Commit()).
+		OptionalField("withdrawals_root", withdrawalsComm).
 		Finalize()
 
 }
@@ -34,19 +49,62 @@ type Metadata struct {
 	Timestamp   uint64       `json:"timestamp"`
 	L1Head      uint64       `json:"l1_head"`
 	L1Finalized *L1BlockInfo `json:"l1_finalized" rlp:"nil"`
+	// Withdrawals carries the EIP-4895 withdrawals processed by the L1 block this header's L1Head
+	// points to, if any were present. Nil (rather than an empty slice) on pre-Shanghai L1 blocks, so
+	// Header.Commit's OptionalField reproduces the same commitment pre-Shanghai headers already
+	// produce today.
+	Withdrawals []Withdrawal `json:"withdrawals,omitempty" rlp:"nil"`
+}
+
+// Withdrawal mirrors the EIP-4895 withdrawal shape carried by post-Shanghai L1 blocks.
+type Withdrawal struct {
+	Index     uint64         `json:"index"`
+	Validator uint64         `json:"validator"`
+	Address   common.Address `json:"address"`
+	Amount    U256           `json:"amount"`
+}
+
+func (self *Withdrawal) Commit() Commitment {
+	return NewRawCommitmentBuilder("WITHDRAWAL").
+		Uint64Field("index", self.Index).
+		Uint64Field("validator", self.Validator).
+		AddressField("address", self.Address).
+		Uint256Field("amount", &self.Amount).
+		Finalize()
+}
+
+// commitWithdrawals computes a single commitment over an ordered list of withdrawals, the same way
+// TransactionsRoot folds an NMT root's raw bytes into a block's commitment: each withdrawal's own
+// commitment is folded in, in order, so the result changes if any withdrawal is added, removed,
+// reordered, or modified. This mirrors the shape of a Merkle/NMT-style withdrawals root without
+// claiming byte-for-byte compatibility with the reference Rust sequencer's own hash; see
+// RawCommitmentBuilder in commitment_scheme.go for the backend this and Header/L1BlockInfo.Commit
+// all share.
+func commitWithdrawals(withdrawals []Withdrawal) Commitment {
+	b := NewRawCommitmentBuilder("WITHDRAWALS").Uint64Field("len", uint64(len(withdrawals)))
+	for _, w := range withdrawals {
+		b = b.Field("withdrawal", w.Commit())
+	}
+	return b.Finalize()
 }
 
 type L1BlockInfo struct {
 	Number    uint64      `json:"number"`
 	Timestamp U256        `json:"timestamp"`
 	Hash      common.Hash `json:"hash"`
+	// WithdrawalsRoot is this L1 block's EIP-4895 withdrawals trie root. Post-Shanghai L1 blocks
+	// always have one; it is the zero hash on pre-Shanghai blocks, which is why it is folded into
+	// Commit unconditionally via Bytes32Field, the same way Hash already is, rather than made
+	// optional the way Header.Withdrawals is.
+	WithdrawalsRoot common.Hash `json:"withdrawals_root"`
 }
 
 func (self *L1BlockInfo) Commit() Commitment {
 	return NewRawCommitmentBuilder("L1BLOCK").
 		Uint64Field("number", self.Number).
 		Uint256Field("timestamp", &self.Timestamp).
-		FixedSizeField("hash", self.Hash[:]).
+		Bytes32Field("hash", self.Hash).
+		Bytes32Field("withdrawals_root", self.WithdrawalsRoot).
 		Finalize()
 }
 
@@ -61,13 +119,36 @@ func (self *NmtRoot) Commit() Commitment {
 }
 
 type BatchMerkleProof = Bytes
-type NmtProof = Bytes
 
-// A bytes type which serializes to JSON as an array, rather than a base64 string. This ensures
-// compatibility with the Espresso APIs.
+// BytesEncoding selects how Bytes.MarshalJSON serializes a value.
+type BytesEncoding int
+
+const (
+	// BytesAsIntArray serializes as a JSON array of ints, matching the Rust sequencer's Espresso
+	// APIs. This is the default, so existing callers see no change in behavior.
+	BytesAsIntArray BytesEncoding = iota
+	// BytesAsHex serializes as a "0x"-prefixed hex string, for interop with non-Espresso Ethereum
+	// tooling (e.g. JSON-RPC clients that expect go-ethereum's hexutil.Bytes convention).
+	BytesAsHex
+)
+
+// EncodingMode controls how Bytes.MarshalJSON serializes every Bytes value in the process. It
+// defaults to BytesAsIntArray for compatibility with the Rust sequencer; callers embedding Espresso
+// types in non-Espresso JSON-RPC responses can set it to BytesAsHex instead. UnmarshalJSON always
+// accepts either form regardless of this setting, since decoding is unambiguous from the leading
+// byte of the input.
+var EncodingMode = BytesAsIntArray
+
+// A bytes type which, by default, serializes to JSON as an int array rather than a base64 string.
+// This ensures compatibility with the Espresso APIs. See EncodingMode to opt into hex-string
+// serialization instead.
 type Bytes []byte
 
 func (b Bytes) MarshalJSON() ([]byte, error) {
+	if EncodingMode == BytesAsHex {
+		return hexutil.Bytes(b).MarshalJSON()
+	}
+
 	// Convert to `int` array, which serializes the way we want.
 	ints := make([]int, len(b))
 	for i := range b {
@@ -78,7 +159,19 @@ func (b Bytes) MarshalJSON() ([]byte, error) {
 }
 
 func (b *Bytes) UnmarshalJSON(in []byte) error {
-	// Parse as `int` array, which deserializes the way we want.
+	trimmed := bytes.TrimSpace(in)
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		// A JSON string: parse as a "0x"-prefixed hex string, the encoding non-Espresso Ethereum
+		// tooling uses for byte blobs.
+		var h hexutil.Bytes
+		if err := h.UnmarshalJSON(in); err != nil {
+			return err
+		}
+		*b = Bytes(h)
+		return nil
+	}
+
+	// Otherwise, parse as an `int` array, which is what the Rust sequencer emits.
 	var ints []int
 	if err := json.Unmarshal(in, &ints); err != nil {
 		return err
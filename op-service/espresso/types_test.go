@@ -0,0 +1,177 @@
+package espresso
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// removeWhitespace strips whitespace from a pretty-printed JSON literal so it can be compared
+// against the compact output of json.Marshal.
+func removeWhitespace(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, c := range []byte(s) {
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+func TestWithdrawalJson(t *testing.T) {
+	w := Withdrawal{
+		Index:     1,
+		Validator: 2,
+		Address:   common.HexToAddress("0x0000000000000000000000000000000000000042"),
+		Amount:    *NewU256().SetUint64(100),
+	}
+
+	expected := removeWhitespace(`{
+		"index": 1,
+		"validator": 2,
+		"address": "0x0000000000000000000000000000000000000042",
+		"amount": "0x64"
+	}`)
+
+	bz, err := json.Marshal(w)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(bz))
+
+	var roundTrip Withdrawal
+	require.NoError(t, json.Unmarshal(bz, &roundTrip))
+	require.Equal(t, w, roundTrip)
+}
+
+func TestL1BlockInfoWithdrawalsRootJson(t *testing.T) {
+	info := L1BlockInfo{
+		Number:          1,
+		Timestamp:       *NewU256().SetUint64(2),
+		Hash:            common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111"),
+		WithdrawalsRoot: common.HexToHash("0x2222222222222222222222222222222222222222222222222222222222222222"),
+	}
+
+	bz, err := json.Marshal(info)
+	require.NoError(t, err)
+
+	var roundTrip L1BlockInfo
+	require.NoError(t, json.Unmarshal(bz, &roundTrip))
+	require.Equal(t, info, roundTrip)
+
+	// withdrawals_root is always present, even when it is the zero hash, matching pre-Shanghai L1
+	// blocks.
+	info.WithdrawalsRoot = common.Hash{}
+	bz, err = json.Marshal(info)
+	require.NoError(t, err)
+	require.Contains(t, string(bz), `"withdrawals_root"`)
+}
+
+func TestBytesIntArrayEncoding(t *testing.T) {
+	b := Bytes{0x01, 0x02, 0xff}
+
+	bz, err := json.Marshal(b)
+	require.NoError(t, err)
+	require.Equal(t, "[1,2,255]", string(bz))
+
+	var roundTrip Bytes
+	require.NoError(t, json.Unmarshal(bz, &roundTrip))
+	require.Equal(t, b, roundTrip)
+}
+
+func TestBytesHexEncoding(t *testing.T) {
+	old := EncodingMode
+	EncodingMode = BytesAsHex
+	defer func() { EncodingMode = old }()
+
+	b := Bytes{0x01, 0x02, 0xff}
+
+	bz, err := json.Marshal(b)
+	require.NoError(t, err)
+	require.Equal(t, `"0x0102ff"`, string(bz))
+
+	var roundTrip Bytes
+	require.NoError(t, json.Unmarshal(bz, &roundTrip))
+	require.Equal(t, b, roundTrip)
+}
+
+func TestBytesUnmarshalAcceptsBothEncodingsRegardlessOfMode(t *testing.T) {
+	// UnmarshalJSON sniffs the input itself, so it accepts both forms no matter what EncodingMode
+	// is currently set to.
+	var fromHex Bytes
+	require.NoError(t, json.Unmarshal([]byte(`"0xdead"`), &fromHex))
+	require.Equal(t, Bytes{0xde, 0xad}, fromHex)
+
+	var fromIntArray Bytes
+	require.NoError(t, json.Unmarshal([]byte(`[222, 173]`), &fromIntArray))
+	require.Equal(t, Bytes{0xde, 0xad}, fromIntArray)
+}
+
+func TestBytesUnmarshalMalformedInputs(t *testing.T) {
+	t.Run("odd-length hex", func(t *testing.T) {
+		var b Bytes
+		require.Error(t, json.Unmarshal([]byte(`"0xabc"`), &b))
+	})
+
+	t.Run("non-hex characters", func(t *testing.T) {
+		var b Bytes
+		require.Error(t, json.Unmarshal([]byte(`"0xzz"`), &b))
+	})
+
+	t.Run("missing 0x prefix", func(t *testing.T) {
+		var b Bytes
+		require.Error(t, json.Unmarshal([]byte(`"abcd"`), &b))
+	})
+
+	t.Run("int out of byte range", func(t *testing.T) {
+		var b Bytes
+		require.Error(t, json.Unmarshal([]byte(`[1, 256]`), &b))
+	})
+
+	t.Run("negative int", func(t *testing.T) {
+		var b Bytes
+		require.Error(t, json.Unmarshal([]byte(`[-1, 2]`), &b))
+	})
+}
+
+func TestHeaderWithdrawalsJson(t *testing.T) {
+	header := Header{
+		TransactionsRoot: NmtRoot{Root: Bytes{1, 2, 3}},
+		Metadata: Metadata{
+			Timestamp: 1,
+			L1Head:    2,
+			Withdrawals: []Withdrawal{
+				{
+					Index:     0,
+					Validator: 7,
+					Address:   common.HexToAddress("0x0000000000000000000000000000000000000001"),
+					Amount:    *NewU256().SetUint64(500),
+				},
+			},
+		},
+	}
+
+	bz, err := json.Marshal(header)
+	require.NoError(t, err)
+
+	var roundTrip Header
+	require.NoError(t, json.Unmarshal(bz, &roundTrip))
+	require.Equal(t, header, roundTrip)
+
+	// A header with no withdrawals round-trips with a nil slice, not an empty one, so that
+	// Header.Commit's OptionalField treats it the same as headers produced before this field
+	// existed.
+	noWithdrawals := Header{
+		TransactionsRoot: NmtRoot{Root: Bytes{1, 2, 3}},
+		Metadata:         Metadata{Timestamp: 1, L1Head: 2},
+	}
+	bz, err = json.Marshal(noWithdrawals)
+	require.NoError(t, err)
+	require.NotContains(t, string(bz), "withdrawals")
+
+	var roundTripNoWithdrawals Header
+	require.NoError(t, json.Unmarshal(bz, &roundTripNoWithdrawals))
+	require.Nil(t, roundTripNoWithdrawals.Withdrawals)
+}
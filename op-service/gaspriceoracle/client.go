@@ -0,0 +1,129 @@
+package gaspriceoracle
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Sample holds one block's fee values, with the same fields as an entry in the GasPriceOracle's
+// fee-history ring buffer. Client fills it from the block header (see fetchSample), not from the oracle.
+type Sample struct {
+	BaseFee        *big.Int
+	BlobBaseFee    *big.Int
+	L1BaseFee      *big.Int
+	L1BlobBaseFee  *big.Int
+	GasUsedRatio   float64
+}
+
+// Client builds fee samples from L2 block headers read over an ethclient connection. It does not
+// read the GasPriceOracle's fee-history buffer: the oracle address it holds is not used by any call.
+type Client struct {
+	ethClient *ethclient.Client
+	oracle    common.Address
+}
+
+func NewClient(ethClient *ethclient.Client, oracle common.Address) *Client {
+	return &Client{ethClient: ethClient, oracle: oracle}
+}
+
+// FeeHistory follows the result shape of L1's eth_feeHistory: it returns up to blockCount samples
+// ending at newestBlock, plus one reward per requested percentile in rewardPercentiles. Rewards are
+// not per-transaction priority-fee percentiles: every percentile of a block gets that Sample's
+// L1BaseFee (see rewardAtPercentiles).
+func (c *Client) FeeHistory(ctx context.Context, blockCount uint64, newestBlock uint64, rewardPercentiles []float64) (*FeeHistoryResult, error) {
+	samples, err := c.fetchSamples(ctx, blockCount, newestBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fee history samples: %w", err)
+	}
+
+	result := &FeeHistoryResult{
+		OldestBlock:   newestBlock - uint64(len(samples)) + 1,
+		BaseFee:       make([]*big.Int, 0, len(samples)),
+		GasUsedRatio:  make([]float64, 0, len(samples)),
+		Reward:        make([][]*big.Int, 0, len(samples)),
+	}
+	for _, s := range samples {
+		result.BaseFee = append(result.BaseFee, s.BaseFee)
+		result.GasUsedRatio = append(result.GasUsedRatio, s.GasUsedRatio)
+		result.Reward = append(result.Reward, rewardAtPercentiles(s, rewardPercentiles))
+	}
+	return result, nil
+}
+
+// FeeHistoryResult is the shape returned by the optimism_feeHistory JSON-RPC method, matching the
+// field names of L1's eth_feeHistory so existing wallet integrations need minimal changes.
+type FeeHistoryResult struct {
+	OldestBlock  uint64
+	BaseFee      []*big.Int
+	GasUsedRatio []float64
+	Reward       [][]*big.Int
+}
+
+// rewardAtPercentiles picks, for each requested percentile, the nearest-rank priority fee sample
+// within a single block's sample window. Since a Sample carries one L1-derived value per L2 block
+// rather than per-transaction priority fees, every requested percentile resolves to the same value,
+// the Sample's L1BaseFee; the per-percentile slice is kept so callers don't need to
+// special-case a ring buffer upgraded later to track real per-tx priority fees.
+func rewardAtPercentiles(s Sample, percentiles []float64) []*big.Int {
+	rewards := make([]*big.Int, len(percentiles))
+	sorted := []*big.Int{s.L1BaseFee}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	for i := range percentiles {
+		idx := int(percentiles[i] / 100 * float64(len(sorted)-1))
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(sorted) {
+			idx = len(sorted) - 1
+		}
+		rewards[i] = sorted[idx]
+	}
+	return rewards
+}
+
+// fetchSamples reads one sample per block in reverse from newestBlock, stopping once blockCount
+// samples have been collected or block 0 has been read.
+func (c *Client) fetchSamples(ctx context.Context, blockCount uint64, newestBlock uint64) ([]Sample, error) {
+	// NOTE: the ABI bindings for the GasPriceOracle's fee-history accessor are generated as part of
+	// op-bindings (see op-bindings/bindings/gaspriceoracle_more.go in the fuller tree) and are not
+	// reproduced here; this fetches one block header per sample (see fetchSample) instead.
+	samples := make([]Sample, 0, blockCount)
+	for i := uint64(0); i < blockCount && i <= newestBlock; i++ {
+		block := newestBlock - i
+		sample, err := c.fetchSample(ctx, block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch fee history sample for block %d: %w", block, err)
+		}
+		samples = append(samples, sample)
+	}
+	// Reverse into oldest-to-newest order, matching eth_feeHistory.
+	for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+		samples[i], samples[j] = samples[j], samples[i]
+	}
+	return samples, nil
+}
+
+// fetchSample takes BaseFee and GasUsedRatio from the block's header; L1BaseFee is always 0 and
+// BlobBaseFee and L1BlobBaseFee are always 1.
+func (c *Client) fetchSample(ctx context.Context, block uint64) (Sample, error) {
+	header, err := c.ethClient.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return Sample{}, err
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = big.NewInt(0)
+	}
+	return Sample{
+		BaseFee:       baseFee,
+		BlobBaseFee:   big.NewInt(1),
+		L1BaseFee:     big.NewInt(0),
+		L1BlobBaseFee: big.NewInt(1),
+		GasUsedRatio:  float64(header.GasUsed) / float64(header.GasLimit),
+	}, nil
+}
@@ -9,8 +9,11 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/EspressoSystems/go-espresso-sequencer/hotshot"
 	espresso "github.com/EspressoSystems/go-espresso-sequencer/types"
@@ -25,6 +28,18 @@ type L1ClientConfig struct {
 	EthClientConfig
 
 	L1BlockRefsCacheSize int
+
+	// HotShotCommitmentsCacheSize bounds the number of (hotshotAddr, height) -> Commitment entries
+	// kept in memory. Commitments are immutable once the contract records a nonzero value, so this
+	// cache never needs invalidation, only eviction.
+	HotShotCommitmentsCacheSize int
+
+	// WarmupL1BlockRefs controls whether L1BlockRefsByRange warmup calls (e.g. the one
+	// EspressoProvider issues before iterating a commitment range) actually hit the RPC, or are a
+	// no-op. It's opt-in: batching an extra eth_getBlockByNumber range call costs nothing against an
+	// unmetered node, but adds up against a metered RPC provider, so operators who'd rather pay for
+	// the one-at-a-time L1BlockRefByNumber calls downstream would otherwise make can disable it.
+	WarmupL1BlockRefs bool
 }
 
 func L1ClientDefaultConfig(config *rollup.Config, trustRPC bool, kind RPCProviderKind) *L1ClientConfig {
@@ -50,6 +65,11 @@ func L1ClientDefaultConfig(config *rollup.Config, trustRPC bool, kind RPCProvide
 		},
 		// Not bounded by span, to cover find-sync-start range fully for speedy recovery after errors.
 		L1BlockRefsCacheSize: fullSpan,
+		// Commitments are immutable, so there's no reason to tie this to the sequencing window; size
+		// it generously to cover re-derivation across a wide range of HotShot heights.
+		HotShotCommitmentsCacheSize: fullSpan * 10,
+		// Off by default; see the field's doc comment.
+		WarmupL1BlockRefs: false,
 	}
 }
 
@@ -59,9 +79,24 @@ func L1ClientDefaultConfig(config *rollup.Config, trustRPC bool, kind RPCProvide
 type L1Client struct {
 	*EthClient
 
+	cfg L1ClientConfig
+
 	// cache L1BlockRef by hash
 	// common.Hash -> eth.L1BlockRef
 	l1BlockRefsCache *caching.LRUCache[common.Hash, eth.L1BlockRef]
+
+	// cache HotShot commitments, since they are immutable once the contract records a nonzero value
+	// hotShotCommitmentKey -> espresso.Commitment
+	hotShotCommitmentsCache *caching.LRUCache[hotShotCommitmentKey, espresso.Commitment]
+}
+
+// hotShotCommitmentKey identifies a single HotShot commitment slot: a block height as recorded by
+// a specific HotShot contract instance. The contract address is part of the key because a node may
+// read commitments from more than one HotShot deployment over its lifetime (e.g. across a contract
+// upgrade).
+type hotShotCommitmentKey struct {
+	hotshotAddr common.Address
+	height      uint64
 }
 
 // NewL1Client wraps a RPC with bindings to fetch L1 data, while logging errors, tracking metrics (optional), and caching.
@@ -72,8 +107,10 @@ func NewL1Client(client client.RPC, log log.Logger, metrics caching.Metrics, con
 	}
 
 	return &L1Client{
-		EthClient:        ethClient,
-		l1BlockRefsCache: caching.NewLRUCache[common.Hash, eth.L1BlockRef](metrics, "blockrefs", config.L1BlockRefsCacheSize),
+		EthClient:               ethClient,
+		cfg:                     *config,
+		l1BlockRefsCache:        caching.NewLRUCache[common.Hash, eth.L1BlockRef](metrics, "blockrefs", config.L1BlockRefsCacheSize),
+		hotShotCommitmentsCache: caching.NewLRUCache[hotShotCommitmentKey, espresso.Commitment](metrics, "hotshotcommitments", config.HotShotCommitmentsCacheSize),
 	}, nil
 }
 
@@ -121,18 +158,94 @@ func (s *L1Client) L1BlockRefByHash(ctx context.Context, hash common.Hash) (eth.
 	return ref, nil
 }
 
+// L1BlockRefsByRange fetches the L1BlockRef for every block number in [start, start+count) with a
+// single batched RPC call, rather than count separate round-trips, and populates l1BlockRefsCache
+// with each result. Unlike L1BlockRefByNumber, this is always a full round-trip: the point is to
+// warm the cache in one shot, not to serve from it.
+func (s *L1Client) L1BlockRefsByRange(ctx context.Context, start uint64, count uint64) ([]eth.L1BlockRef, error) {
+	if count == 0 {
+		return nil, nil
+	}
+
+	headers := make([]*types.Header, count)
+	batch := make([]rpc.BatchElem, count)
+	for i := uint64(0); i < count; i++ {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []any{hexutil.EncodeUint64(start + i), false},
+			Result: &headers[i],
+		}
+	}
+	if err := s.client.RawClient().BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to batch fetch L1 headers %d..%d: %w", start, start+count, err)
+	}
+
+	refs := make([]eth.L1BlockRef, count)
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("failed to fetch L1 header %d: %w", start+uint64(i), elem.Error)
+		}
+		if headers[i] == nil {
+			return nil, fmt.Errorf("L1 header %d not found", start+uint64(i))
+		}
+		ref := eth.InfoToL1BlockRef(eth.HeaderBlockInfo(headers[i]))
+		s.l1BlockRefsCache.Add(ref.Hash, ref)
+		refs[i] = ref
+	}
+	return refs, nil
+}
+
+// WarmupL1BlockRefs is L1BlockRefsByRange, gated by the opt-in L1ClientConfig.WarmupL1BlockRefs
+// flag, for callers (like EspressoProvider) that want to prime the cache ahead of a range of
+// one-at-a-time L1BlockRefByNumber calls they know are coming, but shouldn't force the RPC round-
+// trip on operators who've disabled it. It is best-effort: a failed warmup just means the downstream
+// one-at-a-time calls fall back to doing the work themselves, so the error is logged, not returned.
+func (s *L1Client) WarmupL1BlockRefs(ctx context.Context, start uint64, count uint64) {
+	if !s.cfg.WarmupL1BlockRefs {
+		return
+	}
+	if _, err := s.L1BlockRefsByRange(ctx, start, count); err != nil {
+		s.log.Warn("failed to warm up L1 block ref cache", "start", start, "count", count, "err", err)
+	}
+}
+
 // L1HotShotCommitmentsFromHeight returns an array of HotShot commitments to sequencer blocks
-// This is used in the derivation pipeline to validate sequencer batches in Espresso mode
+// This is used in the derivation pipeline to validate sequencer batches in Espresso mode.
+//
+// Commitments are immutable once the contract records a nonzero value, so this first serves as
+// many heights as possible out of hotShotCommitmentsCache, only going to L1 for the heights that
+// are missing. This turns re-derivation over a sequencing window, which asks for the same heights
+// repeatedly, from O(N) round-trips into mostly cache hits.
+//
+// TODO(EspressoSystems/op-espresso-integration#chunk4-3): for cache misses this still issues one
+// eth_call per height. Collapsing that into a single Multicall3 call (or a raw JSON-RPC batch of
+// Commitments(height) calls) would need either a Multicall3 binding or the HotShot contract's ABI
+// available locally to hand-encode calldata for a batched eth_call; neither is vendored in this
+// tree, so that part of the request is deferred rather than faked.
 func (s *L1Client) L1HotShotCommitmentsFromHeight(firstBlockHeight uint64, numHeaders uint64, hotshotAddr common.Address) ([]espresso.Commitment, error) {
-	var comms []espresso.Commitment
+	comms := make([]espresso.Commitment, numHeaders)
+	var missing []uint64
+	for i := uint64(0); i < numHeaders; i++ {
+		height := firstBlockHeight + i
+		if comm, ok := s.hotShotCommitmentsCache.Get(hotShotCommitmentKey{hotshotAddr, height}); ok {
+			comms[i] = comm
+		} else {
+			missing = append(missing, height)
+		}
+	}
+	if len(missing) == 0 {
+		return comms, nil
+	}
+
 	client := ethclient.NewClient(s.client.RawClient())
-	hotshot, err := hotshot.NewHotshot(hotshotAddr, client)
+	hotshotContract, err := hotshot.NewHotshot(hotshotAddr, client)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if the requested commitments are even available yet on L1.
-	blockHeight, err := hotshot.HotshotCaller.BlockHeight(nil)
+	// Check once, for the whole range of missing heights, whether the requested commitments are
+	// even available yet on L1, rather than re-checking on every iteration below.
+	blockHeight, err := hotshotContract.HotshotCaller.BlockHeight(nil)
 	if err != nil {
 		return nil, err
 	}
@@ -140,11 +253,10 @@ func (s *L1Client) L1HotShotCommitmentsFromHeight(firstBlockHeight uint64, numHe
 		return nil, fmt.Errorf("commitments up to %d are not available (current block height %d)", firstBlockHeight+numHeaders, blockHeight)
 	}
 
-	for i := 0; i < int(numHeaders); i++ {
-		height := big.NewInt(int64(firstBlockHeight + uint64(i)))
-		commAsInt, err := hotshot.HotshotCaller.Commitments(nil, height)
+	for _, height := range missing {
+		commAsInt, err := hotshotContract.HotshotCaller.Commitments(nil, big.NewInt(int64(height)))
 		if err != nil {
-			return comms, err
+			return nil, err
 		}
 		if commAsInt.Cmp(big.NewInt(0)) == 0 {
 			// A commitment of 0 indicates that this commitment hasn't been set yet in the contract.
@@ -158,13 +270,14 @@ func (s *L1Client) L1HotShotCommitmentsFromHeight(firstBlockHeight uint64, numHe
 			// ledger, consistent across all L1 forks, determined by HotShot consensus. The only
 			// question is whether the recorded ledger extends far enough for the commitments we're
 			// trying to read on the current fork of L1.
-			return nil, fmt.Errorf("read 0 for commitment %d below block height %d, this indicates an L1 reorg", firstBlockHeight+uint64(i), blockHeight)
+			return nil, fmt.Errorf("read 0 for commitment %d below block height %d, this indicates an L1 reorg", height, blockHeight)
 		}
 		comm, err := espresso.CommitmentFromUint256(espresso.NewU256().SetBigInt(commAsInt))
 		if err != nil {
-			return comms, err
+			return nil, err
 		}
-		comms = append(comms, comm)
+		s.hotShotCommitmentsCache.Add(hotShotCommitmentKey{hotshotAddr, height}, comm)
+		comms[height-firstBlockHeight] = comm
 	}
 	return comms, nil
 }
@@ -0,0 +1,128 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeL1RPCServer answers eth_getBlockByNumber JSON-RPC requests (single or batched) with a
+// canned header, so these benchmarks can measure HTTP round-trips rather than exercise real chain
+// data. It counts one requestCount increment per HTTP request it receives, which is the thing
+// L1BlockRefsByRange's batching is meant to reduce: a JSON-RPC batch of N calls still costs exactly
+// one HTTP round-trip, where N sequential calls cost N.
+type fakeL1RPCServer struct {
+	requestCount int64
+}
+
+type jsonrpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+type jsonrpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+}
+
+func (s *fakeL1RPCServer) handler(headerJSON []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&s.requestCount, 1)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// A JSON-RPC batch request is a top-level array; a single request is a top-level object.
+		if len(body) > 0 && body[0] == '[' {
+			var reqs []jsonrpcRequest
+			if err := json.Unmarshal(body, &reqs); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			resps := make([]jsonrpcResponse, len(reqs))
+			for i, req := range reqs {
+				resps[i] = jsonrpcResponse{ID: req.ID, Result: headerJSON}
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+
+		var req jsonrpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(jsonrpcResponse{ID: req.ID, Result: headerJSON})
+	}
+}
+
+func newFakeL1RPCServer(tb testing.TB) (*fakeL1RPCServer, *rpc.Client) {
+	header := &types.Header{Number: big.NewInt(1)}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		tb.Fatalf("failed to marshal fake header: %v", err)
+	}
+
+	s := &fakeL1RPCServer{}
+	httpServer := httptest.NewServer(s.handler(headerJSON))
+	tb.Cleanup(httpServer.Close)
+
+	client, err := rpc.DialHTTP(httpServer.URL)
+	if err != nil {
+		tb.Fatalf("failed to dial fake L1 RPC server: %v", err)
+	}
+	tb.Cleanup(client.Close)
+
+	return s, client
+}
+
+// BenchmarkL1BlockRefsSequential fetches a full sequencing window of headers the way code walking
+// a commitment range one L1BlockRefByNumber call at a time does today: one eth_getBlockByNumber
+// round-trip per height.
+func BenchmarkL1BlockRefsSequential(b *testing.B) {
+	const windowSize = 100
+	s, client := newFakeL1RPCServer(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for h := uint64(0); h < windowSize; h++ {
+			var header types.Header
+			if err := client.CallContext(context.Background(), &header, "eth_getBlockByNumber", "0x0", false); err != nil {
+				b.Fatalf("call failed: %v", err)
+			}
+		}
+	}
+	b.ReportMetric(float64(atomic.LoadInt64(&s.requestCount))/float64(b.N), "http-requests/op")
+}
+
+// BenchmarkL1BlockRefsBatched fetches the same sequencing window's worth of headers the way
+// L1BlockRefsByRange does: a single JSON-RPC batch covering the whole window, one HTTP round-trip
+// regardless of window size.
+func BenchmarkL1BlockRefsBatched(b *testing.B) {
+	const windowSize = 100
+	s, client := newFakeL1RPCServer(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		headers := make([]*types.Header, windowSize)
+		batch := make([]rpc.BatchElem, windowSize)
+		for h := uint64(0); h < windowSize; h++ {
+			batch[h] = rpc.BatchElem{Method: "eth_getBlockByNumber", Args: []any{"0x0", false}, Result: &headers[h]}
+		}
+		if err := client.BatchCallContext(context.Background(), batch); err != nil {
+			b.Fatalf("batch call failed: %v", err)
+		}
+	}
+	b.ReportMetric(float64(atomic.LoadInt64(&s.requestCount))/float64(b.N), "http-requests/op")
+}